@@ -0,0 +1,34 @@
+package config
+
+import (
+	"log"
+	"sync"
+	"time"
+)
+
+var (
+	locationOnce sync.Once
+	location     *time.Location
+)
+
+// Timezone returns the outlet timezone name from APP_TIMEZONE, Asia/Jakarta by default
+func Timezone() string {
+	if timezone := GetEnv("APP_TIMEZONE", ""); timezone != "" {
+		return timezone
+	}
+	return "Asia/Jakarta"
+}
+
+// Location returns the outlet timezone, falling back to UTC when APP_TIMEZONE is not a known zone
+func Location() *time.Location {
+	locationOnce.Do(func() {
+		loc, err := time.LoadLocation(Timezone())
+		if err != nil {
+			log.Printf("Warning: invalid APP_TIMEZONE %q, using UTC: %v", Timezone(), err)
+			loc = time.UTC
+		}
+		location = loc
+	})
+
+	return location
+}
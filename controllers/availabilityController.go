@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type AvailabilityController struct {
+	availabilityService *services.AvailabilityService
+}
+
+func NewAvailabilityController(availabilityService *services.AvailabilityService) *AvailabilityController {
+	return &AvailabilityController{
+		availabilityService: availabilityService,
+	}
+}
+
+func (ac *AvailabilityController) GetSchedules(c *gin.Context) {
+	productId, _ := strconv.ParseUint(c.Query("product_id"), 10, 32)
+	categoryId, _ := strconv.ParseUint(c.Query("category_id"), 10, 32)
+
+	schedules, err := ac.availabilityService.GetSchedules(uint(productId), uint(categoryId))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch schedules",
+		})
+		return
+	}
+
+	responses := make([]structs.AvailabilityScheduleResponse, 0, len(schedules))
+	for _, schedule := range schedules {
+		responses = append(responses, toScheduleResponse(schedule))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Schedules fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (ac *AvailabilityController) CreateSchedule(c *gin.Context) {
+	var req structs.AvailabilityScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	schedule, err := ac.availabilityService.CreateSchedule(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create schedule", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Schedule created successfully",
+		Data:    toScheduleResponse(*schedule),
+	})
+}
+
+func (ac *AvailabilityController) UpdateSchedule(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid schedule ID")
+	if !ok {
+		return
+	}
+
+	var req structs.AvailabilityScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	schedule, err := ac.availabilityService.UpdateSchedule(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update schedule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Schedule updated successfully",
+		Data:    toScheduleResponse(*schedule),
+	})
+}
+
+func (ac *AvailabilityController) DeleteSchedule(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid schedule ID")
+	if !ok {
+		return
+	}
+
+	if err := ac.availabilityService.DeleteSchedule(id); err != nil {
+		respondServiceError(c, "Failed to delete schedule", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Schedule deleted successfully",
+	})
+}
+
+func (ac *AvailabilityController) GetBlackouts(c *gin.Context) {
+	blackouts, err := ac.availabilityService.GetBlackouts(c.Query("include_past") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch blackouts",
+		})
+		return
+	}
+
+	responses := make([]structs.AvailabilityBlackoutResponse, 0, len(blackouts))
+	for _, blackout := range blackouts {
+		responses = append(responses, toBlackoutResponse(blackout))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Blackouts fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (ac *AvailabilityController) CreateBlackout(c *gin.Context) {
+	var req structs.AvailabilityBlackoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	blackout, err := ac.availabilityService.CreateBlackout(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create blackout", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Blackout created successfully",
+		Data:    toBlackoutResponse(*blackout),
+	})
+}
+
+func (ac *AvailabilityController) DeleteBlackout(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid blackout ID")
+	if !ok {
+		return
+	}
+
+	if err := ac.availabilityService.DeleteBlackout(id); err != nil {
+		respondServiceError(c, "Failed to delete blackout", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Blackout deleted successfully",
+	})
+}
+
+func toScheduleResponse(schedule models.AvailabilitySchedule) structs.AvailabilityScheduleResponse {
+	return structs.AvailabilityScheduleResponse{
+		Id:         schedule.Id,
+		ProductId:  schedule.ProductId,
+		CategoryId: schedule.CategoryId,
+		Label:      schedule.Label,
+		Days:       schedule.DayList(),
+		StartTime:  schedule.StartTime,
+		EndTime:    schedule.EndTime,
+	}
+}
+
+func toBlackoutResponse(blackout models.AvailabilityBlackout) structs.AvailabilityBlackoutResponse {
+	return structs.AvailabilityBlackoutResponse{
+		Id:         blackout.Id,
+		ProductId:  blackout.ProductId,
+		CategoryId: blackout.CategoryId,
+		StartDate:  blackout.StartDate.Format("2006-01-02"),
+		EndDate:    blackout.EndDate.Format("2006-01-02"),
+		Reason:     blackout.Reason,
+	}
+}
@@ -0,0 +1,114 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type BundleController struct {
+	bundleService *services.BundleService
+}
+
+func NewBundleController(bundleService *services.BundleService) *BundleController {
+	return &BundleController{
+		bundleService: bundleService,
+	}
+}
+
+func (bc *BundleController) GetBundle(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	product, err := bc.bundleService.GetBundle(productId)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch bundle", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Bundle fetched successfully",
+		Data:    toBundleComponentResponses(product.Components),
+	})
+}
+
+func (bc *BundleController) SetBundle(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	var req structs.BundleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	product, err := bc.bundleService.SetBundle(productId, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update bundle", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Bundle updated successfully",
+		Data:    toBundleComponentResponses(product.Components),
+	})
+}
+
+func (bc *BundleController) RemoveBundle(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	if err := bc.bundleService.RemoveBundle(productId); err != nil {
+		respondServiceError(c, "Failed to remove bundle", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Bundle removed successfully",
+	})
+}
+
+func toBundleComponentResponses(components []models.BundleComponent) []structs.BundleComponentResponse {
+	responses := make([]structs.BundleComponentResponse, 0, len(components))
+	for _, component := range components {
+		choices := make([]structs.BundleComponentChoiceResponse, 0, len(component.Choices))
+		for _, choice := range component.Choices {
+			choices = append(choices, structs.BundleComponentChoiceResponse{
+				Id:          choice.Id,
+				ProductId:   choice.ProductId,
+				ProductName: choice.Product.Name,
+				PriceDelta:  choice.PriceDelta,
+				IsAvailable: choice.Product.IsAvailable,
+			})
+		}
+
+		responses = append(responses, structs.BundleComponentResponse{
+			Id:          component.Id,
+			Name:        component.Name,
+			ProductId:   component.ProductId,
+			ProductName: component.Product.Name,
+			Quantity:    component.Quantity,
+			SortOrder:   component.SortOrder,
+			IsAvailable: component.Product.IsAvailable,
+			Choices:     choices,
+		})
+	}
+
+	return responses
+}
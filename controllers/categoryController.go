@@ -1,11 +1,14 @@
 package controllers
 
 import (
+	"deck/helpers"
+	"deck/middlewares"
 	"deck/services"
 	"deck/structs"
+	"errors"
 	"github.com/gin-gonic/gin"
 	"net/http"
-	"strings"
+	"strconv"
 )
 
 type CategoryController struct {
@@ -19,7 +22,21 @@ func NewCategoryController(categoryService *services.CategoryService) *CategoryC
 }
 
 func (cc *CategoryController) GetCategories(c *gin.Context) {
-	categories := cc.categoryService.GetCategories()
+	// Inactive categories are for the admin screens, the public menu only lists active ones
+	includeInactive := c.Query("include_inactive") == "true"
+	if includeInactive && !middlewares.Authenticate(c) {
+		return
+	}
+
+	categories, err := cc.categoryService.GetCategories(includeInactive)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch categories",
+		})
+
+		return
+	}
 
 	c.JSON(http.StatusOK, structs.SuccessResponse{
 		Success: true,
@@ -45,7 +62,7 @@ func (cc *CategoryController) GetCategoryByValue(c *gin.Context) {
 		var statusCode int
 		var message string
 
-		if strings.Contains(err.Error(), "invalid category value") {
+		if errors.Is(err, services.ErrNotFound) {
 			statusCode = http.StatusNotFound
 			message = "Category not found"
 		} else {
@@ -68,3 +85,125 @@ func (cc *CategoryController) GetCategoryByValue(c *gin.Context) {
 		Data:    category,
 	})
 }
+
+func (cc *CategoryController) CreateCategory(c *gin.Context) {
+	var req structs.CategoryCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+
+		return
+	}
+
+	category, err := cc.categoryService.CreateCategory(&req)
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalid) {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		c.JSON(statusCode, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to create category",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Category created successfully",
+		Data:    category,
+	})
+}
+
+func (cc *CategoryController) UpdateCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Invalid category ID",
+		})
+
+		return
+	}
+
+	var req structs.CategoryUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+
+		return
+	}
+
+	category, err := cc.categoryService.UpdateCategory(uint(id), &req)
+	if err != nil {
+		var statusCode int
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, services.ErrInvalid):
+			statusCode = http.StatusUnprocessableEntity
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
+		c.JSON(statusCode, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to update category",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Category updated successfully",
+		Data:    category,
+	})
+}
+
+func (cc *CategoryController) DeleteCategory(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Invalid category ID",
+		})
+
+		return
+	}
+
+	if err := cc.categoryService.DeleteCategory(uint(id)); err != nil {
+		var statusCode int
+		switch {
+		case errors.Is(err, services.ErrNotFound):
+			statusCode = http.StatusNotFound
+		case errors.Is(err, services.ErrInvalid):
+			statusCode = http.StatusConflict
+		default:
+			statusCode = http.StatusInternalServerError
+		}
+
+		c.JSON(statusCode, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to delete category",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Category deleted successfully",
+	})
+}
@@ -0,0 +1,157 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type CustomerController struct {
+	customerService *services.CustomerService
+}
+
+func NewCustomerController(customerService *services.CustomerService) *CustomerController {
+	return &CustomerController{
+		customerService: customerService,
+	}
+}
+
+func (cc *CustomerController) GetCustomers(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	customers, err := cc.customerService.GetCustomers(c.Query("q"), c.DefaultQuery("sort", "recent"), limit)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch customers", err)
+		return
+	}
+
+	responses := make([]structs.CustomerResponse, 0, len(customers))
+	for _, customer := range customers {
+		responses = append(responses, toCustomerResponse(customer))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Customers fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (cc *CustomerController) GetCustomerById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid customer ID")
+	if !ok {
+		return
+	}
+
+	customer, err := cc.customerService.GetCustomerById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch customer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Customer fetched successfully",
+		Data:    toCustomerResponse(*customer),
+	})
+}
+
+func (cc *CustomerController) UpdateCustomer(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid customer ID")
+	if !ok {
+		return
+	}
+
+	var req structs.CustomerUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	customer, err := cc.customerService.UpdateCustomer(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update customer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Customer updated successfully",
+		Data:    toCustomerResponse(*customer),
+	})
+}
+
+func (cc *CustomerController) GetCustomerTransactions(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid customer ID")
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 50
+	}
+
+	transactions, err := cc.customerService.GetCustomerTransactions(id, limit)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch order history", err)
+		return
+	}
+
+	responses := make([]structs.TransactionResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		responses = append(responses, *toTransactionResponse(&transaction))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Order history fetched successfully",
+		Data:    responses,
+	})
+}
+
+func toCustomerResponse(customer services.CustomerWithStats) structs.CustomerResponse {
+	response := structs.CustomerResponse{
+		Id:            customer.Id,
+		Phone:         customer.Phone,
+		Name:          customer.Name,
+		Email:         customer.Email,
+		Notes:         customer.Notes,
+		PointsBalance: customer.PointsBalance,
+		OrderCount:    customer.OrderCount,
+		LifetimeValue: customer.LifetimeValue,
+		VisitDays:     customer.VisitDays,
+		CreatedAt:     customer.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     customer.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+
+	if customer.OrderCount > 0 {
+		response.AverageOrderValue = customer.LifetimeValue / customer.OrderCount
+	}
+	if customer.FirstOrderAt != nil && customer.LastOrderAt != nil {
+		firstOrderAt := customer.FirstOrderAt.Format("2006-01-02 15:04:05")
+		lastOrderAt := customer.LastOrderAt.Format("2006-01-02 15:04:05")
+		response.FirstOrderAt = &firstOrderAt
+		response.LastOrderAt = &lastOrderAt
+
+		// Visit frequency: the average gap between visits, only meaningful from the second visit on
+		if customer.VisitDays > 1 {
+			days := customer.LastOrderAt.Sub(*customer.FirstOrderAt).Hours() / 24 / float64(customer.VisitDays-1)
+			days = math.Round(days*10) / 10
+			response.AverageDaysBetweenVisits = &days
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"deck/config"
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type DiscountController struct {
+	discountService *services.DiscountService
+}
+
+func NewDiscountController(discountService *services.DiscountService) *DiscountController {
+	return &DiscountController{
+		discountService: discountService,
+	}
+}
+
+func (dc *DiscountController) GetDiscounts(c *gin.Context) {
+	discounts, err := dc.discountService.GetDiscounts(c.Query("active") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch discounts",
+		})
+		return
+	}
+
+	now := time.Now().In(config.Location())
+	responses := make([]structs.DiscountResponse, 0, len(discounts))
+	for _, discount := range discounts {
+		responses = append(responses, toDiscountResponse(discount, now))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Discounts fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (dc *DiscountController) GetDiscountById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid discount ID")
+	if !ok {
+		return
+	}
+
+	discount, err := dc.discountService.GetDiscountById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch discount", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Discount fetched successfully",
+		Data:    toDiscountResponse(*discount, time.Now().In(config.Location())),
+	})
+}
+
+func (dc *DiscountController) CreateDiscount(c *gin.Context) {
+	var req structs.DiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	discount, err := dc.discountService.CreateDiscount(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create discount", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Discount created successfully",
+		Data:    toDiscountResponse(*discount, time.Now().In(config.Location())),
+	})
+}
+
+func (dc *DiscountController) UpdateDiscount(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid discount ID")
+	if !ok {
+		return
+	}
+
+	var req structs.DiscountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	discount, err := dc.discountService.UpdateDiscount(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update discount", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Discount updated successfully",
+		Data:    toDiscountResponse(*discount, time.Now().In(config.Location())),
+	})
+}
+
+func (dc *DiscountController) DeleteDiscount(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid discount ID")
+	if !ok {
+		return
+	}
+
+	if err := dc.discountService.DeleteDiscount(id); err != nil {
+		respondServiceError(c, "Failed to delete discount", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Discount deleted successfully",
+	})
+}
+
+func (dc *DiscountController) GetDiscountReport(c *gin.Context) {
+	report, err := dc.discountService.GetDiscountReport(c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondServiceError(c, "Failed to build discount report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Discount report fetched successfully",
+		Data:    report,
+	})
+}
+
+func toDiscountResponse(discount models.Discount, now time.Time) structs.DiscountResponse {
+	var startDate, endDate *string
+	if discount.StartDate != nil {
+		startDateStr := discount.StartDate.Format("2006-01-02")
+		startDate = &startDateStr
+	}
+	if discount.EndDate != nil {
+		endDateStr := discount.EndDate.Format("2006-01-02")
+		endDate = &endDateStr
+	}
+
+	return structs.DiscountResponse{
+		Id:         discount.Id,
+		Name:       discount.Name,
+		Type:       discount.Type,
+		Value:      discount.Value,
+		ProductId:  discount.ProductId,
+		CategoryId: discount.CategoryId,
+		Days:       discount.DayList(),
+		StartTime:  discount.StartTime,
+		EndTime:    discount.EndTime,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Stackable:  discount.Stackable,
+		Priority:   discount.Priority,
+		IsActive:   discount.IsActive,
+		ActiveNow:  discount.IsActive && discount.Covers(now),
+		CreatedAt:  discount.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  discount.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
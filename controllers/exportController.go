@@ -0,0 +1,176 @@
+package controllers
+
+import (
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ExportController struct {
+	exportService *services.ExportService
+}
+
+func NewExportController(exportService *services.ExportService) *ExportController {
+	return &ExportController{
+		exportService: exportService,
+	}
+}
+
+var exportContentTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+// Export runs before the JSON handler of a list or report. With a format query it answers with the CSV or XLSX
+// export of kind instead, in the lang query language, id by default. Exports with more rows than can be streamed,
+// or with async=true, are queued and answered with their export job.
+func (ec *ExportController) Export(kind string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		format := c.Query("format")
+		if format == "" {
+			c.Next()
+			return
+		}
+
+		contentType, ok := exportContentTypes[format]
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Invalid format, use csv or xlsx",
+			})
+			return
+		}
+		c.Abort()
+
+		language := c.DefaultQuery("lang", services.ExportLanguageIndonesian)
+		params := map[string]string{}
+		for key, values := range c.Request.URL.Query() {
+			if key != "format" && key != "lang" && key != "async" && len(values) > 0 {
+				params[key] = values[0]
+			}
+		}
+		for _, param := range c.Params {
+			params[param.Key] = param.Value
+		}
+
+		doc, err := ec.exportService.Prepare(kind, language, params)
+		if err != nil {
+			respondServiceError(c, "Failed to export", err)
+			return
+		}
+
+		if c.Query("async") == "true" || doc.Large() {
+			job, err := ec.exportService.QueueExport(kind, format, language, params, c.GetString("Username"))
+			if err != nil {
+				respondServiceError(c, "Failed to export", err)
+				return
+			}
+
+			c.JSON(http.StatusAccepted, structs.SuccessResponse{
+				Success: true,
+				Message: "Export queued, it can be downloaded once completed",
+				Data:    toExportJobResponse(*job),
+			})
+			return
+		}
+
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", doc.Name+"."+format))
+
+		// The headers are sent with the first rows, an error after that can only cut the file short
+		if _, err := doc.Write(format, c.Writer); err != nil {
+			fmt.Printf("Failed to export %s: %v\n", kind, err)
+		}
+	}
+}
+
+// GetExportJobs lists the latest background exports, filtered by the status query
+func (ec *ExportController) GetExportJobs(c *gin.Context) {
+	jobs, err := ec.exportService.GetExportJobs(c.Query("status"))
+	if err != nil {
+		respondServiceError(c, "Failed to fetch exports", err)
+		return
+	}
+
+	responses := make([]structs.ExportJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		responses = append(responses, toExportJobResponse(job))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Exports fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (ec *ExportController) GetExportJobById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid export ID")
+	if !ok {
+		return
+	}
+
+	job, err := ec.exportService.GetExportJobById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch export", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Export fetched successfully",
+		Data:    toExportJobResponse(*job),
+	})
+}
+
+// DownloadExport sends the file of a completed background export
+func (ec *ExportController) DownloadExport(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid export ID")
+	if !ok {
+		return
+	}
+
+	job, path, err := ec.exportService.GetExportFile(id)
+	if err != nil {
+		respondServiceError(c, "Failed to download export", err)
+		return
+	}
+
+	c.Header("Content-Type", exportContentTypes[job.Format])
+	c.FileAttachment(path, job.FileName)
+}
+
+func toExportJobResponse(job models.ExportJob) structs.ExportJobResponse {
+	response := structs.ExportJobResponse{
+		Id:        job.Id,
+		Kind:      job.Kind,
+		Format:    job.Format,
+		Language:  job.Language,
+		Params:    map[string]string{},
+		Status:    job.Status,
+		FileName:  job.FileName,
+		Rows:      job.Rows,
+		Error:     job.Error,
+		CreatedBy: job.CreatedBy,
+		CreatedAt: job.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	json.Unmarshal([]byte(job.Params), &response.Params)
+	if job.Status == models.ExportJobStatusCompleted {
+		downloadUrl := services.ExportDownloadUrl(job.Id)
+		response.DownloadUrl = &downloadUrl
+	}
+	if job.CompletedAt != nil {
+		completedAt := job.CompletedAt.Format("2006-01-02 15:04:05")
+		response.CompletedAt = &completedAt
+	}
+	if job.ExpiresAt != nil {
+		expiresAt := job.ExpiresAt.Format("2006-01-02 15:04:05")
+		response.ExpiresAt = &expiresAt
+	}
+
+	return response
+}
@@ -0,0 +1,355 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type IngredientController struct {
+	ingredientService *services.IngredientService
+}
+
+func NewIngredientController(ingredientService *services.IngredientService) *IngredientController {
+	return &IngredientController{
+		ingredientService: ingredientService,
+	}
+}
+
+func (ic *IngredientController) GetUnits(c *gin.Context) {
+	units, err := ic.ingredientService.GetUnits()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch units",
+		})
+		return
+	}
+
+	responses := make([]structs.UnitResponse, 0, len(units))
+	for _, unit := range units {
+		responses = append(responses, toUnitResponse(unit))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Units fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (ic *IngredientController) CreateUnit(c *gin.Context) {
+	var req structs.UnitCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	unit, err := ic.ingredientService.CreateUnit(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create unit", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Unit created successfully",
+		Data:    toUnitResponse(*unit),
+	})
+}
+
+func (ic *IngredientController) GetIngredients(c *gin.Context) {
+	ingredients, err := ic.ingredientService.GetIngredients(c.Query("low_stock") == "true")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch ingredients",
+		})
+		return
+	}
+
+	responses := make([]structs.IngredientResponse, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		responses = append(responses, toIngredientResponse(ingredient))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Ingredients fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (ic *IngredientController) GetIngredientById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	ingredient, err := ic.ingredientService.GetIngredientById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch ingredient", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Ingredient fetched successfully",
+		Data:    toIngredientResponse(*ingredient),
+	})
+}
+
+func (ic *IngredientController) CreateIngredient(c *gin.Context) {
+	var req structs.IngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	ingredient, err := ic.ingredientService.CreateIngredient(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create ingredient", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Ingredient created successfully",
+		Data:    toIngredientResponse(*ingredient),
+	})
+}
+
+func (ic *IngredientController) UpdateIngredient(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	var req structs.IngredientRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	ingredient, err := ic.ingredientService.UpdateIngredient(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update ingredient", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Ingredient updated successfully",
+		Data:    toIngredientResponse(*ingredient),
+	})
+}
+
+func (ic *IngredientController) DeleteIngredient(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	if err := ic.ingredientService.DeleteIngredient(id); err != nil {
+		respondServiceError(c, "Failed to delete ingredient", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Ingredient deleted successfully",
+	})
+}
+
+func (ic *IngredientController) GetMovements(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	movements, err := ic.ingredientService.GetMovements(id, limit)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch ingredient movements", err)
+		return
+	}
+
+	responses := make([]structs.IngredientMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		responses = append(responses, toIngredientMovementResponse(movement))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Ingredient movements fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (ic *IngredientController) CreateMovement(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid ingredient ID")
+	if !ok {
+		return
+	}
+
+	var req structs.IngredientMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	movement, err := ic.ingredientService.RecordMovement(id, &req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to record ingredient movement", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Ingredient movement recorded successfully",
+		Data:    toIngredientMovementResponse(*movement),
+	})
+}
+
+func (ic *IngredientController) GetRecipe(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	items, err := ic.ingredientService.GetRecipe(productId)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch recipe", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Recipe fetched successfully",
+		Data:    toRecipeResponse(productId, items),
+	})
+}
+
+func (ic *IngredientController) SetRecipe(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	var req structs.RecipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	items, err := ic.ingredientService.SetRecipe(productId, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update recipe", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Recipe updated successfully",
+		Data:    toRecipeResponse(productId, items),
+	})
+}
+
+func toUnitResponse(unit models.Unit) structs.UnitResponse {
+	return structs.UnitResponse{
+		Id:        unit.Id,
+		Symbol:    unit.Symbol,
+		Name:      unit.Name,
+		Dimension: unit.Dimension,
+		Factor:    unit.Factor,
+	}
+}
+
+func toIngredientResponse(ingredient models.Ingredient) structs.IngredientResponse {
+	return structs.IngredientResponse{
+		Id:            ingredient.Id,
+		Name:          ingredient.Name,
+		UnitId:        ingredient.UnitId,
+		Unit:          ingredient.Unit.Symbol,
+		StockQuantity: ingredient.StockQuantity,
+		LowStockLevel: ingredient.LowStockLevel,
+		CostPerUnit:   ingredient.CostPerUnit,
+		IsActive:      ingredient.IsActive,
+		IsLowStock:    ingredient.StockQuantity <= ingredient.LowStockLevel,
+		CreatedAt:     ingredient.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     ingredient.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toIngredientMovementResponse(movement models.IngredientMovement) structs.IngredientMovementResponse {
+	return structs.IngredientMovementResponse{
+		Id:              movement.Id,
+		IngredientId:    movement.IngredientId,
+		Type:            movement.Type,
+		Quantity:        movement.Quantity,
+		StockAfter:      movement.StockAfter,
+		TransactionId:   movement.TransactionId,
+		PurchaseOrderId: movement.PurchaseOrderId,
+		StockCountId:    movement.StockCountId,
+		Reference:       movement.Reference,
+		Notes:           movement.Notes,
+		CreatedBy:       movement.CreatedBy,
+		CreatedAt:       movement.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// toRecipeResponse also prices every recipe line at the current ingredient cost
+func toRecipeResponse(productId uint, items []models.RecipeItem) structs.RecipeResponse {
+	response := structs.RecipeResponse{
+		ProductId: productId,
+		Items:     make([]structs.RecipeItemResponse, 0, len(items)),
+	}
+
+	for _, item := range items {
+		cost := item.Unit.Convert(item.Quantity, item.Ingredient.Unit) * item.Ingredient.CostPerUnit
+		response.Cost += cost
+
+		response.Items = append(response.Items, structs.RecipeItemResponse{
+			Id:             item.Id,
+			IngredientId:   item.IngredientId,
+			IngredientName: item.Ingredient.Name,
+			Quantity:       item.Quantity,
+			UnitId:         item.UnitId,
+			Unit:           item.Unit.Symbol,
+			Cost:           cost,
+		})
+	}
+
+	return response
+}
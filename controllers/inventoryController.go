@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type InventoryController struct {
+	inventoryService *services.InventoryService
+}
+
+func NewInventoryController(inventoryService *services.InventoryService) *InventoryController {
+	return &InventoryController{
+		inventoryService: inventoryService,
+	}
+}
+
+func (ic *InventoryController) GetInventory(c *gin.Context) {
+	lowStockOnly := c.Query("low_stock") == "true"
+
+	items, err := ic.inventoryService.GetInventory(lowStockOnly)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch inventory", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Inventory fetched successfully",
+		Data:    items,
+	})
+}
+
+func (ic *InventoryController) UpdateStockSettings(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	var req structs.StockSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	if err := ic.inventoryService.UpdateStockSettings(productId, &req); err != nil {
+		respondServiceError(c, "Failed to update stock settings", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Stock settings updated successfully",
+	})
+}
+
+func (ic *InventoryController) CreateMovement(c *gin.Context) {
+	var req structs.StockMovementRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	movement, err := ic.inventoryService.RecordMovement(&req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to record stock movement", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Stock movement recorded successfully",
+		Data:    toStockMovementResponse(*movement),
+	})
+}
+
+func (ic *InventoryController) GetMovements(c *gin.Context) {
+	productId, _ := strconv.ParseUint(c.Query("product_id"), 10, 32)
+	variantId, _ := strconv.ParseUint(c.Query("variant_id"), 10, 32)
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 {
+		limit = 100
+	}
+	if limit > 500 {
+		limit = 500
+	}
+
+	movements, err := ic.inventoryService.GetMovements(uint(productId), uint(variantId), c.Query("type"), limit)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch stock movements", err)
+		return
+	}
+
+	responses := make([]structs.StockMovementResponse, 0, len(movements))
+	for _, movement := range movements {
+		responses = append(responses, toStockMovementResponse(movement))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Stock movements fetched successfully",
+		Data:    responses,
+	})
+}
+
+func toStockMovementResponse(movement models.StockMovement) structs.StockMovementResponse {
+	return structs.StockMovementResponse{
+		Id:            movement.Id,
+		ProductId:     movement.ProductId,
+		VariantId:     movement.VariantId,
+		Type:          movement.Type,
+		Quantity:      movement.Quantity,
+		StockAfter:    movement.StockAfter,
+		TransactionId: movement.TransactionId,
+		Reference:     movement.Reference,
+		Notes:         movement.Notes,
+		CreatedBy:     movement.CreatedBy,
+		CreatedAt:     movement.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
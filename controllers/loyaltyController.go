@@ -0,0 +1,151 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type LoyaltyController struct {
+	loyaltyService *services.LoyaltyService
+}
+
+func NewLoyaltyController(loyaltyService *services.LoyaltyService) *LoyaltyController {
+	return &LoyaltyController{
+		loyaltyService: loyaltyService,
+	}
+}
+
+func (lc *LoyaltyController) GetSetting(c *gin.Context) {
+	setting, err := lc.loyaltyService.GetSetting()
+	if err != nil {
+		respondServiceError(c, "Failed to fetch loyalty setting", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Loyalty setting fetched successfully",
+		Data:    toLoyaltySettingResponse(setting),
+	})
+}
+
+func (lc *LoyaltyController) UpdateSetting(c *gin.Context) {
+	var req structs.LoyaltySettingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	setting, err := lc.loyaltyService.UpdateSetting(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to update loyalty setting", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Loyalty setting updated successfully",
+		Data:    toLoyaltySettingResponse(setting),
+	})
+}
+
+func (lc *LoyaltyController) GetLedger(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid customer ID")
+	if !ok {
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit < 1 || limit > 500 {
+		limit = 100
+	}
+
+	entries, err := lc.loyaltyService.GetLedger(id, limit)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch points ledger", err)
+		return
+	}
+
+	responses := make([]structs.LoyaltyPointEntryResponse, 0, len(entries))
+	for _, entry := range entries {
+		responses = append(responses, toLoyaltyPointEntryResponse(&entry))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Points ledger fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (lc *LoyaltyController) AdjustPoints(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid customer ID")
+	if !ok {
+		return
+	}
+
+	var req structs.LoyaltyAdjustRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	entry, err := lc.loyaltyService.AdjustPoints(id, &req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to adjust points", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Points adjusted successfully",
+		Data:    toLoyaltyPointEntryResponse(entry),
+	})
+}
+
+func toLoyaltySettingResponse(setting *models.LoyaltySetting) structs.LoyaltySettingResponse {
+	return structs.LoyaltySettingResponse{
+		IsActive:         setting.IsActive,
+		EarnAmount:       setting.EarnAmount,
+		MinimumSpend:     setting.MinimumSpend,
+		PointValue:       setting.PointValue,
+		MinRedeemPoints:  setting.MinRedeemPoints,
+		MaxRedeemPercent: setting.MaxRedeemPercent,
+		ExpiryDays:       setting.ExpiryDays,
+		UpdatedAt:        setting.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toLoyaltyPointEntryResponse(entry *models.LoyaltyPointEntry) structs.LoyaltyPointEntryResponse {
+	response := structs.LoyaltyPointEntryResponse{
+		Id:            entry.Id,
+		CustomerId:    entry.CustomerId,
+		TransactionId: entry.TransactionId,
+		Type:          entry.Type,
+		Points:        entry.Points,
+		Remaining:     entry.Remaining,
+		BalanceAfter:  entry.BalanceAfter,
+		Notes:         entry.Notes,
+		CreatedBy:     entry.CreatedBy,
+		CreatedAt:     entry.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if entry.ExpiresAt != nil {
+		expiresAt := entry.ExpiresAt.Format("2006-01-02 15:04:05")
+		response.ExpiresAt = &expiresAt
+	}
+
+	return response
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ModifierController struct {
+	modifierService *services.ModifierService
+}
+
+func NewModifierController(modifierService *services.ModifierService) *ModifierController {
+	return &ModifierController{
+		modifierService: modifierService,
+	}
+}
+
+func (mc *ModifierController) GetModifierGroups(c *gin.Context) {
+	groups, err := mc.modifierService.GetModifierGroups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch modifier groups",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Modifier groups fetched successfully",
+		Data:    toModifierGroupResponses(groups),
+	})
+}
+
+func (mc *ModifierController) GetModifierGroupById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid modifier group ID")
+	if !ok {
+		return
+	}
+
+	group, err := mc.modifierService.GetModifierGroupById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch modifier group", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Modifier group fetched successfully",
+		Data:    toModifierGroupResponses([]models.ModifierGroup{*group})[0],
+	})
+}
+
+func (mc *ModifierController) CreateModifierGroup(c *gin.Context) {
+	var req structs.ModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	group, err := mc.modifierService.CreateModifierGroup(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create modifier group", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Modifier group created successfully",
+		Data:    toModifierGroupResponses([]models.ModifierGroup{*group})[0],
+	})
+}
+
+func (mc *ModifierController) UpdateModifierGroup(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid modifier group ID")
+	if !ok {
+		return
+	}
+
+	var req structs.ModifierGroupRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	group, err := mc.modifierService.UpdateModifierGroup(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update modifier group", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Modifier group updated successfully",
+		Data:    toModifierGroupResponses([]models.ModifierGroup{*group})[0],
+	})
+}
+
+func (mc *ModifierController) DeleteModifierGroup(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid modifier group ID")
+	if !ok {
+		return
+	}
+
+	if err := mc.modifierService.DeleteModifierGroup(id); err != nil {
+		respondServiceError(c, "Failed to delete modifier group", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Modifier group deleted successfully",
+	})
+}
+
+func (mc *ModifierController) SetProductModifierGroups(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	var req structs.ProductModifierGroupsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	groups, err := mc.modifierService.SetProductModifierGroups(productId, req.ModifierGroupIds)
+	if err != nil {
+		respondServiceError(c, "Failed to update product modifier groups", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Product modifier groups updated successfully",
+		Data:    toModifierGroupResponses(groups),
+	})
+}
+
+func toModifierGroupResponses(groups []models.ModifierGroup) []structs.ModifierGroupResponse {
+	responses := make([]structs.ModifierGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		modifiers := make([]structs.ModifierResponse, 0, len(group.Modifiers))
+		for _, modifier := range group.Modifiers {
+			modifiers = append(modifiers, structs.ModifierResponse{
+				Id:          modifier.Id,
+				Name:        modifier.Name,
+				PriceDelta:  modifier.PriceDelta,
+				IsAvailable: modifier.IsAvailable,
+				SortOrder:   modifier.SortOrder,
+			})
+		}
+
+		responses = append(responses, structs.ModifierGroupResponse{
+			Id:         group.Id,
+			Name:       group.Name,
+			MinSelect:  group.MinSelect,
+			MaxSelect:  group.MaxSelect,
+			IsRequired: group.IsRequired,
+			SortOrder:  group.SortOrder,
+			Modifiers:  modifiers,
+		})
+	}
+
+	return responses
+}
@@ -64,6 +64,8 @@ func (nc *NotificationController) GetNotifications(c *gin.Context) {
 										cleanProduct[key] = num
 									} else if _, ok := value.(bool); ok {
 										cleanProduct[key] = value
+									} else if _, ok := value.([]interface{}); ok && key == "modifiers" {
+										cleanProduct[key] = value
 									}
 								}
 							}
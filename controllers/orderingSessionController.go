@@ -0,0 +1,153 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type OrderingSessionController struct {
+	orderingSessionService *services.OrderingSessionService
+}
+
+func NewOrderingSessionController(orderingSessionService *services.OrderingSessionService) *OrderingSessionController {
+	return &OrderingSessionController{
+		orderingSessionService: orderingSessionService,
+	}
+}
+
+func (oc *OrderingSessionController) GetTableQr(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid table ID")
+	if !ok {
+		return
+	}
+
+	qr, err := oc.orderingSessionService.GetTableQr(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch table QR code", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Table QR code fetched successfully",
+		Data:    toTableQrResponse(qr),
+	})
+}
+
+// GetTableQrImage renders the table QR code as PNG, the size query is in pixels
+func (oc *OrderingSessionController) GetTableQrImage(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid table ID")
+	if !ok {
+		return
+	}
+
+	size, err := strconv.Atoi(c.DefaultQuery("size", "512"))
+	if err != nil || size < 128 || size > 2048 {
+		size = 512
+	}
+
+	png, err := oc.orderingSessionService.RenderTableQr(id, size)
+	if err != nil {
+		respondServiceError(c, "Failed to render table QR code", err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"table-%d.png\"", id))
+	c.Data(http.StatusOK, "image/png", png)
+}
+
+func (oc *OrderingSessionController) RotateTableQr(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid table ID")
+	if !ok {
+		return
+	}
+
+	qr, err := oc.orderingSessionService.RotateTableQr(id)
+	if err != nil {
+		respondServiceError(c, "Failed to rotate table QR code", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Table QR code rotated successfully",
+		Data:    toTableQrResponse(qr),
+	})
+}
+
+// OpenSession is called by the guest ordering page with the token of the scanned QR code
+func (oc *OrderingSessionController) OpenSession(c *gin.Context) {
+	var req structs.OrderingSessionOpenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	session, err := oc.orderingSessionService.OpenSession(req.Token, c.ClientIP())
+	if err != nil {
+		respondServiceError(c, "Failed to open ordering session", err)
+		return
+	}
+
+	response := toOrderingSessionResponse(session, nil)
+	response.Token = session.Token
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Ordering session opened successfully",
+		Data:    response,
+	})
+}
+
+func (oc *OrderingSessionController) GetSession(c *gin.Context) {
+	session, transactions, err := oc.orderingSessionService.GetSession(c.Param("token"))
+	if err != nil {
+		respondServiceError(c, "Failed to fetch ordering session", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Ordering session fetched successfully",
+		Data:    toOrderingSessionResponse(session, transactions),
+	})
+}
+
+func toTableQrResponse(qr *services.TableQr) structs.TableQrResponse {
+	return structs.TableQrResponse{
+		TableId:   qr.Table.Id,
+		TableName: qr.Table.Name,
+		Token:     qr.Token,
+		Url:       qr.Url,
+	}
+}
+
+func toOrderingSessionResponse(session *models.OrderingSession, transactions []models.Transaction) structs.OrderingSessionResponse {
+	response := structs.OrderingSessionResponse{
+		ExpiresAt:  session.ExpiresAt.Format("2006-01-02 15:04:05"),
+		OrderCount: session.OrderCount,
+		MaxOrders:  services.QrSessionMaxOrders(),
+	}
+	if session.Table != nil {
+		response.Table = toTableResponse(*session.Table)
+	}
+	if session.LastOrderAt != nil {
+		lastOrderAt := session.LastOrderAt.Format("2006-01-02 15:04:05")
+		response.LastOrderAt = &lastOrderAt
+	}
+	for _, transaction := range transactions {
+		response.Orders = append(response.Orders, *toTransactionResponse(&transaction))
+	}
+
+	return response
+}
@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type PaymentController struct {
+	paymentService *services.PaymentService
+}
+
+func NewPaymentController(paymentService *services.PaymentService) *PaymentController {
+	return &PaymentController{
+		paymentService: paymentService,
+	}
+}
+
+func (pc *PaymentController) GetPayments(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	summary, err := pc.paymentService.GetPayments(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch payments", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Payments fetched successfully",
+		Data:    toPaymentSummaryResponse(summary),
+	})
+}
+
+func (pc *PaymentController) CreatePayment(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	var req structs.PaymentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	payment, err := pc.paymentService.CreatePayment(id, &req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to create payment", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Payment created successfully",
+		Data:    toPaymentResponse(*payment),
+	})
+}
+
+func (pc *PaymentController) CancelPayment(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+	paymentId, ok := parseUintParam(c, "paymentId", "Invalid payment ID")
+	if !ok {
+		return
+	}
+
+	payment, err := pc.paymentService.CancelPayment(id, paymentId)
+	if err != nil {
+		respondServiceError(c, "Failed to cancel payment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Payment cancelled successfully",
+		Data:    toPaymentResponse(*payment),
+	})
+}
+
+// MidtransNotification receives the payment notifications Midtrans sends for each payment token
+func (pc *PaymentController) MidtransNotification(c *gin.Context) {
+	var notification services.MidtransNotification
+	if err := c.ShouldBindJSON(&notification); err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Invalid notification",
+		})
+		return
+	}
+
+	if err := pc.paymentService.HandleMidtransNotification(&notification); err != nil {
+		statusCode := http.StatusInternalServerError
+		switch {
+		case errors.Is(err, services.ErrInvalidSignature):
+			statusCode = http.StatusForbidden
+		case errors.Is(err, services.ErrInvalid):
+			statusCode = http.StatusUnprocessableEntity
+		case errors.Is(err, services.ErrNotFound):
+			statusCode = http.StatusNotFound
+		}
+
+		c.JSON(statusCode, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to process notification",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Notification processed successfully",
+	})
+}
+
+func toPaymentSummaryResponse(summary *services.PaymentSummary) structs.PaymentSummaryResponse {
+	payments := make([]structs.PaymentResponse, 0, len(summary.Payments))
+	for _, payment := range summary.Payments {
+		payments = append(payments, toPaymentResponse(payment))
+	}
+
+	return structs.PaymentSummaryResponse{
+		TransactionId: summary.Transaction.Id,
+		OrderNumber:   summary.Transaction.OrderNumber,
+		PaymentStatus: summary.Transaction.PaymentStatus,
+		TotalAmount:   summary.Transaction.TotalAmount,
+		PaidAmount:    summary.PaidAmount,
+		PendingAmount: summary.PendingAmount,
+		Remaining:     summary.Remaining,
+		Payments:      payments,
+	}
+}
+
+func toPaymentResponse(payment models.Payment) structs.PaymentResponse {
+	detailIds := make([]uint, 0, len(payment.Lines))
+	for _, line := range payment.Lines {
+		detailIds = append(detailIds, line.TransactionDetailId)
+	}
+
+	response := structs.PaymentResponse{
+		Id:              payment.Id,
+		TransactionId:   payment.TransactionId,
+		Method:          payment.Method,
+		Amount:          payment.Amount,
+		Tendered:        payment.Tendered,
+		Change:          payment.Change,
+		Status:          payment.Status,
+		SplitType:       payment.SplitType,
+		SplitLabel:      payment.SplitLabel,
+		DetailIds:       detailIds,
+		Reference:       payment.Reference,
+		MidtransOrderId: payment.MidtransOrderId,
+		MidtransToken:   payment.MidtransToken,
+		RedirectUrl:     payment.RedirectUrl,
+		CreatedBy:       payment.CreatedBy,
+		CreatedAt:       payment.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if payment.PaidAt != nil {
+		paidAt := payment.PaidAt.Format("2006-01-02 15:04:05")
+		response.PaidAt = &paidAt
+	}
+
+	return response
+}
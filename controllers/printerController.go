@@ -0,0 +1,398 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type PrinterController struct {
+	printerService  *services.PrinterService
+	printJobService *services.PrintJobService
+}
+
+func NewPrinterController(printerService *services.PrinterService, printJobService *services.PrintJobService) *PrinterController {
+	return &PrinterController{
+		printerService:  printerService,
+		printJobService: printJobService,
+	}
+}
+
+func (pc *PrinterController) GetPrinters(c *gin.Context) {
+	printers, err := pc.printerService.GetPrinters()
+	if err != nil {
+		respondServiceError(c, "Failed to fetch printers", err)
+		return
+	}
+
+	responses := make([]structs.PrinterResponse, 0, len(printers))
+	for _, printer := range printers {
+		responses = append(responses, toPrinterResponse(printer))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Printers fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (pc *PrinterController) CreatePrinter(c *gin.Context) {
+	var req structs.PrinterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	printer, err := pc.printerService.CreatePrinter(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create printer", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Printer created successfully",
+		Data:    toPrinterResponse(*printer),
+	})
+}
+
+func (pc *PrinterController) UpdatePrinter(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid printer ID")
+	if !ok {
+		return
+	}
+
+	var req structs.PrinterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	printer, err := pc.printerService.UpdatePrinter(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update printer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Printer updated successfully",
+		Data:    toPrinterResponse(*printer),
+	})
+}
+
+func (pc *PrinterController) DeletePrinter(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid printer ID")
+	if !ok {
+		return
+	}
+
+	if err := pc.printerService.DeletePrinter(id); err != nil {
+		respondServiceError(c, "Failed to delete printer", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Printer deleted successfully",
+	})
+}
+
+func (pc *PrinterController) GetStations(c *gin.Context) {
+	stations, err := pc.printerService.GetStations()
+	if err != nil {
+		respondServiceError(c, "Failed to fetch stations", err)
+		return
+	}
+
+	responses := make([]structs.StationResponse, 0, len(stations))
+	for _, station := range stations {
+		responses = append(responses, toStationResponse(station))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Stations fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (pc *PrinterController) GetStationById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid station ID")
+	if !ok {
+		return
+	}
+
+	station, err := pc.printerService.GetStationById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch station", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Station fetched successfully",
+		Data:    toStationResponse(*station),
+	})
+}
+
+func (pc *PrinterController) CreateStation(c *gin.Context) {
+	var req structs.StationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	station, err := pc.printerService.CreateStation(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create station", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Station created successfully",
+		Data:    toStationResponse(*station),
+	})
+}
+
+func (pc *PrinterController) UpdateStation(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid station ID")
+	if !ok {
+		return
+	}
+
+	var req structs.StationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	station, err := pc.printerService.UpdateStation(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update station", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Station updated successfully",
+		Data:    toStationResponse(*station),
+	})
+}
+
+func (pc *PrinterController) DeleteStation(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid station ID")
+	if !ok {
+		return
+	}
+
+	if err := pc.printerService.DeleteStation(id); err != nil {
+		respondServiceError(c, "Failed to delete station", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Station deleted successfully",
+	})
+}
+
+// GetPrintJobs lists the latest print jobs, filtered by the status and transaction_id queries
+func (pc *PrinterController) GetPrintJobs(c *gin.Context) {
+	var transactionId uint
+	if value := c.Query("transaction_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Invalid transaction ID",
+			})
+			return
+		}
+		transactionId = uint(parsed)
+	}
+
+	jobs, err := pc.printJobService.GetPrintJobs(c.Query("status"), transactionId)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch print jobs", err)
+		return
+	}
+
+	responses := make([]structs.PrintJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		responses = append(responses, toPrintJobResponse(job))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Print jobs fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (pc *PrinterController) GetPrintJobById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid print job ID")
+	if !ok {
+		return
+	}
+
+	job, err := pc.printJobService.GetPrintJobById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch print job", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Print job fetched successfully",
+		Data:    toPrintJobResponse(*job),
+	})
+}
+
+func (pc *PrinterController) RetryPrintJob(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid print job ID")
+	if !ok {
+		return
+	}
+
+	job, err := pc.printJobService.RetryPrintJob(id)
+	if err != nil {
+		respondServiceError(c, "Failed to retry print job", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Print job queued again successfully",
+		Data:    toPrintJobResponse(*job),
+	})
+}
+
+// ReprintKitchenTickets queues the kitchen tickets of a paid order again, the body may name a single station
+func (pc *PrinterController) ReprintKitchenTickets(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	var req structs.KitchenTicketReprintRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+				Success: false,
+				Message: "Validation Error",
+				Errors:  helpers.TranslateErrorMessage(err),
+			})
+			return
+		}
+	}
+
+	jobs, err := pc.printJobService.ReprintKitchenTickets(id, req.StationId, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to reprint kitchen tickets", err)
+		return
+	}
+
+	responses := make([]structs.PrintJobResponse, 0, len(jobs))
+	for _, job := range jobs {
+		responses = append(responses, toPrintJobResponse(job))
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Kitchen tickets queued successfully",
+		Data:    responses,
+	})
+}
+
+func toPrinterResponse(printer models.Printer) structs.PrinterResponse {
+	return structs.PrinterResponse{
+		Id:         printer.Id,
+		Name:       printer.Name,
+		Host:       printer.Host,
+		Port:       printer.Port,
+		PaperWidth: printer.PaperWidth,
+		IsActive:   printer.IsActive,
+		CreatedAt:  printer.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  printer.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toStationResponse(station models.Station) structs.StationResponse {
+	response := structs.StationResponse{
+		Id:         station.Id,
+		Name:       station.Name,
+		PrinterId:  station.PrinterId,
+		IsActive:   station.IsActive,
+		Categories: make([]structs.StationCategoryResponse, 0, len(station.Categories)),
+		CreatedAt:  station.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:  station.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if station.Printer != nil {
+		printer := toPrinterResponse(*station.Printer)
+		response.Printer = &printer
+	}
+	for _, category := range station.Categories {
+		response.Categories = append(response.Categories, structs.StationCategoryResponse{
+			Id:          category.Id,
+			Slug:        category.Slug,
+			DisplayName: category.DisplayName,
+		})
+	}
+
+	return response
+}
+
+func toPrintJobResponse(job models.PrintJob) structs.PrintJobResponse {
+	response := structs.PrintJobResponse{
+		Id:            job.Id,
+		TransactionId: job.TransactionId,
+		StationId:     job.StationId,
+		PrinterId:     job.PrinterId,
+		Kind:          job.Kind,
+		Status:        job.Status,
+		Attempts:      job.Attempts,
+		LastError:     job.LastError,
+		NextAttemptAt: job.NextAttemptAt.Format("2006-01-02 15:04:05"),
+		CreatedBy:     job.CreatedBy,
+		CreatedAt:     job.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:     job.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if job.Transaction != nil {
+		response.OrderNumber = job.Transaction.OrderNumber
+	}
+	if job.Station != nil {
+		response.StationName = job.Station.Name
+	}
+	if job.Printer != nil {
+		response.PrinterName = job.Printer.Name
+	}
+	if job.PrintedAt != nil {
+		printedAt := job.PrintedAt.Format("2006-01-02 15:04:05")
+		response.PrintedAt = &printedAt
+	}
+
+	return response
+}
@@ -2,12 +2,12 @@ package controllers
 
 import (
 	"deck/database"
-	"deck/enums"
 	"deck/helpers"
 	"deck/models"
 	"deck/services"
 	"deck/structs"
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 	"log"
 	"net/http"
 	"os"
@@ -17,12 +17,18 @@ import (
 )
 
 type ProductController struct {
-	productService *services.ProductService
+	productService      *services.ProductService
+	categoryService     *services.CategoryService
+	availabilityService *services.AvailabilityService
+	priceService        *services.ProductPriceService
 }
 
-func NewProductController(productService *services.ProductService) *ProductController {
+func NewProductController(productService *services.ProductService, categoryService *services.CategoryService, availabilityService *services.AvailabilityService, priceService *services.ProductPriceService) *ProductController {
 	return &ProductController{
-		productService: productService,
+		productService:      productService,
+		categoryService:     categoryService,
+		availabilityService: availabilityService,
+		priceService:        priceService,
 	}
 }
 
@@ -38,10 +44,111 @@ func (pc *ProductController) GetProducts(c *gin.Context) {
 		return
 	}
 
+	availability, err := pc.availabilityService.Evaluate(products)
+	if err != nil {
+		log.Printf("Failed to evaluate product availability: %v", err)
+	}
+
+	responses := make([]structs.ProductResponse, 0, len(products))
+	for _, product := range products {
+		response := pc.toProductResponse(&product)
+		applyAvailability(response, availability)
+		responses = append(responses, *response)
+	}
+
 	c.JSON(http.StatusOK, structs.SuccessResponse{
 		Success: true,
 		Message: "Products fetched successfully",
-		Data:    products,
+		Data:    responses,
+	})
+}
+
+func (pc *ProductController) SearchProducts(c *gin.Context) {
+	keyword := strings.TrimSpace(c.Query("q"))
+	if keyword == "" {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Search query is required",
+			Errors:  map[string]string{"q": "q is required"},
+		})
+
+		return
+	}
+
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "20"))
+	if err != nil || limit < 1 {
+		limit = 20
+	}
+	if limit > 50 {
+		limit = 50
+	}
+
+	results, err := pc.productService.SearchProducts(keyword, limit)
+	if err != nil {
+		respondServiceError(c, "Failed to search products", err)
+		return
+	}
+
+	products := make([]models.Product, 0, len(results))
+	for _, result := range results {
+		products = append(products, result.Product)
+	}
+	availability, err := pc.availabilityService.Evaluate(products)
+	if err != nil {
+		log.Printf("Failed to evaluate product availability: %v", err)
+	}
+
+	responses := make([]structs.ProductSearchResponse, 0, len(results))
+	for _, result := range results {
+		response := pc.toProductResponse(&result.Product)
+		applyAvailability(response, availability)
+
+		responses = append(responses, structs.ProductSearchResponse{
+			ProductResponse: *response,
+			Rank:            result.Rank,
+			NameHighlight:   result.NameHighlight,
+			Snippet:         result.Snippet,
+			MatchType:       result.MatchType,
+		})
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Products searched successfully",
+		Data:    responses,
+	})
+}
+
+func (pc *ProductController) LookupProduct(c *gin.Context) {
+	barcode := strings.TrimSpace(c.Query("barcode"))
+	sku := strings.TrimSpace(c.Query("sku"))
+	if barcode == "" && sku == "" {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Barcode or SKU is required",
+			Errors:  map[string]string{"barcode": "barcode or sku is required"},
+		})
+		return
+	}
+
+	product, err := pc.productService.LookupProduct(barcode, sku)
+	if err != nil {
+		respondServiceError(c, "Failed to look up product", err)
+		return
+	}
+
+	response := pc.toProductResponse(product)
+
+	availability, err := pc.availabilityService.Evaluate([]models.Product{*product})
+	if err != nil {
+		log.Printf("Failed to evaluate product availability: %v", err)
+	}
+	applyAvailability(response, availability)
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Product fetched successfully",
+		Data:    response,
 	})
 }
 
@@ -71,6 +178,12 @@ func (pc *ProductController) GetProductById(c *gin.Context) {
 
 	response := pc.toProductResponse(product)
 
+	availability, err := pc.availabilityService.Evaluate([]models.Product{*product})
+	if err != nil {
+		log.Printf("Failed to evaluate product availability: %v", err)
+	}
+	applyAvailability(response, availability)
+
 	c.JSON(http.StatusOK, structs.SuccessResponse{
 		Success: true,
 		Message: "Product fetched successfully",
@@ -104,25 +217,7 @@ func (pc *ProductController) CreateProduct(c *gin.Context) {
 
 	product, err := pc.productService.CreateProduct(&req, file)
 	if err != nil {
-		var statusCode int
-		switch {
-		case strings.Contains(err.Error(), "validation") ||
-			strings.Contains(err.Error(), "invalid") ||
-			strings.Contains(err.Error(), "required") ||
-			strings.Contains(err.Error(), "size must be") ||
-			strings.Contains(err.Error(), "must be a"):
-			statusCode = http.StatusBadRequest
-		case strings.Contains(err.Error(), "failed to create product"):
-			statusCode = http.StatusInternalServerError
-		default:
-			statusCode = http.StatusInternalServerError
-		}
-
-		c.JSON(statusCode, structs.ErrorResponse{
-			Success: false,
-			Message: "Failed to create product",
-			Errors:  map[string]string{"error": err.Error()},
-		})
+		respondServiceError(c, "Failed to create product", err)
 		return
 	}
 
@@ -134,7 +229,7 @@ func (pc *ProductController) CreateProduct(c *gin.Context) {
 	})
 }
 
-func UpdateProduct(c *gin.Context) {
+func (pc *ProductController) UpdateProduct(c *gin.Context) {
 	var product models.Product
 	productId := c.Param("id")
 
@@ -158,27 +253,23 @@ func UpdateProduct(c *gin.Context) {
 		return
 	}
 
-	// Validate category if provided
-	if req.Category != "" {
-		validCategories := []enums.CategoryType{
-			enums.Other, enums.Appetizers, enums.MainCourse, enums.Desserts, enums.Snacks, enums.Food, enums.Pastry,
-		}
-
-		isValidCategory := false
-		for _, validCat := range validCategories {
-			if req.Category == validCat {
-				isValidCategory = true
-				break
-			}
-		}
+	category, err := pc.categoryService.GetActiveCategoryBySlug(req.Category)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Invalid category type",
+		})
+		return
+	}
 
-		if !isValidCategory {
-			c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
-				Success: false,
-				Message: "Invalid category type",
-			})
-			return
-		}
+	sku, barcode, err := pc.productService.ResolveIdentifiers(product.Id, req.Sku, req.Barcode)
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation error",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
 	}
 
 	var newFileName string
@@ -230,18 +321,36 @@ func UpdateProduct(c *gin.Context) {
 
 	// Store old image for cleanup
 	oldImage := product.Image
+	oldPrice := product.Price
 
 	product.Name = req.Name
+	product.Sku = sku
+	product.Barcode = barcode
 	product.Price = req.Price
-	product.Category = req.Category
+	product.CategoryId = category.Id
+	product.Category = *category
 	product.Description = req.Description
-	product.IsAvailable = req.IsAvailable
+	// A tracked product without stock stays sold out until it is restocked
+	if !req.IsAvailable || !product.TrackStock || product.StockQuantity > 0 {
+		product.IsAvailable = req.IsAvailable
+	}
 
 	if shouldUpdateImage {
 		product.Image = newFileName
 	}
 
-	if err := database.DB.Save(&product).Error; err != nil {
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Category", "StockQuantity", "ReservedStock").Save(&product).Error; err != nil {
+			return err
+		}
+
+		if product.Price != oldPrice {
+			return pc.priceService.RecordPriceChange(tx, product.Id, product.Price, c.GetString("Username"))
+		}
+
+		return nil
+	})
+	if err != nil {
 		if shouldUpdateImage {
 			os.Remove(filepath.Join("uploads", newFileName))
 		}
@@ -265,18 +374,7 @@ func UpdateProduct(c *gin.Context) {
 	c.JSON(http.StatusOK, structs.SuccessResponse{
 		Success: true,
 		Message: "Product updated successfully",
-		Data: structs.ProductResponse{
-			Id:           product.Id,
-			Name:         product.Name,
-			Price:        product.Price,
-			Category:     product.Category,
-			CategoryName: product.Category.GetDisplayName(),
-			Image:        product.Image,
-			Description:  product.Description,
-			IsAvailable:  product.IsAvailable,
-			CreatedAt:    product.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:    product.UpdatedAt.Format("2006-01-02 15:04:05"),
-		},
+		Data:    pc.toProductResponse(&product),
 	})
 }
 
@@ -294,27 +392,7 @@ func (pc *ProductController) DeleteProduct(c *gin.Context) {
 
 	err = pc.productService.DeleteProduct(uint(productId))
 	if err != nil {
-		var statusCode int
-		var message string
-
-		switch {
-		case strings.Contains(err.Error(), "not found"):
-			statusCode = http.StatusNotFound
-			message = "Product not found"
-		case strings.Contains(err.Error(), "failed to delete"):
-			statusCode = http.StatusInternalServerError
-			message = "Failed to delete product"
-		default:
-			statusCode = http.StatusInternalServerError
-			message = "Internal server error"
-		}
-
-		c.JSON(statusCode, structs.ErrorResponse{
-			Success: false,
-			Message: message,
-			Errors:  map[string]string{"error": err.Error()},
-		})
-
+		respondServiceError(c, "Failed to delete product", err)
 		return
 	}
 
@@ -403,15 +481,36 @@ func (pc *ProductController) toProductResponse(product *models.Product) *structs
 	return &structs.ProductResponse{
 		Id:           product.Id,
 		Name:         product.Name,
+		Sku:          product.Sku,
+		Barcode:      product.Barcode,
+		Type:         product.Type,
 		Price:        product.Price,
-		Category:     product.Category,
-		CategoryName: product.Category.GetDisplayName(),
+		CategoryId:   product.CategoryId,
+		Category:     product.Category.Slug,
+		CategoryName: product.Category.DisplayName,
 		Image:        product.Image,
 		Description:  product.Description,
 		IsAvailable:  product.IsAvailable,
 		CreatedAt:    product.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:    product.UpdatedAt.Format("2006-01-02 15:04:05"),
+		OptionGroups: toOptionGroupResponses(product.OptionGroups),
+		Variants:     toVariantResponses(product.Variants, product.Price),
+
+		ModifierGroups: toModifierGroupResponses(product.ModifierGroups),
+		Components:     toBundleComponentResponses(product.Components),
+	}
+}
+
+// applyAvailability fills the computed availability, falling back to the stored flag when it could not be evaluated
+func applyAvailability(response *structs.ProductResponse, availability map[uint]services.ProductAvailability) {
+	result, ok := availability[response.Id]
+	if !ok {
+		response.AvailableNow = response.IsAvailable
+		return
 	}
+
+	response.AvailableNow = result.Available
+	response.UnavailableReason = result.Reason
 }
 
 //func DeleteProduct(c *gin.Context) {
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"deck/services"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+type ProductImportController struct {
+	importService *services.ProductImportService
+}
+
+func NewProductImportController(importService *services.ProductImportService) *ProductImportController {
+	return &ProductImportController{
+		importService: importService,
+	}
+}
+
+var productExportContentTypes = map[string]string{
+	"csv":  "text/csv; charset=utf-8",
+	"xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+}
+
+func (pc *ProductImportController) ExportProducts(c *gin.Context) {
+	format := c.DefaultQuery("format", "csv")
+	contentType, ok := productExportContentTypes[format]
+	if !ok {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Invalid format, use csv or xlsx",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("products-%s.%s", time.Now().Format("20060102150405"), format)
+	c.Header("Content-Type", contentType)
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+
+	if err := pc.importService.ExportProducts(format, c.Writer); err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to export products",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+}
+
+func (pc *ProductImportController) ImportProducts(c *gin.Context) {
+	file, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  map[string]string{"file": "file is required"},
+		})
+		return
+	}
+
+	images, err := c.FormFile("images")
+	if err != nil {
+		images = nil
+	}
+
+	dryRun := c.Query("dry_run") == "true" || c.PostForm("dry_run") == "true"
+
+	report, err := pc.importService.ImportProducts(file, images, dryRun, c.GetString("Username"))
+	if err != nil {
+		statusCode := http.StatusInternalServerError
+		if errors.Is(err, services.ErrInvalid) {
+			statusCode = http.StatusUnprocessableEntity
+		}
+
+		c.JSON(statusCode, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to import products",
+			Errors:  map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	if report.Invalid > 0 && !dryRun {
+		c.JSON(http.StatusUnprocessableEntity, structs.SuccessResponse{
+			Success: false,
+			Message: fmt.Sprintf("Import rejected, %d of %d rows are invalid", report.Invalid, report.TotalRows),
+			Data:    report,
+		})
+		return
+	}
+
+	message := "Products imported successfully"
+	if dryRun {
+		message = "Dry run completed, nothing was saved"
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data:    report,
+	})
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ProductPriceController struct {
+	priceService *services.ProductPriceService
+}
+
+func NewProductPriceController(priceService *services.ProductPriceService) *ProductPriceController {
+	return &ProductPriceController{
+		priceService: priceService,
+	}
+}
+
+func (pc *ProductPriceController) GetPriceHistory(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	prices, err := pc.priceService.GetPriceHistory(productId)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch price history", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Price history fetched successfully",
+		Data:    toProductPriceResponses(prices),
+	})
+}
+
+func (pc *ProductPriceController) SchedulePrice(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	var req structs.ProductPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	price, err := pc.priceService.SchedulePrice(productId, &req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to schedule price", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Price scheduled successfully",
+		Data: structs.ProductPriceResponse{
+			Id:            price.Id,
+			ProductId:     price.ProductId,
+			Price:         price.Price,
+			EffectiveFrom: price.EffectiveFrom.Format("2006-01-02 15:04:05"),
+			Notes:         price.Notes,
+			CreatedBy:     price.CreatedBy,
+			IsScheduled:   true,
+			CreatedAt:     price.CreatedAt.Format("2006-01-02 15:04:05"),
+		},
+	})
+}
+
+func (pc *ProductPriceController) DeleteScheduledPrice(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+	priceId, ok := parseUintParam(c, "priceId", "Invalid price ID")
+	if !ok {
+		return
+	}
+
+	if err := pc.priceService.DeleteScheduledPrice(productId, priceId); err != nil {
+		respondServiceError(c, "Failed to delete scheduled price", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Scheduled price deleted successfully",
+	})
+}
+
+func (pc *ProductPriceController) GetPricePeriodReport(c *gin.Context) {
+	productId, err := strconv.ParseUint(c.Query("product_id"), 10, 32)
+	if err != nil || productId == 0 {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Invalid product ID",
+		})
+		return
+	}
+
+	report, err := pc.priceService.GetPricePeriodReport(uint(productId), c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondServiceError(c, "Failed to build price period report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Price period report fetched successfully",
+		Data:    report,
+	})
+}
+
+// toProductPriceResponses maps a price history sorted by effective_from, filling in when each price ended
+// and which one applies right now
+func toProductPriceResponses(prices []models.ProductPrice) []structs.ProductPriceResponse {
+	now := time.Now()
+	responses := make([]structs.ProductPriceResponse, 0, len(prices))
+
+	for i, price := range prices {
+		response := structs.ProductPriceResponse{
+			Id:            price.Id,
+			ProductId:     price.ProductId,
+			Price:         price.Price,
+			EffectiveFrom: price.EffectiveFrom.Format("2006-01-02 15:04:05"),
+			Notes:         price.Notes,
+			CreatedBy:     price.CreatedBy,
+			IsScheduled:   price.EffectiveFrom.After(now),
+			CreatedAt:     price.CreatedAt.Format("2006-01-02 15:04:05"),
+		}
+
+		if i+1 < len(prices) {
+			next := prices[i+1].EffectiveFrom
+			effectiveTo := next.Format("2006-01-02 15:04:05")
+			response.EffectiveTo = &effectiveTo
+			response.IsCurrent = !response.IsScheduled && next.After(now)
+		} else {
+			response.IsCurrent = !response.IsScheduled
+		}
+
+		responses = append(responses, response)
+	}
+
+	return responses
+}
@@ -0,0 +1,221 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ProductVariantController struct {
+	variantService *services.ProductVariantService
+}
+
+func NewProductVariantController(variantService *services.ProductVariantService) *ProductVariantController {
+	return &ProductVariantController{
+		variantService: variantService,
+	}
+}
+
+func (vc *ProductVariantController) GetVariants(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	vc.respondVariants(c, http.StatusOK, "Variants fetched successfully", productId)
+}
+
+func (vc *ProductVariantController) CreateOptionGroup(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	var req structs.ProductOptionGroupCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	group, err := vc.variantService.CreateOptionGroup(productId, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to create option group", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Option group created successfully",
+		Data:    toOptionGroupResponses([]models.ProductOptionGroup{*group})[0],
+	})
+}
+
+func (vc *ProductVariantController) DeleteOptionGroup(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	groupId, ok := parseUintParam(c, "groupId", "Invalid option group ID")
+	if !ok {
+		return
+	}
+
+	if err := vc.variantService.DeleteOptionGroup(productId, groupId); err != nil {
+		respondServiceError(c, "Failed to delete option group", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Option group deleted successfully",
+	})
+}
+
+func (vc *ProductVariantController) CreateVariant(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	var req structs.ProductVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	_, err := vc.variantService.CreateVariant(productId, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to create variant", err)
+		return
+	}
+
+	vc.respondVariants(c, http.StatusCreated, "Variant created successfully", productId)
+}
+
+func (vc *ProductVariantController) UpdateVariant(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	variantId, ok := parseUintParam(c, "variantId", "Invalid variant ID")
+	if !ok {
+		return
+	}
+
+	var req structs.ProductVariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	_, err := vc.variantService.UpdateVariant(productId, variantId, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update variant", err)
+		return
+	}
+
+	vc.respondVariants(c, http.StatusOK, "Variant updated successfully", productId)
+}
+
+func (vc *ProductVariantController) DeleteVariant(c *gin.Context) {
+	productId, ok := parseUintParam(c, "id", "Invalid product ID")
+	if !ok {
+		return
+	}
+
+	variantId, ok := parseUintParam(c, "variantId", "Invalid variant ID")
+	if !ok {
+		return
+	}
+
+	if err := vc.variantService.DeleteVariant(productId, variantId); err != nil {
+		respondServiceError(c, "Failed to delete variant", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Variant deleted successfully",
+	})
+}
+
+// respondVariants writes the full option group and variant configuration of a product
+func (vc *ProductVariantController) respondVariants(c *gin.Context, statusCode int, message string, productId uint) {
+	product, err := vc.variantService.GetVariants(productId)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch variants", err)
+		return
+	}
+
+	c.JSON(statusCode, structs.SuccessResponse{
+		Success: true,
+		Message: message,
+		Data: structs.ProductVariantsResponse{
+			ProductId:    product.Id,
+			OptionGroups: toOptionGroupResponses(product.OptionGroups),
+			Variants:     toVariantResponses(product.Variants, product.Price),
+		},
+	})
+}
+
+func toOptionGroupResponses(groups []models.ProductOptionGroup) []structs.ProductOptionGroupResponse {
+	responses := make([]structs.ProductOptionGroupResponse, 0, len(groups))
+	for _, group := range groups {
+		values := make([]structs.ProductOptionValueResponse, 0, len(group.Values))
+		for _, value := range group.Values {
+			values = append(values, structs.ProductOptionValueResponse{
+				Id:        value.Id,
+				Name:      value.Name,
+				SortOrder: value.SortOrder,
+			})
+		}
+
+		responses = append(responses, structs.ProductOptionGroupResponse{
+			Id:        group.Id,
+			Name:      group.Name,
+			SortOrder: group.SortOrder,
+			Values:    values,
+		})
+	}
+
+	return responses
+}
+
+func toVariantResponses(variants []models.ProductVariant, basePrice uint) []structs.ProductVariantResponse {
+	responses := make([]structs.ProductVariantResponse, 0, len(variants))
+	for _, variant := range variants {
+		valueIds := make([]uint, 0, len(variant.OptionValues))
+		for _, value := range variant.OptionValues {
+			valueIds = append(valueIds, value.Id)
+		}
+
+		responses = append(responses, structs.ProductVariantResponse{
+			Id:             variant.Id,
+			Name:           variant.Name,
+			Price:          variant.ResolvePrice(basePrice),
+			AbsolutePrice:  variant.Price,
+			PriceDelta:     variant.PriceDelta,
+			IsAvailable:    variant.IsAvailable,
+			OptionValueIds: valueIds,
+		})
+	}
+
+	return responses
+}
@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type PurchaseOrderController struct {
+	purchaseOrderService *services.PurchaseOrderService
+}
+
+func NewPurchaseOrderController(purchaseOrderService *services.PurchaseOrderService) *PurchaseOrderController {
+	return &PurchaseOrderController{
+		purchaseOrderService: purchaseOrderService,
+	}
+}
+
+func (pc *PurchaseOrderController) GetSuppliers(c *gin.Context) {
+	suppliers, err := pc.purchaseOrderService.GetSuppliers()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch suppliers",
+		})
+		return
+	}
+
+	responses := make([]structs.SupplierResponse, 0, len(suppliers))
+	for _, supplier := range suppliers {
+		responses = append(responses, toSupplierResponse(supplier))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Suppliers fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (pc *PurchaseOrderController) CreateSupplier(c *gin.Context) {
+	var req structs.SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	supplier, err := pc.purchaseOrderService.CreateSupplier(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create supplier", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Supplier created successfully",
+		Data:    toSupplierResponse(*supplier),
+	})
+}
+
+func (pc *PurchaseOrderController) UpdateSupplier(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid supplier ID")
+	if !ok {
+		return
+	}
+
+	var req structs.SupplierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	supplier, err := pc.purchaseOrderService.UpdateSupplier(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update supplier", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Supplier updated successfully",
+		Data:    toSupplierResponse(*supplier),
+	})
+}
+
+func (pc *PurchaseOrderController) DeleteSupplier(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid supplier ID")
+	if !ok {
+		return
+	}
+
+	if err := pc.purchaseOrderService.DeleteSupplier(id); err != nil {
+		respondServiceError(c, "Failed to delete supplier", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Supplier deleted successfully",
+	})
+}
+
+func (pc *PurchaseOrderController) GetPurchaseOrders(c *gin.Context) {
+	orders, err := pc.purchaseOrderService.GetPurchaseOrders(c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch purchase orders",
+		})
+		return
+	}
+
+	responses := make([]structs.PurchaseOrderResponse, 0, len(orders))
+	for _, order := range orders {
+		responses = append(responses, toPurchaseOrderResponse(order))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Purchase orders fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (pc *PurchaseOrderController) GetPurchaseOrderById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid purchase order ID")
+	if !ok {
+		return
+	}
+
+	order, err := pc.purchaseOrderService.GetPurchaseOrderById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch purchase order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Purchase order fetched successfully",
+		Data:    toPurchaseOrderResponse(*order),
+	})
+}
+
+func (pc *PurchaseOrderController) CreatePurchaseOrder(c *gin.Context) {
+	var req structs.PurchaseOrderCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	order, err := pc.purchaseOrderService.CreatePurchaseOrder(&req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to create purchase order", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Purchase order created successfully",
+		Data:    toPurchaseOrderResponse(*order),
+	})
+}
+
+func (pc *PurchaseOrderController) ReceivePurchaseOrder(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid purchase order ID")
+	if !ok {
+		return
+	}
+
+	var req structs.PurchaseOrderReceiveRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+				Success: false,
+				Message: "Validation Error",
+				Errors:  helpers.TranslateErrorMessage(err),
+			})
+			return
+		}
+	}
+
+	order, err := pc.purchaseOrderService.ReceivePurchaseOrder(id, &req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to receive purchase order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Purchase order received successfully",
+		Data:    toPurchaseOrderResponse(*order),
+	})
+}
+
+func (pc *PurchaseOrderController) CancelPurchaseOrder(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid purchase order ID")
+	if !ok {
+		return
+	}
+
+	order, err := pc.purchaseOrderService.CancelPurchaseOrder(id)
+	if err != nil {
+		respondServiceError(c, "Failed to cancel purchase order", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Purchase order cancelled successfully",
+		Data:    toPurchaseOrderResponse(*order),
+	})
+}
+
+func toSupplierResponse(supplier models.Supplier) structs.SupplierResponse {
+	return structs.SupplierResponse{
+		Id:        supplier.Id,
+		Name:      supplier.Name,
+		Phone:     supplier.Phone,
+		Email:     supplier.Email,
+		Address:   supplier.Address,
+		Notes:     supplier.Notes,
+		CreatedAt: supplier.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: supplier.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toPurchaseOrderResponse(order models.PurchaseOrder) structs.PurchaseOrderResponse {
+	items := make([]structs.PurchaseOrderItemResponse, 0, len(order.Items))
+	for _, item := range order.Items {
+		items = append(items, structs.PurchaseOrderItemResponse{
+			Id:               item.Id,
+			IngredientId:     item.IngredientId,
+			IngredientName:   item.Ingredient.Name,
+			Unit:             item.Ingredient.Unit.Symbol,
+			Quantity:         item.Quantity,
+			ReceivedQuantity: item.ReceivedQuantity,
+			UnitCost:         item.UnitCost,
+		})
+	}
+
+	var receivedAt *string
+	if order.ReceivedAt != nil {
+		receivedAtStr := order.ReceivedAt.Format("2006-01-02 15:04:05")
+		receivedAt = &receivedAtStr
+	}
+
+	return structs.PurchaseOrderResponse{
+		Id:           order.Id,
+		Number:       order.Number,
+		SupplierId:   order.SupplierId,
+		SupplierName: order.Supplier.Name,
+		Status:       order.Status,
+		TotalCost:    order.TotalCost,
+		Notes:        order.Notes,
+		CreatedBy:    order.CreatedBy,
+		ReceivedAt:   receivedAt,
+		CreatedAt:    order.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:    order.UpdatedAt.Format("2006-01-02 15:04:05"),
+		Items:        items,
+	}
+}
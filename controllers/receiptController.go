@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"deck/services"
+	"deck/structs"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+type ReceiptController struct {
+	receiptService *services.ReceiptService
+}
+
+func NewReceiptController(receiptService *services.ReceiptService) *ReceiptController {
+	return &ReceiptController{
+		receiptService: receiptService,
+	}
+}
+
+// GetReceipt renders the receipt of a transaction, format=pdf (default) or format=escpos with width=58 or 80 for
+// raw bytes to send to a thermal printer
+func (rc *ReceiptController) GetReceipt(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	switch c.DefaultQuery("format", "pdf") {
+	case "pdf":
+		pdf, err := rc.receiptService.RenderPdf(id)
+		if err != nil {
+			respondServiceError(c, "Failed to render receipt", err)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("inline; filename=\"receipt-%d.pdf\"", id))
+		c.Data(http.StatusOK, "application/pdf", pdf)
+	case "escpos":
+		var width int
+		switch c.DefaultQuery("width", "80") {
+		case "58":
+			width = services.ReceiptWidth58mm
+		case "80":
+			width = services.ReceiptWidth80mm
+		default:
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Invalid paper width, use 58 or 80",
+				Errors:  map[string]string{"width": c.Query("width")},
+			})
+			return
+		}
+
+		escpos, err := rc.receiptService.RenderEscPos(id, width)
+		if err != nil {
+			respondServiceError(c, "Failed to render receipt", err)
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"receipt-%d.bin\"", id))
+		c.Data(http.StatusOK, "application/octet-stream", escpos)
+	default:
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Invalid receipt format, use pdf or escpos",
+			Errors:  map[string]string{"format": c.Query("format")},
+		})
+	}
+}
@@ -0,0 +1,182 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type ReportController struct {
+	reportService *services.ReportService
+}
+
+func NewReportController(reportService *services.ReportService) *ReportController {
+	return &ReportController{
+		reportService: reportService,
+	}
+}
+
+// GetDailyReport returns the sales summary of the date query, today in the outlet timezone when it is empty
+func (rc *ReportController) GetDailyReport(c *gin.Context) {
+	report, err := rc.reportService.GetDailyReport(c.Query("date"))
+	if err != nil {
+		respondServiceError(c, "Failed to build daily report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Daily report fetched successfully",
+		Data:    report,
+	})
+}
+
+// CloseDay stores the daily report of a date as its Z-report
+func (rc *ReportController) CloseDay(c *gin.Context) {
+	var req structs.ZReportCloseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	zReport, err := rc.reportService.CloseDay(req.Date, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to close day", err)
+		return
+	}
+
+	response, err := toZReportResponse(zReport)
+	if err != nil {
+		respondServiceError(c, "Failed to close day", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Day closed successfully",
+		Data:    response,
+	})
+}
+
+func (rc *ReportController) GetZReports(c *gin.Context) {
+	zReports, err := rc.reportService.GetZReports(c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondServiceError(c, "Failed to fetch z-reports", err)
+		return
+	}
+
+	responses := make([]structs.ZReportResponse, 0, len(zReports))
+	for _, zReport := range zReports {
+		response, err := toZReportResponse(&zReport)
+		if err != nil {
+			respondServiceError(c, "Failed to fetch z-reports", err)
+			return
+		}
+		responses = append(responses, *response)
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Z-reports fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (rc *ReportController) GetZReportById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid z-report ID")
+	if !ok {
+		return
+	}
+
+	zReport, err := rc.reportService.GetZReportById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch z-report", err)
+		return
+	}
+
+	response, err := toZReportResponse(zReport)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch z-report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Z-report fetched successfully",
+		Data:    response,
+	})
+}
+
+// GetProductPerformance ranks products or categories between the from and to dates, see ProductPerformanceQuery
+func (rc *ReportController) GetProductPerformance(c *gin.Context) {
+	query := structs.ProductPerformanceQuery{
+		From:    c.Query("from"),
+		To:      c.Query("to"),
+		GroupBy: c.Query("group_by"),
+		SortBy:  c.Query("sort_by"),
+		Bucket:  c.Query("bucket"),
+	}
+	if value := c.Query("limit"); value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > 100 {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Invalid limit, use 1 to 100",
+			})
+			return
+		}
+		query.Limit = limit
+	}
+
+	report, err := rc.reportService.GetProductPerformance(query)
+	if err != nil {
+		respondServiceError(c, "Failed to build product performance report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Product performance report fetched successfully",
+		Data:    report,
+	})
+}
+
+// GetSalesHeatmap counts paid orders by weekday and hour between the from and to dates
+func (rc *ReportController) GetSalesHeatmap(c *gin.Context) {
+	heatmap, err := rc.reportService.GetSalesHeatmap(c.Query("from"), c.Query("to"))
+	if err != nil {
+		respondServiceError(c, "Failed to build sales heatmap", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Sales heatmap fetched successfully",
+		Data:    heatmap,
+	})
+}
+
+func toZReportResponse(zReport *models.ZReport) (*structs.ZReportResponse, error) {
+	report, err := services.ZReportData(zReport)
+	if err != nil {
+		return nil, err
+	}
+
+	return &structs.ZReportResponse{
+		Id:       zReport.Id,
+		Date:     zReport.BusinessDate.Format("2006-01-02"),
+		ClosedAt: zReport.ClosedAt.Format("2006-01-02 15:04:05"),
+		ClosedBy: zReport.ClosedBy,
+		Orders:   zReport.Orders,
+		NetSales: zReport.NetSales,
+		Report:   *report,
+	}, nil
+}
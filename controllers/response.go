@@ -0,0 +1,48 @@
+package controllers
+
+import (
+	"deck/services"
+	"deck/structs"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// respondServiceError writes the error response of a failed service call, with the status of its error kind:
+// 429 for a rate limit, 422 for a rejected request, 404 for a missing record and 502 for a Midtrans failure
+func respondServiceError(c *gin.Context, message string, err error) {
+	var statusCode int
+	switch {
+	case errors.Is(err, services.ErrTooManyRequests):
+		statusCode = http.StatusTooManyRequests
+	case errors.Is(err, services.ErrInvalid):
+		statusCode = http.StatusUnprocessableEntity
+	case errors.Is(err, services.ErrNotFound):
+		statusCode = http.StatusNotFound
+	case errors.Is(err, services.ErrPaymentGateway):
+		statusCode = http.StatusBadGateway
+	default:
+		statusCode = http.StatusInternalServerError
+	}
+
+	c.JSON(statusCode, structs.ErrorResponse{
+		Success: false,
+		Message: message,
+		Errors:  map[string]string{"error": err.Error()},
+	})
+}
+
+// parseUintParam reads a numeric path parameter and writes a 400 response when it is invalid
+func parseUintParam(c *gin.Context, name string, message string) (uint, bool) {
+	value, err := strconv.ParseUint(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: message,
+		})
+		return 0, false
+	}
+
+	return uint(value), true
+}
@@ -0,0 +1,211 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type ShiftController struct {
+	shiftService *services.ShiftService
+}
+
+func NewShiftController(shiftService *services.ShiftService) *ShiftController {
+	return &ShiftController{
+		shiftService: shiftService,
+	}
+}
+
+// GetShifts lists the latest shifts, filtered by the status and user_id queries
+func (sc *ShiftController) GetShifts(c *gin.Context) {
+	var userId uint
+	if value := c.Query("user_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Invalid user ID",
+			})
+			return
+		}
+		userId = uint(parsed)
+	}
+
+	shifts, err := sc.shiftService.GetShifts(c.Query("status"), userId)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch shifts", err)
+		return
+	}
+
+	responses := make([]structs.ShiftResponse, 0, len(shifts))
+	for _, shift := range shifts {
+		responses = append(responses, toShiftResponse(shift))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Shifts fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (sc *ShiftController) GetCurrentShift(c *gin.Context) {
+	shift, err := sc.shiftService.GetCurrentShift()
+	if err != nil {
+		respondServiceError(c, "Failed to fetch current shift", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Current shift fetched successfully",
+		Data:    toShiftResponse(*shift),
+	})
+}
+
+func (sc *ShiftController) GetShiftById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid shift ID")
+	if !ok {
+		return
+	}
+
+	shift, err := sc.shiftService.GetShiftById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch shift", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Shift fetched successfully",
+		Data:    toShiftResponse(*shift),
+	})
+}
+
+// OpenShift opens the drawer for the signed in cashier
+func (sc *ShiftController) OpenShift(c *gin.Context) {
+	var req structs.ShiftOpenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	shift, err := sc.shiftService.OpenShift(c.GetUint("user_id"), &req)
+	if err != nil {
+		respondServiceError(c, "Failed to open shift", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Shift opened successfully",
+		Data:    toShiftResponse(*shift),
+	})
+}
+
+func (sc *ShiftController) AddCashEntry(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid shift ID")
+	if !ok {
+		return
+	}
+
+	var req structs.ShiftCashEntryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	shift, err := sc.shiftService.AddCashEntry(id, &req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to record cash entry", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Cash entry recorded successfully",
+		Data:    toShiftResponse(*shift),
+	})
+}
+
+func (sc *ShiftController) CloseShift(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid shift ID")
+	if !ok {
+		return
+	}
+
+	var req structs.ShiftCloseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	shift, err := sc.shiftService.CloseShift(id, &req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to close shift", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Shift closed successfully",
+		Data:    toShiftResponse(*shift),
+	})
+}
+
+func toShiftResponse(shift models.Shift) structs.ShiftResponse {
+	response := structs.ShiftResponse{
+		Id:           shift.Id,
+		UserId:       shift.UserId,
+		Status:       shift.Status,
+		OpenedAt:     shift.OpenedAt.Format("2006-01-02 15:04:05"),
+		OpeningFloat: shift.OpeningFloat,
+		OpenNotes:    shift.OpenNotes,
+		ClosedBy:     shift.ClosedBy,
+		CashSales:    shift.CashSales,
+		PaidIn:       shift.PaidIn,
+		PaidOut:      shift.PaidOut,
+		ExpectedCash: shift.ExpectedCash,
+		CountedCash:  shift.CountedCash,
+		Variance:     shift.Variance,
+		CloseNotes:   shift.CloseNotes,
+		CashEntries:  make([]structs.ShiftCashEntryResponse, 0, len(shift.CashEntries)),
+		CreatedAt:    shift.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:    shift.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if shift.User != nil {
+		response.Username = shift.User.Username
+	}
+	if shift.ClosedAt != nil {
+		closedAt := shift.ClosedAt.Format("2006-01-02 15:04:05")
+		response.ClosedAt = &closedAt
+	}
+	for _, entry := range shift.CashEntries {
+		response.CashEntries = append(response.CashEntries, structs.ShiftCashEntryResponse{
+			Id:        entry.Id,
+			Type:      entry.Type,
+			Amount:    entry.Amount,
+			Reason:    entry.Reason,
+			CreatedBy: entry.CreatedBy,
+			CreatedAt: entry.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response
+}
@@ -0,0 +1,132 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+type StockCountController struct {
+	stockCountService *services.StockCountService
+}
+
+func NewStockCountController(stockCountService *services.StockCountService) *StockCountController {
+	return &StockCountController{
+		stockCountService: stockCountService,
+	}
+}
+
+func (sc *StockCountController) GetStockCounts(c *gin.Context) {
+	counts, err := sc.stockCountService.GetStockCounts()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to fetch stock counts",
+		})
+		return
+	}
+
+	responses := make([]structs.StockCountResponse, 0, len(counts))
+	for _, count := range counts {
+		responses = append(responses, toStockCountResponse(count))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Stock counts fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (sc *StockCountController) GetStockCountById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid stock count ID")
+	if !ok {
+		return
+	}
+
+	count, err := sc.stockCountService.GetStockCountById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch stock count", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Stock count fetched successfully",
+		Data:    toStockCountResponse(*count),
+	})
+}
+
+func (sc *StockCountController) CreateStockCount(c *gin.Context) {
+	var req structs.StockCountRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	count, err := sc.stockCountService.CreateStockCount(&req, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to record stock count", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Stock count recorded successfully",
+		Data:    toStockCountResponse(*count),
+	})
+}
+
+func (sc *StockCountController) GetVarianceReport(c *gin.Context) {
+	countId, err := strconv.ParseUint(c.DefaultQuery("count_id", "0"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+			Success: false,
+			Message: "Invalid stock count ID",
+		})
+		return
+	}
+
+	report, err := sc.stockCountService.GetVarianceReport(uint(countId))
+	if err != nil {
+		respondServiceError(c, "Failed to build variance report", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Variance report fetched successfully",
+		Data:    report,
+	})
+}
+
+func toStockCountResponse(count models.StockCount) structs.StockCountResponse {
+	items := make([]structs.StockCountItemResponse, 0, len(count.Items))
+	for _, item := range count.Items {
+		items = append(items, structs.StockCountItemResponse{
+			IngredientId:     item.IngredientId,
+			IngredientName:   item.Ingredient.Name,
+			Unit:             item.Ingredient.Unit.Symbol,
+			ExpectedQuantity: item.ExpectedQuantity,
+			CountedQuantity:  item.CountedQuantity,
+			Variance:         math.Round((item.CountedQuantity-item.ExpectedQuantity)*1000) / 1000,
+		})
+	}
+
+	return structs.StockCountResponse{
+		Id:        count.Id,
+		CountedAt: count.CountedAt.Format("2006-01-02 15:04:05"),
+		Notes:     count.Notes,
+		CreatedBy: count.CreatedBy,
+		Items:     items,
+	}
+}
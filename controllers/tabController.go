@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type TabController struct {
+	tabService *services.TabService
+}
+
+func NewTabController(tabService *services.TabService) *TabController {
+	return &TabController{
+		tabService: tabService,
+	}
+}
+
+func (tc *TabController) AddItems(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	var req structs.TransactionItemsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	transaction, err := tc.tabService.AddItems(id, req.Items, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to add items", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Items added successfully",
+		Data:    toTransactionResponse(transaction),
+	})
+}
+
+func (tc *TabController) VoidItem(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+	detailId, ok := parseUintParam(c, "detailId", "Invalid order line ID")
+	if !ok {
+		return
+	}
+
+	var req structs.TransactionItemVoidRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	transaction, err := tc.tabService.VoidItem(id, detailId, strings.TrimSpace(req.Reason), c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to void item", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Item voided successfully",
+		Data:    toTransactionResponse(transaction),
+	})
+}
+
+func (tc *TabController) StartPayment(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	transaction, err := tc.tabService.StartPayment(id, c.GetString("Username"))
+	if err != nil {
+		respondServiceError(c, "Failed to start payment", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Payment started successfully",
+		Data:    toTransactionResponse(transaction),
+	})
+}
+
+func (tc *TabController) GetChanges(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	changes, err := tc.tabService.GetChanges(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch order changes", err)
+		return
+	}
+
+	responses := make([]structs.TransactionChangeResponse, 0, len(changes))
+	for _, change := range changes {
+		responses = append(responses, toTransactionChangeResponse(change))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Order changes fetched successfully",
+		Data:    responses,
+	})
+}
+
+func toTransactionChangeResponse(change models.TransactionChange) structs.TransactionChangeResponse {
+	return structs.TransactionChangeResponse{
+		Id:                  change.Id,
+		TransactionId:       change.TransactionId,
+		TransactionDetailId: change.TransactionDetailId,
+		Action:              change.Action,
+		ProductName:         change.ProductName,
+		Quantity:            change.Quantity,
+		Amount:              change.Amount,
+		Reason:              change.Reason,
+		TotalBefore:         change.TotalBefore,
+		TotalAfter:          change.TotalAfter,
+		ChangedBy:           change.ChangedBy,
+		CreatedAt:           change.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,335 @@
+package controllers
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/services"
+	"deck/structs"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type TableController struct {
+	tableService *services.TableService
+}
+
+func NewTableController(tableService *services.TableService) *TableController {
+	return &TableController{
+		tableService: tableService,
+	}
+}
+
+func (tc *TableController) GetAreas(c *gin.Context) {
+	areas, err := tc.tableService.GetAreas()
+	if err != nil {
+		respondServiceError(c, "Failed to fetch areas", err)
+		return
+	}
+
+	responses := make([]structs.AreaResponse, 0, len(areas))
+	for _, area := range areas {
+		responses = append(responses, toAreaResponse(area))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Areas fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (tc *TableController) CreateArea(c *gin.Context) {
+	var req structs.AreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	area, err := tc.tableService.CreateArea(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create area", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Area created successfully",
+		Data:    toAreaResponse(*area),
+	})
+}
+
+func (tc *TableController) UpdateArea(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid area ID")
+	if !ok {
+		return
+	}
+
+	var req structs.AreaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	area, err := tc.tableService.UpdateArea(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update area", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Area updated successfully",
+		Data:    toAreaResponse(*area),
+	})
+}
+
+func (tc *TableController) DeleteArea(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid area ID")
+	if !ok {
+		return
+	}
+
+	if err := tc.tableService.DeleteArea(id); err != nil {
+		respondServiceError(c, "Failed to delete area", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Area deleted successfully",
+	})
+}
+
+func (tc *TableController) GetTables(c *gin.Context) {
+	var areaId uint
+	if value := c.Query("area_id"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, structs.ErrorResponse{
+				Success: false,
+				Message: "Invalid area ID",
+			})
+			return
+		}
+		areaId = uint(parsed)
+	}
+
+	tables, err := tc.tableService.GetTables(areaId, c.Query("status"))
+	if err != nil {
+		respondServiceError(c, "Failed to fetch tables", err)
+		return
+	}
+
+	responses := make([]structs.TableResponse, 0, len(tables))
+	for _, table := range tables {
+		responses = append(responses, toTableResponse(table))
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Tables fetched successfully",
+		Data:    responses,
+	})
+}
+
+func (tc *TableController) GetTableById(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid table ID")
+	if !ok {
+		return
+	}
+
+	table, err := tc.tableService.GetTableById(id)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch table", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Table fetched successfully",
+		Data:    toTableResponse(*table),
+	})
+}
+
+func (tc *TableController) CreateTable(c *gin.Context) {
+	var req structs.TableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	table, err := tc.tableService.CreateTable(&req)
+	if err != nil {
+		respondServiceError(c, "Failed to create table", err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Table created successfully",
+		Data:    toTableResponse(*table),
+	})
+}
+
+func (tc *TableController) UpdateTable(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid table ID")
+	if !ok {
+		return
+	}
+
+	var req structs.TableRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	table, err := tc.tableService.UpdateTable(id, &req)
+	if err != nil {
+		respondServiceError(c, "Failed to update table", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Table updated successfully",
+		Data:    toTableResponse(*table),
+	})
+}
+
+func (tc *TableController) DeleteTable(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid table ID")
+	if !ok {
+		return
+	}
+
+	if err := tc.tableService.DeleteTable(id); err != nil {
+		respondServiceError(c, "Failed to delete table", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Table deleted successfully",
+	})
+}
+
+func (tc *TableController) UpdateTableStatus(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid table ID")
+	if !ok {
+		return
+	}
+
+	var req structs.TableStatusRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	table, err := tc.tableService.SetTableStatus(id, req.Status)
+	if err != nil {
+		respondServiceError(c, "Failed to update table status", err)
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Table status updated successfully",
+		Data:    toTableResponse(*table),
+	})
+}
+
+// GetOpenTabs lists the open tabs of every occupied table, or of the table in the :id parameter
+func (tc *TableController) GetOpenTabs(c *gin.Context) {
+	var tableId uint
+	if c.Param("id") != "" {
+		id, ok := parseUintParam(c, "id", "Invalid table ID")
+		if !ok {
+			return
+		}
+		tableId = id
+	}
+
+	tables, err := tc.tableService.GetOpenTabs(tableId)
+	if err != nil {
+		respondServiceError(c, "Failed to fetch open tabs", err)
+		return
+	}
+
+	responses := make([]structs.TableTabsResponse, 0, len(tables))
+	for _, table := range tables {
+		response := structs.TableTabsResponse{
+			Table: toTableResponse(table.Table),
+			Tabs:  make([]structs.TransactionResponse, 0, len(table.Tabs)),
+		}
+		for _, transaction := range table.Tabs {
+			response.OpenAmount += transaction.TotalAmount
+			response.Tabs = append(response.Tabs, *toTransactionResponse(&transaction))
+		}
+		responses = append(responses, response)
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Open tabs fetched successfully",
+		Data:    responses,
+	})
+}
+
+func toAreaResponse(area models.Area) structs.AreaResponse {
+	tables := make([]structs.TableResponse, 0, len(area.Tables))
+	for _, table := range area.Tables {
+		tables = append(tables, toTableResponse(table))
+	}
+
+	return structs.AreaResponse{
+		Id:        area.Id,
+		Name:      area.Name,
+		SortOrder: area.SortOrder,
+		Tables:    tables,
+		CreatedAt: area.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: area.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toTableResponse(table models.Table) structs.TableResponse {
+	response := structs.TableResponse{
+		Id:        table.Id,
+		AreaId:    table.AreaId,
+		Name:      table.Name,
+		Seats:     table.Seats,
+		Status:    table.Status,
+		IsActive:  table.IsActive,
+		CreatedAt: table.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt: table.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+	if table.Area != nil {
+		response.AreaName = table.Area.Name
+	}
+	if table.OccupiedSince != nil {
+		occupiedSince := table.OccupiedSince.Format("2006-01-02 15:04:05")
+		response.OccupiedSince = &occupiedSince
+	}
+
+	return response
+}
@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"deck/helpers"
+	"deck/middlewares"
 	"deck/models"
 	"deck/services"
 	"deck/structs"
@@ -39,46 +40,24 @@ func (tc *TransactionController) CreateTransaction(c *gin.Context) {
 		return
 	}
 
+	// Guests order at a table through its QR code ordering session, only staff seat orders here. Points are
+	// redeemed by staff too, the phone number alone does not prove the customer is the one ordering.
+	if (req.OrderType == models.OrderTypeDineIn || req.TableId != nil || req.RedeemPoints > 0) && !middlewares.Authenticate(c) {
+		return
+	}
+
 	// Create transaction
 	transaction, err := tc.transactionService.CreateTransaction(&req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
-			Success: false,
-			Message: "Failed to create transaction",
-			Errors:  helpers.TranslateErrorMessage(err),
-		})
+		respondServiceError(c, "Failed to create transaction", err)
 		return
 	}
 
 	// Broadcast notification to admin
-	go func() {
-		notificationData := map[string]interface{}{
-			"transaction_id": transaction.Id,
-			"buyer_name":     transaction.BuyerName,
-			"amount":         transaction.TotalAmount,
-			"order_type":     "new_order",
-			"products":       transaction.TransactionDetails,
-		}
-
-		title := "Pesanan Baru"
-		message := fmt.Sprintf("Ada pesanan baru dari %s dengan total Rp %s",
-			transaction.BuyerName,
-			helpers.FormatCurrency(transaction.TotalAmount))
-
-		err := tc.notificationService.BroadcastToAdmins(
-			"new_transaction",
-			title,
-			message,
-			notificationData,
-		)
-
-		if err != nil {
-			fmt.Printf("Failed to broadcast notification: %v\n", err)
-		}
-	}()
+	go tc.broadcastNewOrder(transaction)
 
 	// Convert to response
-	response := tc.toTransactionResponse(transaction)
+	response := toTransactionResponse(transaction)
 
 	c.JSON(http.StatusCreated, structs.SuccessResponse{
 		Success: true,
@@ -87,6 +66,69 @@ func (tc *TransactionController) CreateTransaction(c *gin.Context) {
 	})
 }
 
+// CreateSessionOrder - Place a dine-in order from a guest ordering session opened with a table QR code
+func (tc *TransactionController) CreateSessionOrder(c *gin.Context) {
+	var req structs.TransactionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	if req.RedeemPoints > 0 {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to place order",
+			Errors:  map[string]string{"redeem_points": "Points are redeemed by the cashier"},
+		})
+		return
+	}
+
+	transaction, err := tc.transactionService.CreateSessionOrder(c.Param("token"), &req)
+	if err != nil {
+		respondServiceError(c, "Failed to place order", err)
+		return
+	}
+
+	go tc.broadcastNewOrder(transaction)
+
+	c.JSON(http.StatusCreated, structs.SuccessResponse{
+		Success: true,
+		Message: "Order placed successfully",
+		Data:    toTransactionResponse(transaction),
+	})
+}
+
+// broadcastNewOrder tells the admins about a new order
+func (tc *TransactionController) broadcastNewOrder(transaction *models.Transaction) {
+	notificationData := map[string]interface{}{
+		"transaction_id": transaction.Id,
+		"buyer_name":     transaction.BuyerName,
+		"amount":         transaction.TotalAmount,
+		"order_type":     "new_order",
+		"products":       transaction.TransactionDetails,
+	}
+
+	title := "Pesanan Baru"
+	message := fmt.Sprintf("Ada pesanan baru dari %s dengan total %s",
+		transaction.BuyerName,
+		helpers.FormatCurrency(transaction.TotalAmount))
+
+	err := tc.notificationService.BroadcastToAdmins(
+		"new_transaction",
+		title,
+		message,
+		notificationData,
+	)
+
+	if err != nil {
+		fmt.Printf("Failed to broadcast notification: %v\n", err)
+	}
+}
+
 // GetTransactionByID - Get transaction by ID
 func (tc *TransactionController) GetTransactionByID(c *gin.Context) {
 	idStr := c.Param("id")
@@ -110,7 +152,7 @@ func (tc *TransactionController) GetTransactionByID(c *gin.Context) {
 		return
 	}
 
-	response := tc.toTransactionResponse(transaction)
+	response := toTransactionResponse(transaction)
 
 	c.JSON(http.StatusOK, structs.SuccessResponse{
 		Success: true,
@@ -133,7 +175,7 @@ func (tc *TransactionController) GetAllTransactions(c *gin.Context) {
 
 	var responses []structs.TransactionResponse
 	for _, transaction := range transactions {
-		responses = append(responses, *tc.toTransactionResponse(&transaction))
+		responses = append(responses, *toTransactionResponse(&transaction))
 	}
 
 	c.JSON(http.StatusOK, structs.SuccessResponse{
@@ -143,29 +185,124 @@ func (tc *TransactionController) GetAllTransactions(c *gin.Context) {
 	})
 }
 
+// UpdateTransactionStatus - Mark a pending transaction as paid, failed, expired or cancelled
+func (tc *TransactionController) UpdateTransactionStatus(c *gin.Context) {
+	id, ok := parseUintParam(c, "id", "Invalid transaction ID")
+	if !ok {
+		return
+	}
+
+	var req structs.TransactionStatusUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusUnprocessableEntity, structs.ErrorResponse{
+			Success: false,
+			Message: "Validation Error",
+			Errors:  helpers.TranslateErrorMessage(err),
+		})
+		return
+	}
+
+	transaction, err := tc.transactionService.GetTransactionByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, structs.ErrorResponse{
+			Success: false,
+			Message: "Transaction not found",
+		})
+		return
+	}
+
+	if err := tc.transactionService.UpdateTransactionStatus(transaction.OrderNumber, req.Status); err != nil {
+		respondServiceError(c, "Failed to update transaction status", err)
+		return
+	}
+
+	transaction, err = tc.transactionService.GetTransactionByID(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, structs.ErrorResponse{
+			Success: false,
+			Message: "Failed to retrieve transaction",
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, structs.SuccessResponse{
+		Success: true,
+		Message: "Transaction status updated successfully",
+		Data:    toTransactionResponse(transaction),
+	})
+}
+
 // Convert model to response
-func (tc *TransactionController) toTransactionResponse(transaction *models.Transaction) *structs.TransactionResponse {
+func toTransactionResponse(transaction *models.Transaction) *structs.TransactionResponse {
 	var details []structs.TransactionDetailResponse
 	for _, detail := range transaction.TransactionDetails {
+		modifiers := make([]structs.TransactionDetailModifierResponse, 0, len(detail.Modifiers))
+		for _, modifier := range detail.Modifiers {
+			modifiers = append(modifiers, structs.TransactionDetailModifierResponse{
+				Id:         modifier.Id,
+				ModifierId: modifier.ModifierId,
+				GroupName:  modifier.GroupName,
+				Name:       modifier.Name,
+				PriceDelta: modifier.PriceDelta,
+			})
+		}
+
+		discounts := make([]structs.TransactionDetailDiscountResponse, 0, len(detail.Discounts))
+		for _, discount := range detail.Discounts {
+			discounts = append(discounts, structs.TransactionDetailDiscountResponse{
+				Id:         discount.Id,
+				DiscountId: discount.DiscountId,
+				Name:       discount.Name,
+				Type:       discount.Type,
+				Value:      discount.Value,
+				Amount:     discount.Amount,
+			})
+		}
+
+		var voidedAt *string
+		if detail.VoidedAt != nil {
+			voidedAtStr := detail.VoidedAt.Format("2006-01-02 15:04:05")
+			voidedAt = &voidedAtStr
+		}
+
 		details = append(details, structs.TransactionDetailResponse{
 			Id:            detail.Id,
 			TransactionId: detail.TransactionId,
 			ProductId:     detail.ProductId,
 			ProductName:   detail.ProductName,
+			VariantId:     detail.VariantId,
+			VariantName:   detail.VariantName,
 			Quantity:      detail.Quantity,
 			Price:         detail.Price,
 			TotalPrice:    detail.TotalPrice,
 			Notes:         detail.Notes,
 			CreatedAt:     detail.CreatedAt.Format("2006-01-02 15:04:05"),
 			UpdatedAt:     detail.UpdatedAt.Format("2006-01-02 15:04:05"),
+
+			ParentDetailId: detail.ParentDetailId,
+			ModifiersPrice: detail.ModifiersPrice,
+			Modifiers:      modifiers,
+			DiscountAmount: detail.DiscountAmount,
+			Discounts:      discounts,
+			VoidedAt:       voidedAt,
+			VoidedBy:       detail.VoidedBy,
+			VoidReason:     detail.VoidReason,
 		})
 	}
 
-	var paidAt, expiredAt *string
+	var paymentStartedAt, paidAt, refundedAt, expiredAt *string
+	if transaction.PaymentStartedAt != nil {
+		paymentStartedAtStr := transaction.PaymentStartedAt.Format("2006-01-02 15:04:05")
+		paymentStartedAt = &paymentStartedAtStr
+	}
 	if transaction.PaidAt != nil {
 		paidAtStr := transaction.PaidAt.Format("2006-01-02 15:04:05")
 		paidAt = &paidAtStr
 	}
+	if transaction.RefundedAt != nil {
+		refundedAtStr := transaction.RefundedAt.Format("2006-01-02 15:04:05")
+		refundedAt = &refundedAtStr
+	}
 	if transaction.ExpiredAt != nil {
 		expiredAtStr := transaction.ExpiredAt.Format("2006-01-02 15:04:05")
 		expiredAt = &expiredAtStr
@@ -174,13 +311,22 @@ func (tc *TransactionController) toTransactionResponse(transaction *models.Trans
 	return &structs.TransactionResponse{
 		Id:                 transaction.Id,
 		OrderNumber:        transaction.OrderNumber,
+		CustomerId:         transaction.CustomerId,
 		SubTotal:           transaction.SubTotal,
+		DiscountAmount:     transaction.DiscountAmount,
+		PointsRedeemed:     transaction.PointsRedeemed,
+		PointsDiscount:     transaction.PointsDiscount,
+		PointsEarned:       transaction.PointsEarned,
 		TotalAmount:        transaction.TotalAmount,
 		PaymentStatus:      transaction.PaymentStatus,
 		PaymentMethod:      transaction.PaymentMethod,
+		OrderType:          transaction.OrderType,
+		TableId:            transaction.TableId,
 		BuyerName:          transaction.BuyerName,
 		Phone:              transaction.Phone,
+		PaymentStartedAt:   paymentStartedAt,
 		PaidAt:             paidAt,
+		RefundedAt:         refundedAt,
 		ExpiredAt:          expiredAt,
 		CreatedAt:          transaction.CreatedAt.Format("2006-01-02 15:04:05"),
 		UpdatedAt:          transaction.UpdatedAt.Format("2006-01-02 15:04:05"),
@@ -1,6 +1,7 @@
 package database
 
 import (
+	"deck/config"
 	"deck/models"
 	"fmt"
 	"gorm.io/driver/postgres"
@@ -19,7 +20,7 @@ func InitDB() {
 	dbPort := os.Getenv("DB_PORT")
 	sslMode := os.Getenv("DB_SSL_MODE")
 
-	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=Asia/Jakarta", dbHost, dbUser, dbPassword, dbName, dbPort, sslMode)
+	dsn := fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s TimeZone=%s", dbHost, dbUser, dbPassword, dbName, dbPort, sslMode, config.Timezone())
 
 	var err error
 	DB, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
@@ -28,12 +29,57 @@ func InitDB() {
 		log.Fatal("Failed to connect to database", err)
 	}
 
+	if err = DB.AutoMigrate(&models.Category{}); err != nil {
+		log.Fatal("Failed to migrate database:", err)
+	}
+
+	ConvertProductCategories()
+
 	err = DB.AutoMigrate(
 		&models.User{},
 		&models.Product{},
+		&models.ProductOptionGroup{},
+		&models.ProductOptionValue{},
+		&models.ProductVariant{},
+		&models.ModifierGroup{},
+		&models.Modifier{},
+		&models.BundleComponent{},
+		&models.BundleComponentChoice{},
 		&models.Transaction{},
 		&models.TransactionDetail{},
+		&models.TransactionDetailModifier{},
 		&models.Notification{},
+		&models.StockMovement{},
+		&models.Unit{},
+		&models.Ingredient{},
+		&models.IngredientMovement{},
+		&models.RecipeItem{},
+		&models.Supplier{},
+		&models.PurchaseOrder{},
+		&models.PurchaseOrderItem{},
+		&models.StockCount{},
+		&models.StockCountItem{},
+		&models.AvailabilitySchedule{},
+		&models.AvailabilityBlackout{},
+		&models.ProductPrice{},
+		&models.Discount{},
+		&models.Customer{},
+		&models.LoyaltySetting{},
+		&models.LoyaltyPointEntry{},
+		&models.Area{},
+		&models.Table{},
+		&models.OrderingSession{},
+		&models.TransactionChange{},
+		&models.Payment{},
+		&models.PaymentLine{},
+		&models.TransactionDetailDiscount{},
+		&models.Printer{},
+		&models.Station{},
+		&models.PrintJob{},
+		&models.Shift{},
+		&models.ShiftCashEntry{},
+		&models.ZReport{},
+		&models.ExportJob{},
 	)
 
 	if err != nil {
@@ -42,6 +88,11 @@ func InitDB() {
 
 	fmt.Println("Successfully migrated database")
 
+	RunMigrations()
+
 	SeedUser()
+	SeedCategories()
+	SeedUnits()
+	SeedLoyaltySetting()
 	SeedProducts()
 }
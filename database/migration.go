@@ -0,0 +1,126 @@
+package database
+
+import (
+	"deck/models"
+	"fmt"
+	"gorm.io/gorm"
+	"log"
+)
+
+// Raw SQL migrations for things AutoMigrate can't express (extensions, triggers, special indexes).
+// Every statement must be idempotent because it runs on each startup.
+var migrations = []string{
+	`CREATE EXTENSION IF NOT EXISTS pg_trgm`,
+
+	// Full-text search vector for products. The "simple" config is used because Postgres ships no
+	// Indonesian stemmer and the english one mangles words like "kopi" or "susu".
+	// It is maintained by a trigger rather than a generated column so it can include the category display name.
+	`ALTER TABLE products ADD COLUMN IF NOT EXISTS search_vector tsvector`,
+	`CREATE OR REPLACE FUNCTION products_search_vector_update() RETURNS trigger AS $$
+	BEGIN
+		NEW.search_vector :=
+			setweight(to_tsvector('simple', coalesce(NEW.name, '')), 'A') ||
+			setweight(to_tsvector('simple', coalesce((SELECT display_name FROM categories WHERE id = NEW.category_id), '')), 'B') ||
+			setweight(to_tsvector('simple', coalesce(NEW.description, '')), 'C');
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS trg_products_search_vector ON products`,
+	`CREATE TRIGGER trg_products_search_vector BEFORE INSERT OR UPDATE ON products
+		FOR EACH ROW EXECUTE FUNCTION products_search_vector_update()`,
+	`CREATE OR REPLACE FUNCTION categories_search_vector_refresh() RETURNS trigger AS $$
+	BEGIN
+		UPDATE products SET name = name WHERE category_id = NEW.id;
+		RETURN NEW;
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS trg_categories_search_vector ON categories`,
+	`CREATE TRIGGER trg_categories_search_vector AFTER UPDATE OF display_name ON categories
+		FOR EACH ROW EXECUTE FUNCTION categories_search_vector_refresh()`,
+	`UPDATE products SET name = name WHERE search_vector IS NULL`,
+	`CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector)`,
+	`CREATE INDEX IF NOT EXISTS idx_products_name_trgm ON products USING GIN (name gin_trgm_ops)`,
+
+	// Start the price history of products that have none with their current price
+	`INSERT INTO product_prices (product_id, price, effective_from, notes, created_at, updated_at)
+		SELECT p.id, p.price, p.created_at, 'initial price', now(), now()
+		FROM products p
+		WHERE NOT EXISTS (SELECT 1 FROM product_prices pp WHERE pp.product_id = p.id)`,
+
+	// The outlet has one cash drawer, so at most one shift may be open
+	`CREATE UNIQUE INDEX IF NOT EXISTS idx_shifts_single_open ON shifts (status) WHERE status = 'open'`,
+
+	// Z-reports are the closed books of a day, they are never changed or removed
+	`CREATE OR REPLACE FUNCTION z_reports_immutable() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'z-reports cannot be changed or deleted';
+	END
+	$$ LANGUAGE plpgsql`,
+	`DROP TRIGGER IF EXISTS trg_z_reports_immutable ON z_reports`,
+	`CREATE TRIGGER trg_z_reports_immutable BEFORE UPDATE OR DELETE ON z_reports
+		FOR EACH ROW EXECUTE FUNCTION z_reports_immutable()`,
+}
+
+func RunMigrations() {
+	if DB == nil {
+		fmt.Println("Error: DB is nil in RunMigrations()")
+		return
+	}
+
+	for _, migration := range migrations {
+		if err := DB.Exec(migration).Error; err != nil {
+			log.Fatal("Failed to run migration:", err)
+		}
+	}
+
+	fmt.Println("Successfully ran raw migrations")
+}
+
+// ConvertProductCategories moves products from the old enum "category" column to a category_id
+// referencing the categories table. It must run before AutoMigrate touches the products table.
+func ConvertProductCategories() {
+	if DB == nil {
+		fmt.Println("Error: DB is nil in ConvertProductCategories()")
+		return
+	}
+
+	migrator := DB.Migrator()
+	if !migrator.HasTable("products") || !migrator.HasColumn("products", "category") {
+		return
+	}
+
+	SeedCategories()
+
+	err := DB.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			// Keep any category value that was never part of the enum
+			`INSERT INTO categories (slug, display_name, sort_order, is_active, created_at, updated_at)
+				SELECT DISTINCT p.category, replace(initcap(p.category), '_', ' '), 99, true, now(), now()
+				FROM products p
+				WHERE p.category <> '' AND NOT EXISTS (SELECT 1 FROM categories c WHERE c.slug = p.category)`,
+			`ALTER TABLE products ADD COLUMN IF NOT EXISTS category_id bigint`,
+			`UPDATE products p SET category_id = c.id FROM categories c WHERE c.slug = p.category`,
+			`UPDATE products SET category_id = (SELECT id FROM categories WHERE slug = 'other') WHERE category_id IS NULL`,
+			`ALTER TABLE products ALTER COLUMN category_id SET NOT NULL`,
+			// The old generated search vector depends on the enum column
+			`ALTER TABLE products DROP COLUMN IF EXISTS search_vector`,
+			`ALTER TABLE products DROP COLUMN category`,
+		}
+
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		log.Fatal("Failed to convert product categories:", err)
+	}
+
+	var count int64
+	DB.Model(&models.Product{}).Count(&count)
+	log.Printf("Converted %d products to database-managed categories", count)
+}
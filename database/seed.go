@@ -1,7 +1,6 @@
 package database
 
 import (
-	"deck/enums"
 	"deck/helpers"
 	"deck/models"
 	"fmt"
@@ -35,30 +34,121 @@ func SeedUser() {
 	}
 }
 
+// Categories that used to be hard-coded, kept as the initial data set
+var defaultCategories = []models.Category{
+	{Slug: "appetizers", DisplayName: "Appetizers", SortOrder: 1, IsActive: true},
+	{Slug: "main_course", DisplayName: "Main Course", SortOrder: 2, IsActive: true},
+	{Slug: "desserts", DisplayName: "Desserts", SortOrder: 3, IsActive: true},
+	{Slug: "snacks", DisplayName: "Snacks", SortOrder: 4, IsActive: true},
+	{Slug: "food", DisplayName: "Food", SortOrder: 5, IsActive: true},
+	{Slug: "pastry", DisplayName: "Pastry", SortOrder: 6, IsActive: true},
+	{Slug: "other", DisplayName: "Other", SortOrder: 7, IsActive: true},
+}
+
+func SeedCategories() {
+	if DB == nil {
+		fmt.Println("Error: DB is nil in SeedCategories()")
+		return
+	}
+
+	for _, categoryData := range defaultCategories {
+		var category models.Category
+
+		result := DB.Where("slug = ?", categoryData.Slug).FirstOrCreate(&category, categoryData)
+		if result.Error != nil {
+			log.Printf("Error creating/finding category '%s': %v", categoryData.Slug, result.Error)
+			continue
+		}
+
+		if result.RowsAffected > 0 {
+			log.Printf("Created category: %s", categoryData.Slug)
+		}
+	}
+}
+
+// Common kitchen units, recipes may use any unit of the same dimension as the ingredient
+var defaultUnits = []models.Unit{
+	{Symbol: "g", Name: "Gram", Dimension: models.UnitDimensionMass, Factor: 1},
+	{Symbol: "kg", Name: "Kilogram", Dimension: models.UnitDimensionMass, Factor: 1000},
+	{Symbol: "ml", Name: "Milliliter", Dimension: models.UnitDimensionVolume, Factor: 1},
+	{Symbol: "l", Name: "Liter", Dimension: models.UnitDimensionVolume, Factor: 1000},
+	{Symbol: "pcs", Name: "Pieces", Dimension: models.UnitDimensionCount, Factor: 1},
+}
+
+func SeedUnits() {
+	if DB == nil {
+		fmt.Println("Error: DB is nil in SeedUnits()")
+		return
+	}
+
+	for _, unitData := range defaultUnits {
+		var unit models.Unit
+
+		result := DB.Where("symbol = ?", unitData.Symbol).FirstOrCreate(&unit, unitData)
+		if result.Error != nil {
+			log.Printf("Error creating/finding unit '%s': %v", unitData.Symbol, result.Error)
+			continue
+		}
+
+		if result.RowsAffected > 0 {
+			log.Printf("Created unit: %s", unitData.Symbol)
+		}
+	}
+}
+
+// SeedLoyaltySetting creates the loyalty program row with its defaults
+func SeedLoyaltySetting() {
+	if DB == nil {
+		fmt.Println("Error: DB is nil in SeedLoyaltySetting()")
+		return
+	}
+
+	var setting models.LoyaltySetting
+	result := DB.Order("id ASC").FirstOrCreate(&setting)
+	if result.Error != nil {
+		log.Printf("Error creating loyalty setting: %v", result.Error)
+		return
+	}
+
+	if result.RowsAffected > 0 {
+		log.Printf("Created loyalty setting")
+	}
+}
+
 func SeedProducts() {
 	if DB == nil {
 		fmt.Println("Error: DB is nil in SeedProducts()")
 		return
 	}
 
+	categoryIds := make(map[string]uint)
+	var categories []models.Category
+	if err := DB.Find(&categories).Error; err != nil {
+		log.Printf("Error loading categories for product seeding: %v", err)
+		return
+	}
+	for _, category := range categories {
+		categoryIds[category.Slug] = category.Id
+	}
+
 	productsToSeed := []models.Product{
 		{
 			Name:        "Kopi Lampung",
-			Category:    enums.MainCourse,
+			CategoryId:  categoryIds["main_course"],
 			Description: "Kopi Lampung adalah kopi robusta yang terkenal dengan cita rasa yang kuat dan aroma yang khas. Ditanam di dataran tinggi Lampung, kopi ini memiliki keasaman rendah dan body yang tebal.",
 			Price:       50000,
 			IsAvailable: true,
 		},
 		{
 			Name:        "Indomie Intel Telur",
-			Category:    enums.Other,
+			CategoryId:  categoryIds["other"],
 			Description: "Indomie Intel Telur adalah varian mie instan yang dilengkapi dengan bumbu spesial dan telur. Cocok untuk sarapan cepat atau camilan di sore hari.",
 			Price:       15000,
 			IsAvailable: true,
 		},
 		{
 			Name:        "Teh Kampleng",
-			Category:    enums.MainCourse,
+			CategoryId:  categoryIds["main_course"],
 			Description: "Teh Kampleng adalah teh herbal tradisional yang terbuat dari kamplengan tangan. Dikenal dengan manfaat kesehatan yang baik, teh ini memiliki rasa yang segar dan menantang.",
 			Price:       20000,
 			IsAvailable: true,
@@ -0,0 +1,28 @@
+package helpers
+
+// ValidBarcode reports whether code is an EAN-13, EAN-8 or UPC-A barcode with a correct check digit
+func ValidBarcode(code string) bool {
+	if len(code) != 8 && len(code) != 12 && len(code) != 13 {
+		return false
+	}
+
+	sum := 0
+	for i := len(code) - 1; i >= 0; i-- {
+		digit := code[i]
+		if digit < '0' || digit > '9' {
+			return false
+		}
+		if i == len(code)-1 {
+			continue
+		}
+
+		// Weights alternate 3 and 1 starting from the digit left of the check digit
+		if (len(code)-1-i)%2 == 1 {
+			sum += int(digit-'0') * 3
+		} else {
+			sum += int(digit - '0')
+		}
+	}
+
+	return (10-sum%10)%10 == int(code[len(code)-1]-'0')
+}
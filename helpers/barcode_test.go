@@ -0,0 +1,33 @@
+package helpers
+
+import "testing"
+
+func TestValidBarcode(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want bool
+	}{
+		{"ean-13", "4006381333931", true},
+		{"ean-13 with a wrong check digit", "4006381333932", false},
+		{"indonesian ean-13", "8992761111113", true},
+		{"ean-8", "96385074", true},
+		{"ean-8 with a wrong check digit", "96385075", false},
+		{"upc-a", "036000291452", true},
+		{"upc-a with a wrong check digit", "036000291453", false},
+		{"too short", "1234567", false},
+		{"between lengths", "12345678901", false},
+		{"too long", "40063813339310", false},
+		{"letters", "40063813339A1", false},
+		{"spaces", "4006381 33931", false},
+		{"empty", "", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ValidBarcode(test.code); got != test.want {
+				t.Errorf("ValidBarcode(%q) = %v, want %v", test.code, got, test.want)
+			}
+		})
+	}
+}
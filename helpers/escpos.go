@@ -0,0 +1,154 @@
+package helpers
+
+import (
+	"bytes"
+	"strings"
+	"unicode/utf8"
+)
+
+// EscPos builds a byte stream of ESC/POS commands for thermal receipt printers
+type EscPos struct {
+	buf bytes.Buffer
+}
+
+const (
+	EscPosAlignLeft   = 0
+	EscPosAlignCenter = 1
+	EscPosAlignRight  = 2
+)
+
+// NewEscPos starts a stream with the printer reset to its defaults
+func NewEscPos() *EscPos {
+	p := &EscPos{}
+	p.buf.Write([]byte{0x1B, 0x40})
+	return p
+}
+
+// Align sets the justification of the following lines
+func (p *EscPos) Align(align byte) *EscPos {
+	p.buf.Write([]byte{0x1B, 0x61, align})
+	return p
+}
+
+// Bold turns emphasized printing on or off
+func (p *EscPos) Bold(on bool) *EscPos {
+	p.buf.Write([]byte{0x1B, 0x45, boolByte(on)})
+	return p
+}
+
+// Size sets the character size, 1 is normal and 2 doubles the width or height
+func (p *EscPos) Size(width byte, height byte) *EscPos {
+	p.buf.Write([]byte{0x1D, 0x21, (width-1)<<4 | (height - 1)})
+	return p
+}
+
+// Line prints text followed by a line feed, characters outside ASCII are replaced
+func (p *EscPos) Line(text string) *EscPos {
+	p.buf.WriteString(EscPosText(text))
+	p.buf.WriteByte('\n')
+	return p
+}
+
+// Feed prints and feeds n lines
+func (p *EscPos) Feed(n byte) *EscPos {
+	p.buf.Write([]byte{0x1B, 0x64, n})
+	return p
+}
+
+// QrCode prints data as a QR code with modules of size dots, using model 2 and error correction level M
+func (p *EscPos) QrCode(data string, size byte) *EscPos {
+	length := len(data) + 3
+	p.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x04, 0x00, 0x31, 0x41, 0x32, 0x00})
+	p.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x43, size})
+	p.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x45, 0x31})
+	p.buf.Write([]byte{0x1D, 0x28, 0x6B, byte(length % 256), byte(length / 256), 0x31, 0x50, 0x30})
+	p.buf.WriteString(data)
+	p.buf.Write([]byte{0x1D, 0x28, 0x6B, 0x03, 0x00, 0x31, 0x51, 0x30})
+	return p
+}
+
+// Cut feeds the paper past the cutter and makes a partial cut
+func (p *EscPos) Cut() *EscPos {
+	p.buf.Write([]byte{0x1D, 0x56, 0x42, 0x00})
+	return p
+}
+
+// Bytes returns the stream built so far
+func (p *EscPos) Bytes() []byte {
+	return p.buf.Bytes()
+}
+
+// EscPosText keeps printable ASCII and replaces anything else with '?', printers without a code page
+// for it would print garbage
+func EscPosText(text string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\t' {
+			return ' '
+		}
+		if r < 0x20 || r > 0x7E {
+			return '?'
+		}
+		return r
+	}, text)
+}
+
+// PadColumns lays out left and right text on one line of width characters, cutting the left text when they
+// do not fit. Widths count runes, the printer prints one character for each.
+func PadColumns(left string, right string, width int) string {
+	rightWidth := utf8.RuneCountInString(right)
+	space := width - rightWidth - 1
+	if space < 0 {
+		return right
+	}
+	if utf8.RuneCountInString(left) > space {
+		left = string([]rune(left)[:space])
+	}
+
+	return left + strings.Repeat(" ", width-utf8.RuneCountInString(left)-rightWidth) + right
+}
+
+// WrapText breaks text into lines of at most width characters, on spaces where it can
+func WrapText(text string, width int) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+		lineWidth := 0
+		for _, word := range strings.Fields(paragraph) {
+			runes := []rune(word)
+			for len(runes) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+					lineWidth = 0
+				}
+				lines = append(lines, string(runes[:width]))
+				runes = runes[width:]
+			}
+			word = string(runes)
+			switch {
+			case line == "":
+				line = word
+				lineWidth = len(runes)
+			case lineWidth+1+len(runes) <= width:
+				line += " " + word
+				lineWidth += 1 + len(runes)
+			default:
+				lines = append(lines, line)
+				line = word
+				lineWidth = len(runes)
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func boolByte(on bool) byte {
+	if on {
+		return 1
+	}
+	return 0
+}
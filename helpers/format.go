@@ -7,12 +7,28 @@ import (
 	"path/filepath"
 )
 
+// FormatCurrency writes an amount in Rupiah, 25000 becomes Rp 25.000
 func FormatCurrency(amount uint) string {
-	return fmt.Sprintf("Rp %d", amount)
+	return "Rp " + FormatThousands(amount)
+}
+
+// FormatThousands writes an amount with dots between thousands, 25000 becomes 25.000
+func FormatThousands(amount uint) string {
+	digits := fmt.Sprintf("%d", amount)
+	for i := len(digits) - 3; i > 0; i -= 3 {
+		digits = digits[:i] + "." + digits[i:]
+	}
+
+	return digits
 }
 
 func GenerateUniqueFilename(file *multipart.FileHeader) string {
-	extension := filepath.Ext(file.Filename)
+	return UniqueFilenameFor(file.Filename)
+}
+
+// UniqueFilenameFor returns a random file name keeping the extension of name
+func UniqueFilenameFor(name string) string {
+	extension := filepath.Ext(name)
 	newFileName := uuid.New().String() + extension
 
 	return newFileName
@@ -0,0 +1,41 @@
+package helpers
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+)
+
+var phoneSeparators = strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "")
+
+var e164Pattern = regexp.MustCompile(`^\+[1-9][0-9]{7,14}$`)
+
+// NormalizePhone converts a phone number to E.164. Local Indonesian forms such as 0812..., 62812... and 812...
+// become +62812..., numbers that already carry another country code are kept as they are.
+func NormalizePhone(phone string) (string, error) {
+	phone = phoneSeparators.Replace(strings.TrimSpace(phone))
+
+	switch {
+	case strings.HasPrefix(phone, "+"):
+	case strings.HasPrefix(phone, "62"):
+		phone = "+" + phone
+	case strings.HasPrefix(phone, "0"):
+		phone = "+62" + strings.TrimPrefix(phone, "0")
+	case strings.HasPrefix(phone, "8"):
+		phone = "+62" + phone
+	}
+
+	if !e164Pattern.MatchString(phone) {
+		return "", errors.New("invalid phone number")
+	}
+
+	// Indonesian subscriber numbers are 7 to 12 digits after the country code and never start with 0
+	if strings.HasPrefix(phone, "+62") {
+		national := strings.TrimPrefix(phone, "+62")
+		if len(national) < 7 || len(national) > 12 || national[0] == '0' {
+			return "", errors.New("invalid phone number")
+		}
+	}
+
+	return phone, nil
+}
@@ -0,0 +1,45 @@
+package helpers
+
+import "testing"
+
+func TestNormalizePhone(t *testing.T) {
+	tests := []struct {
+		name    string
+		phone   string
+		want    string
+		wantErr bool
+	}{
+		{"local 08", "081234567890", "+6281234567890", false},
+		{"country code without plus", "6281234567890", "+6281234567890", false},
+		{"e.164", "+6281234567890", "+6281234567890", false},
+		{"without leading zero", "81234567890", "+6281234567890", false},
+		{"spaces", " 0812 3456 7890 ", "+6281234567890", false},
+		{"dashes", "0812-3456-7890", "+6281234567890", false},
+		{"dots and brackets", "(0812) 3456.7890", "+6281234567890", false},
+		{"other country", "+14155552671", "+14155552671", false},
+		{"empty", "", "", true},
+		{"letters", "0812abc7890", "", true},
+		{"too short", "08123", "", true},
+		{"too long", "08123456789012", "", true},
+		{"zero after country code", "+62081234567890", "", true},
+		{"zero country code", "+0812345678", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := NormalizePhone(test.phone)
+			if test.wantErr {
+				if err == nil {
+					t.Errorf("NormalizePhone(%q) = %q, want an error", test.phone, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizePhone(%q): %v", test.phone, err)
+			}
+			if got != test.want {
+				t.Errorf("NormalizePhone(%q) = %q, want %q", test.phone, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"deck/config"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidTableToken is a table token that was not signed by the outlet or is malformed
+var ErrInvalidTableToken = errors.New("invalid table token")
+
+// SignTableToken returns the token printed in a table QR code, "<table id>.<version>.<signature>". Bumping the
+// version invalidates the codes printed before.
+func SignTableToken(tableId uint, version uint) (string, error) {
+	payload := fmt.Sprintf("%d.%d", tableId, version)
+	signature, err := tableTokenSignature(payload)
+	if err != nil {
+		return "", err
+	}
+
+	return payload + "." + signature, nil
+}
+
+// ParseTableToken checks the signature of a table token and returns its table id and version
+func ParseTableToken(token string) (uint, uint, error) {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return 0, 0, ErrInvalidTableToken
+	}
+
+	payload := parts[0] + "." + parts[1]
+	signature, err := tableTokenSignature(payload)
+	if err != nil {
+		return 0, 0, err
+	}
+	if !hmac.Equal([]byte(parts[2]), []byte(signature)) {
+		return 0, 0, ErrInvalidTableToken
+	}
+
+	tableId, err := strconv.ParseUint(parts[0], 10, 32)
+	if err != nil {
+		return 0, 0, ErrInvalidTableToken
+	}
+	version, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return 0, 0, ErrInvalidTableToken
+	}
+
+	return uint(tableId), uint(version), nil
+}
+
+// RandomToken returns a random hex string of n bytes, for session tokens
+func RandomToken(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(buf), nil
+}
+
+// tableTokenKey reads the key of the table QR codes when it is used, so a key set in .env is seen.
+// QR_TOKEN_SECRET falls back to the JWT secret, and the public default key is never used.
+func tableTokenKey() ([]byte, error) {
+	key := config.GetEnv("QR_TOKEN_SECRET", "")
+	if key == "" {
+		key = config.GetEnv("JWT_SECRET", "")
+	}
+	if key == "" || key == "secret_key" {
+		return nil, errors.New("table qr codes are not configured, set QR_TOKEN_SECRET")
+	}
+
+	return []byte(key), nil
+}
+
+func tableTokenSignature(payload string) (string, error) {
+	key, err := tableTokenKey()
+	if err != nil {
+		return "", err
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("table:" + payload))
+
+	// 16 bytes keep the QR code small and are plenty against guessing
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil)[:16]), nil
+}
@@ -0,0 +1,49 @@
+package helpers
+
+import (
+	"deck/config"
+	"errors"
+	"time"
+)
+
+var localTimeLayouts = []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04:05", "2006-01-02T15:04", "2006-01-02"}
+
+// ParseLocalTime parses a date or date-time given in the outlet timezone
+func ParseLocalTime(value string) (time.Time, error) {
+	for _, layout := range localTimeLayouts {
+		if t, err := time.ParseInLocation(layout, value, config.Location()); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, errors.New("invalid time, use YYYY-MM-DD or YYYY-MM-DD HH:MM:SS")
+}
+
+// ParseLocalDateRange parses from and to dates in the outlet timezone. The returned end is exclusive,
+// the start of the day after to, so the whole of the to date is included. Empty values stay nil.
+func ParseLocalDateRange(from string, to string) (*time.Time, *time.Time, error) {
+	var start, end *time.Time
+
+	if from != "" {
+		t, err := time.ParseInLocation("2006-01-02", from, config.Location())
+		if err != nil {
+			return nil, nil, errors.New("invalid from date, use YYYY-MM-DD")
+		}
+		start = &t
+	}
+
+	if to != "" {
+		t, err := time.ParseInLocation("2006-01-02", to, config.Location())
+		if err != nil {
+			return nil, nil, errors.New("invalid to date, use YYYY-MM-DD")
+		}
+		t = t.AddDate(0, 0, 1)
+		end = &t
+	}
+
+	if start != nil && end != nil && !start.Before(*end) {
+		return nil, nil, errors.New("invalid date range, from is after to")
+	}
+
+	return start, end, nil
+}
@@ -16,6 +16,8 @@ func TranslateErrorMessage(err error) map[string]string {
 			switch fieldError.Tag() {
 			case "required":
 				errorsMap[field] = fmt.Sprintf("%s is required", field)
+			case "required_without":
+				errorsMap[field] = fmt.Sprintf("%s is required when %s is empty", field, fieldError.Param())
 			case "email":
 				errorsMap[field] = fmt.Sprintf("%s is not a valid email", field)
 			case "unique":
@@ -2,7 +2,8 @@ package middlewares
 
 import (
 	"deck/config"
-	"fmt"
+	"deck/database"
+	"deck/models"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
 	"net/http"
@@ -13,36 +14,54 @@ var jwtKey = []byte(config.GetEnv("JWT_SECRET", "secret_key"))
 
 func AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		tokenString := c.GetHeader("Authorization")
-
-		if tokenString == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token is required",
-			})
-			c.Abort()
-			return
+		if Authenticate(c) {
+			c.Next()
 		}
+	}
+}
 
-		tokenString = strings.TrimPrefix(tokenString, "Bearer ")
-		claims := &jwt.RegisteredClaims{}
+// Authenticate checks the bearer token of a request and sets the user on the context. It answers 401 and aborts
+// when the token is missing or invalid, for handlers that only need a user for some requests.
+func Authenticate(c *gin.Context) bool {
+	tokenString := c.GetHeader("Authorization")
 
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			return jwtKey, nil
+	if tokenString == "" {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Token is required",
 		})
+		c.Abort()
+		return false
+	}
 
-		fmt.Print(token, err)
+	tokenString = strings.TrimPrefix(tokenString, "Bearer ")
+	claims := &jwt.RegisteredClaims{}
 
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"error": "Token is invalid",
-			})
-			c.Abort()
+	token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
+		return jwtKey, nil
+	})
 
-			return
-		}
+	if err != nil || !token.Valid {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Token is invalid",
+		})
+		c.Abort()
 
-		c.Set("Username", claims.Subject)
+		return false
+	}
+
+	// Controllers tie records to the user by id, the token only carries the username
+	var user models.User
+	if err := database.DB.Select("id").Where("username = ?", claims.Subject).First(&user).Error; err != nil {
+		c.JSON(http.StatusUnauthorized, gin.H{
+			"error": "Token is invalid",
+		})
+		c.Abort()
 
-		c.Next()
+		return false
 	}
+
+	c.Set("Username", claims.Subject)
+	c.Set("user_id", user.Id)
+
+	return true
 }
@@ -0,0 +1,75 @@
+package models
+
+import "time"
+
+// AvailabilitySchedule is a weekly window in which a product, or every product of a category, can be ordered.
+// Days holds one bit per weekday with Sunday as bit 0. Times are HH:MM in the outlet timezone, a window that
+// ends before it starts runs past midnight and equal times cover the whole day.
+type AvailabilitySchedule struct {
+	GormModel
+	ProductId  *uint  `json:"product_id" gorm:"index"`
+	CategoryId *uint  `json:"category_id" gorm:"index"`
+	Label      string `json:"label"`
+	Days       int    `json:"days" gorm:"not null;default:127"`
+	StartTime  string `json:"start_time" gorm:"type:varchar(5);not null"`
+	EndTime    string `json:"end_time" gorm:"type:varchar(5);not null"`
+}
+
+// AvailabilityBlackout makes a product or category unavailable for a range of dates, both ends included
+type AvailabilityBlackout struct {
+	GormModel
+	ProductId  *uint     `json:"product_id" gorm:"index"`
+	CategoryId *uint     `json:"category_id" gorm:"index"`
+	StartDate  time.Time `json:"start_date" gorm:"type:date;not null"`
+	EndDate    time.Time `json:"end_date" gorm:"type:date;not null"`
+	Reason     string    `json:"reason"`
+}
+
+const AllDays = 1<<7 - 1
+
+func (s AvailabilitySchedule) HasDay(day time.Weekday) bool {
+	return s.Days&(1<<uint(day)) != 0
+}
+
+// DayList returns the weekdays of the window, Sunday being 0
+func (s AvailabilitySchedule) DayList() []int {
+	return weekdayList(s.Days)
+}
+
+// Covers reports whether the local time t falls inside the window
+func (s AvailabilitySchedule) Covers(t time.Time) bool {
+	return weeklyWindowCovers(s.Days, s.StartTime, s.EndTime, t)
+}
+
+func weekdayList(days int) []int {
+	list := make([]int, 0, 7)
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if days&(1<<uint(day)) != 0 {
+			list = append(list, int(day))
+		}
+	}
+
+	return list
+}
+
+// weeklyWindowCovers checks t against a weekly window of days and HH:MM times, see AvailabilitySchedule
+func weeklyWindowCovers(days int, startTime string, endTime string, t time.Time) bool {
+	hasDay := func(day time.Weekday) bool { return days&(1<<uint(day)) != 0 }
+	clock := t.Format("15:04")
+
+	switch {
+	case startTime == endTime:
+		return hasDay(t.Weekday())
+	case startTime < endTime:
+		return hasDay(t.Weekday()) && clock >= startTime && clock < endTime
+	default:
+		yesterday := t.AddDate(0, 0, -1).Weekday()
+		return (hasDay(t.Weekday()) && clock >= startTime) || (hasDay(yesterday) && clock < endTime)
+	}
+}
+
+// Covers reports whether the local date of t falls inside the blackout
+func (b AvailabilityBlackout) Covers(t time.Time) bool {
+	date := t.Format("2006-01-02")
+	return date >= b.StartDate.Format("2006-01-02") && date <= b.EndDate.Format("2006-01-02")
+}
@@ -0,0 +1,21 @@
+package models
+
+type BundleComponent struct {
+	GormModel
+	BundleProductId uint                    `json:"bundle_product_id" gorm:"not null;index"`
+	Name            string                  `json:"name" gorm:"not null"`
+	ProductId       uint                    `json:"product_id" gorm:"not null"`
+	Quantity        uint                    `json:"quantity" gorm:"not null;default:1"`
+	SortOrder       int                     `json:"sort_order" gorm:"not null;default:0"`
+	Product         Product                 `json:"product" gorm:"foreignKey:ProductId;references:Id"`
+	Choices         []BundleComponentChoice `json:"choices" gorm:"foreignKey:BundleComponentId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// BundleComponentChoice is an alternative product a customer may swap in for the component's default product
+type BundleComponentChoice struct {
+	GormModel
+	BundleComponentId uint    `json:"bundle_component_id" gorm:"not null;index"`
+	ProductId         uint    `json:"product_id" gorm:"not null"`
+	PriceDelta        int     `json:"price_delta" gorm:"not null;default:0"`
+	Product           Product `json:"product" gorm:"foreignKey:ProductId;references:Id"`
+}
@@ -0,0 +1,10 @@
+package models
+
+type Category struct {
+	GormModel
+	Slug        string `json:"slug" gorm:"type:varchar(100);not null;unique"`
+	DisplayName string `json:"display_name" gorm:"not null"`
+	SortOrder   int    `json:"sort_order" gorm:"not null;default:0"`
+	Icon        string `json:"icon" gorm:"type:varchar(255)"`
+	IsActive    bool   `json:"is_active" gorm:"not null;default:true"`
+}
@@ -0,0 +1,12 @@
+package models
+
+// Customer is a buyer recognised by phone number, stored in E.164 form
+type Customer struct {
+	GormModel
+	Phone string `json:"phone" gorm:"type:varchar(20);not null;unique"`
+	Name  string `json:"name" gorm:"not null"`
+	Email string `json:"email"`
+	Notes string `json:"notes" gorm:"type:text"`
+
+	PointsBalance int `json:"points_balance" gorm:"not null;default:0"`
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Discount is an automatic promotion applied at checkout without a code. It targets a product, a category or,
+// when neither is set, the whole menu. Days, StartTime and EndTime form a weekly window like AvailabilitySchedule,
+// StartDate and EndDate optionally limit the promotion to a range of dates.
+//
+// Stackable discounts combine with each other, a non-stackable one never combines: an order line gets either the
+// best non-stackable discount or every stackable one together, whichever saves more.
+type Discount struct {
+	GormModel
+	Name       string     `json:"name" gorm:"not null"`
+	Type       string     `json:"type" gorm:"type:varchar(20);not null"`
+	Value      uint       `json:"value" gorm:"not null"`
+	ProductId  *uint      `json:"product_id" gorm:"index"`
+	CategoryId *uint      `json:"category_id" gorm:"index"`
+	Days       int        `json:"days" gorm:"not null;default:127"`
+	StartTime  string     `json:"start_time" gorm:"type:varchar(5);not null"`
+	EndTime    string     `json:"end_time" gorm:"type:varchar(5);not null"`
+	StartDate  *time.Time `json:"start_date" gorm:"type:date"`
+	EndDate    *time.Time `json:"end_date" gorm:"type:date"`
+	Stackable  bool       `json:"stackable" gorm:"not null;default:false"`
+	Priority   int        `json:"priority" gorm:"not null;default:0"`
+	IsActive   bool       `json:"is_active" gorm:"not null;default:true"`
+}
+
+// TransactionDetailDiscount snapshots a discount applied to an order line
+type TransactionDetailDiscount struct {
+	GormModel
+	TransactionDetailId uint   `json:"transaction_detail_id" gorm:"not null;index"`
+	DiscountId          uint   `json:"discount_id" gorm:"not null;index"`
+	Name                string `json:"name" gorm:"not null"`
+	Type                string `json:"type" gorm:"type:varchar(20);not null"`
+	Value               uint   `json:"value" gorm:"not null"`
+	Amount              uint   `json:"amount" gorm:"not null"`
+}
+
+const (
+	DiscountTypePercentage = "percentage"
+	DiscountTypeFixed      = "fixed"
+)
+
+// Covers reports whether the discount runs at local time t
+func (d Discount) Covers(t time.Time) bool {
+	date := t.Format("2006-01-02")
+	if d.StartDate != nil && date < d.StartDate.Format("2006-01-02") {
+		return false
+	}
+	if d.EndDate != nil && date > d.EndDate.Format("2006-01-02") {
+		return false
+	}
+
+	return weeklyWindowCovers(d.Days, d.StartTime, d.EndTime, t)
+}
+
+// DayList returns the weekdays the discount runs on, Sunday being 0
+func (d Discount) DayList() []int {
+	return weekdayList(d.Days)
+}
+
+// Targets reports whether the discount applies to the product
+func (d Discount) Targets(product *Product) bool {
+	switch {
+	case d.ProductId != nil:
+		return *d.ProductId == product.Id
+	case d.CategoryId != nil:
+		return *d.CategoryId == product.CategoryId
+	default:
+		return true
+	}
+}
+
+// AmountFor returns how much the discount takes off a line of quantity units totalling lineTotal
+func (d Discount) AmountFor(lineTotal uint, quantity uint) uint {
+	var amount uint
+	switch d.Type {
+	case DiscountTypePercentage:
+		amount = lineTotal * min(d.Value, 100) / 100
+	case DiscountTypeFixed:
+		amount = d.Value * quantity
+	}
+
+	return min(amount, lineTotal)
+}
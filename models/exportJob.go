@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// ExportJob is a CSV or XLSX export written in the background because it is too large to stream in the
+// request. The file is kept until ExpiresAt and then removed.
+type ExportJob struct {
+	GormModel
+	Kind        string     `json:"kind" gorm:"type:varchar(50);not null"`
+	Format      string     `json:"format" gorm:"type:varchar(10);not null"`
+	Language    string     `json:"language" gorm:"type:varchar(5);not null"`
+	Params      string     `json:"params" gorm:"type:jsonb;not null"`
+	Status      string     `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	FileName    string     `json:"file_name"`
+	Rows        int64      `json:"rows" gorm:"not null;default:0"`
+	Error       string     `json:"error" gorm:"type:text"`
+	CreatedBy   string     `json:"created_by" gorm:"type:varchar(100)"`
+	CompletedAt *time.Time `json:"completed_at"`
+	ExpiresAt   *time.Time `json:"expires_at" gorm:"index"`
+}
+
+const (
+	ExportJobStatusPending   = "pending"
+	ExportJobStatusRunning   = "running"
+	ExportJobStatusCompleted = "completed"
+	ExportJobStatusFailed    = "failed"
+	ExportJobStatusExpired   = "expired"
+)
@@ -0,0 +1,34 @@
+package models
+
+type Ingredient struct {
+	GormModel
+	Name          string  `json:"name" gorm:"not null;unique"`
+	UnitId        uint    `json:"unit_id" gorm:"not null"`
+	StockQuantity float64 `json:"stock_quantity" gorm:"type:numeric(14,3);not null;default:0"`
+	LowStockLevel float64 `json:"low_stock_level" gorm:"type:numeric(14,3);not null;default:0"`
+	CostPerUnit   float64 `json:"cost_per_unit" gorm:"type:numeric(14,2);not null;default:0"`
+	IsActive      bool    `json:"is_active" gorm:"not null;default:true"`
+	Unit          Unit    `json:"unit" gorm:"foreignKey:UnitId;references:Id"`
+}
+
+type IngredientMovement struct {
+	GormModel
+	IngredientId    uint    `json:"ingredient_id" gorm:"not null;index"`
+	Type            string  `json:"type" gorm:"type:varchar(20);not null"`
+	Quantity        float64 `json:"quantity" gorm:"type:numeric(14,3);not null"`
+	StockAfter      float64 `json:"stock_after" gorm:"type:numeric(14,3);not null"`
+	TransactionId   *uint   `json:"transaction_id" gorm:"index"`
+	PurchaseOrderId *uint   `json:"purchase_order_id" gorm:"index"`
+	StockCountId    *uint   `json:"stock_count_id" gorm:"index"`
+	Reference       string  `json:"reference"`
+	Notes           string  `json:"notes"`
+	CreatedBy       string  `json:"created_by"`
+}
+
+const (
+	IngredientMovementSale       = "sale"
+	IngredientMovementPurchase   = "purchase"
+	IngredientMovementWaste      = "waste"
+	IngredientMovementAdjustment = "adjustment"
+	IngredientMovementCount      = "count"
+)
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// LoyaltySetting holds the rules of the loyalty program, the table has a single row
+type LoyaltySetting struct {
+	GormModel
+	IsActive         bool `json:"is_active" gorm:"not null;default:true"`
+	EarnAmount       uint `json:"earn_amount" gorm:"not null;default:10000"`      // spend that earns one point
+	MinimumSpend     uint `json:"minimum_spend" gorm:"not null;default:0"`        // orders below this earn nothing
+	PointValue       uint `json:"point_value" gorm:"not null;default:100"`        // rupiah taken off per redeemed point
+	MinRedeemPoints  uint `json:"min_redeem_points" gorm:"not null;default:0"`    // smallest redemption allowed
+	MaxRedeemPercent uint `json:"max_redeem_percent" gorm:"not null;default:100"` // share of an order points may pay for
+	ExpiryDays       uint `json:"expiry_days" gorm:"not null;default:365"`        // 0 keeps points forever
+}
+
+// LoyaltyPointEntry is one line of a customer's points ledger. Points is signed, entries that add points keep
+// what is left of them in Remaining so redemptions and expiry consume the oldest points first.
+type LoyaltyPointEntry struct {
+	GormModel
+	CustomerId    uint       `json:"customer_id" gorm:"not null;index"`
+	TransactionId *uint      `json:"transaction_id" gorm:"index"`
+	Type          string     `json:"type" gorm:"type:varchar(20);not null"`
+	Points        int        `json:"points" gorm:"not null"`
+	Remaining     int        `json:"remaining" gorm:"not null;default:0"`
+	BalanceAfter  int        `json:"balance_after" gorm:"not null"`
+	ExpiresAt     *time.Time `json:"expires_at" gorm:"index"`
+	Notes         string     `json:"notes"`
+	CreatedBy     string     `json:"created_by"`
+}
+
+const (
+	LoyaltyEntryEarn   = "earn"
+	LoyaltyEntryRedeem = "redeem"
+	LoyaltyEntryExpire = "expire"
+	LoyaltyEntryAdjust = "adjust"
+)
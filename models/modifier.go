@@ -0,0 +1,29 @@
+package models
+
+type ModifierGroup struct {
+	GormModel
+	Name       string     `json:"name" gorm:"not null"`
+	MinSelect  uint       `json:"min_select" gorm:"not null;default:0"`
+	MaxSelect  uint       `json:"max_select" gorm:"not null;default:0"` // 0 means no limit
+	IsRequired bool       `json:"is_required" gorm:"not null;default:false"`
+	SortOrder  int        `json:"sort_order" gorm:"not null;default:0"`
+	Modifiers  []Modifier `json:"modifiers" gorm:"foreignKey:ModifierGroupId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+type Modifier struct {
+	GormModel
+	ModifierGroupId uint   `json:"modifier_group_id" gorm:"not null;index"`
+	Name            string `json:"name" gorm:"not null"`
+	PriceDelta      int    `json:"price_delta" gorm:"not null;default:0"`
+	IsAvailable     bool   `json:"is_available" gorm:"not null;default:true"`
+	SortOrder       int    `json:"sort_order" gorm:"not null;default:0"`
+}
+
+// MinRequired returns how many modifiers must be picked from the group
+func (g *ModifierGroup) MinRequired() uint {
+	if g.IsRequired && g.MinSelect == 0 {
+		return 1
+	}
+
+	return g.MinSelect
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// OrderingSession is opened by a guest scanning a table QR code, it lets their phone place orders on that table
+// until it expires or the table is cleared
+type OrderingSession struct {
+	GormModel
+	Token       string     `json:"-" gorm:"type:varchar(64);not null;unique"`
+	TableId     uint       `json:"table_id" gorm:"not null;index"`
+	Table       *Table     `json:"table,omitempty" gorm:"foreignKey:TableId;references:Id"`
+	ClientIp    string     `json:"client_ip" gorm:"type:varchar(45);index"`
+	ExpiresAt   time.Time  `json:"expires_at" gorm:"not null"`
+	OrderCount  uint       `json:"order_count" gorm:"not null;default:0"`
+	LastOrderAt *time.Time `json:"last_order_at"`
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// Payment is one payment towards a transaction. A bill can be split over several payments, by amount, by order
+// lines or evenly between people, and the transaction is paid once its paid payments cover TotalAmount.
+type Payment struct {
+	GormModel
+	TransactionId   uint          `json:"transaction_id" gorm:"not null;index"`
+	Method          string        `json:"method" gorm:"type:varchar(20);not null"`
+	Amount          uint          `json:"amount" gorm:"not null"`
+	Tendered        uint          `json:"tendered" gorm:"not null;default:0"`
+	Change          uint          `json:"change" gorm:"not null;default:0"`
+	Status          string        `json:"status" gorm:"type:varchar(20);not null;default:pending"`
+	SplitType       string        `json:"split_type" gorm:"type:varchar(20);not null;default:amount"`
+	SplitLabel      string        `json:"split_label"`
+	Reference       string        `json:"reference"`
+	MidtransOrderId *string       `json:"midtrans_order_id" gorm:"type:varchar(100);uniqueIndex"`
+	MidtransToken   string        `json:"midtrans_token"`
+	RedirectUrl     string        `json:"redirect_url"`
+	PaidAt          *time.Time    `json:"paid_at"`
+	CreatedBy       string        `json:"created_by"`
+	Lines           []PaymentLine `json:"lines" gorm:"foreignKey:PaymentId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// PaymentLine ties a payment split by items to the order lines it pays for
+type PaymentLine struct {
+	GormModel
+	PaymentId           uint `json:"payment_id" gorm:"not null;index"`
+	TransactionDetailId uint `json:"transaction_detail_id" gorm:"not null;index"`
+	Amount              uint `json:"amount" gorm:"not null"`
+}
+
+// PaymentStatusRefundDue marks a payment that went through after its order was already closed, the money has to
+// be given back to the customer. Payments otherwise share the statuses of transactions.
+const PaymentStatusRefundDue = "refund_due"
+
+// PaymentStatusReview marks a Midtrans payment that settled for less than it asked, an admin has to check it with
+// Midtrans. It does not count towards the bill.
+const PaymentStatusReview = "review"
+
+const (
+	PaymentMethodCash     = "cash"
+	PaymentMethodCard     = "card"
+	PaymentMethodQris     = "qris"
+	PaymentMethodTransfer = "transfer"
+	PaymentMethodMidtrans = "midtrans"
+	PaymentMethodSplit    = "split"
+)
+
+const (
+	PaymentSplitAmount = "amount"
+	PaymentSplitItems  = "items"
+	PaymentSplitEven   = "even"
+)
@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// Printer is a network thermal printer that takes raw ESC/POS on a TCP port, usually 9100
+type Printer struct {
+	GormModel
+	Name       string `json:"name" gorm:"type:varchar(100);not null;unique"`
+	Host       string `json:"host" gorm:"type:varchar(255);not null"`
+	Port       uint   `json:"port" gorm:"not null;default:9100"`
+	PaperWidth uint   `json:"paper_width" gorm:"not null;default:80"`
+	IsActive   bool   `json:"is_active" gorm:"not null;default:true"`
+}
+
+// Station is a place that prepares orders, like the bar or the kitchen. The products of its categories
+// are printed on its printer when an order is paid.
+type Station struct {
+	GormModel
+	Name       string     `json:"name" gorm:"type:varchar(100);not null;unique"`
+	PrinterId  *uint      `json:"printer_id" gorm:"index"`
+	Printer    *Printer   `json:"printer,omitempty" gorm:"foreignKey:PrinterId;references:Id"`
+	IsActive   bool       `json:"is_active" gorm:"not null;default:true"`
+	Categories []Category `json:"categories" gorm:"many2many:station_categories;constraint:OnDelete:CASCADE"`
+}
+
+// PrintJob is a ticket waiting for, or sent to, a printer. The ticket is rendered when the job is queued so
+// a retry prints exactly the same thing.
+type PrintJob struct {
+	GormModel
+	TransactionId uint         `json:"transaction_id" gorm:"not null;index"`
+	Transaction   *Transaction `json:"transaction,omitempty" gorm:"foreignKey:TransactionId;references:Id"`
+	StationId     uint         `json:"station_id" gorm:"not null;index"`
+	Station       *Station     `json:"station,omitempty" gorm:"foreignKey:StationId;references:Id"`
+	PrinterId     uint         `json:"printer_id" gorm:"not null;index"`
+	Printer       *Printer     `json:"printer,omitempty" gorm:"foreignKey:PrinterId;references:Id"`
+	Kind          string       `json:"kind" gorm:"type:varchar(20);not null"`
+	Payload       []byte       `json:"-" gorm:"not null"`
+	Status        string       `json:"status" gorm:"type:varchar(20);not null;default:pending;index"`
+	Attempts      uint         `json:"attempts" gorm:"not null;default:0"`
+	LastError     string       `json:"last_error" gorm:"type:text"`
+	NextAttemptAt time.Time    `json:"next_attempt_at" gorm:"not null;index"`
+	PrintedAt     *time.Time   `json:"printed_at"`
+	CreatedBy     string       `json:"created_by" gorm:"type:varchar(100)"`
+}
+
+const (
+	PrintJobKindTicket  = "ticket"
+	PrintJobKindReprint = "reprint"
+)
+
+const (
+	PrintJobStatusPending  = "pending"
+	PrintJobStatusPrinting = "printing"
+	PrintJobStatusPrinted  = "printed"
+	PrintJobStatusFailed   = "failed"
+)
@@ -1,13 +1,28 @@
 package models
 
-import "deck/enums"
-
 type Product struct {
 	GormModel
-	Name        string             `json:"name" gorm:"not null"`
-	Category    enums.CategoryType `json:"category" gorm:"not null"`
-	Description string             `json:"description" gorm:"type:text"`
-	Image       string             `json:"image" gorm:"type:varchar(255)"`
-	Price       uint               `json:"price" gorm:"not null"`
-	IsAvailable bool               `json:"is_available" gorm:"not null;default:true"`
+	Name           string               `json:"name" gorm:"not null"`
+	Sku            *string              `json:"sku" gorm:"type:varchar(64);uniqueIndex"`
+	Barcode        *string              `json:"barcode" gorm:"type:varchar(14);uniqueIndex"`
+	Type           string               `json:"type" gorm:"type:varchar(20);not null;default:single"`
+	CategoryId     uint                 `json:"category_id" gorm:"not null"`
+	Description    string               `json:"description" gorm:"type:text"`
+	Image          string               `json:"image" gorm:"type:varchar(255)"`
+	Price          uint                 `json:"price" gorm:"not null"`
+	IsAvailable    bool                 `json:"is_available" gorm:"not null;default:true"`
+	TrackStock     bool                 `json:"track_stock" gorm:"not null;default:false"`
+	StockQuantity  int                  `json:"stock_quantity" gorm:"not null;default:0"`
+	ReservedStock  int                  `json:"reserved_stock" gorm:"not null;default:0"`
+	LowStockLevel  int                  `json:"low_stock_level" gorm:"not null;default:0"`
+	Category       Category             `json:"category" gorm:"foreignKey:CategoryId;references:Id"`
+	OptionGroups   []ProductOptionGroup `json:"option_groups,omitempty" gorm:"foreignKey:ProductId;references:Id;constraint:OnDelete:CASCADE"`
+	Variants       []ProductVariant     `json:"variants,omitempty" gorm:"foreignKey:ProductId;references:Id;constraint:OnDelete:CASCADE"`
+	ModifierGroups []ModifierGroup      `json:"modifier_groups,omitempty" gorm:"many2many:product_modifier_groups;constraint:OnDelete:CASCADE"`
+	Components     []BundleComponent    `json:"components,omitempty" gorm:"foreignKey:BundleProductId;references:Id;constraint:OnDelete:CASCADE"`
 }
+
+const (
+	ProductTypeSingle = "single"
+	ProductTypeBundle = "bundle"
+)
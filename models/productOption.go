@@ -0,0 +1,16 @@
+package models
+
+type ProductOptionGroup struct {
+	GormModel
+	ProductId uint                 `json:"product_id" gorm:"not null;index"`
+	Name      string               `json:"name" gorm:"not null"`
+	SortOrder int                  `json:"sort_order" gorm:"not null;default:0"`
+	Values    []ProductOptionValue `json:"values" gorm:"foreignKey:OptionGroupId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+type ProductOptionValue struct {
+	GormModel
+	OptionGroupId uint   `json:"option_group_id" gorm:"not null;index"`
+	Name          string `json:"name" gorm:"not null"`
+	SortOrder     int    `json:"sort_order" gorm:"not null;default:0"`
+}
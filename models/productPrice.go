@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// ProductPrice is one entry of a product's price history. A price applies from EffectiveFrom until the next entry.
+type ProductPrice struct {
+	GormModel
+	ProductId     uint      `json:"product_id" gorm:"not null;index:idx_product_prices_effective,priority:1"`
+	Price         uint      `json:"price" gorm:"not null"`
+	EffectiveFrom time.Time `json:"effective_from" gorm:"not null;index:idx_product_prices_effective,priority:2"`
+	Notes         string    `json:"notes"`
+	CreatedBy     string    `json:"created_by"`
+}
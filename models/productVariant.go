@@ -0,0 +1,29 @@
+package models
+
+type ProductVariant struct {
+	GormModel
+	ProductId     uint                 `json:"product_id" gorm:"not null;index"`
+	Name          string               `json:"name" gorm:"not null"`
+	Price         *uint                `json:"price"`
+	PriceDelta    int                  `json:"price_delta" gorm:"not null;default:0"`
+	IsAvailable   bool                 `json:"is_available" gorm:"not null;default:true"`
+	TrackStock    bool                 `json:"track_stock" gorm:"not null;default:false"`
+	StockQuantity int                  `json:"stock_quantity" gorm:"not null;default:0"`
+	ReservedStock int                  `json:"reserved_stock" gorm:"not null;default:0"`
+	LowStockLevel int                  `json:"low_stock_level" gorm:"not null;default:0"`
+	OptionValues  []ProductOptionValue `json:"option_values" gorm:"many2many:product_variant_option_values;constraint:OnDelete:CASCADE"`
+}
+
+// ResolvePrice returns the absolute variant price when set, otherwise the base price adjusted by the delta
+func (v *ProductVariant) ResolvePrice(basePrice uint) uint {
+	if v.Price != nil {
+		return *v.Price
+	}
+
+	price := int(basePrice) + v.PriceDelta
+	if price < 0 {
+		return 0
+	}
+
+	return uint(price)
+}
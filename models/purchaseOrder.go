@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+type PurchaseOrder struct {
+	GormModel
+	Number     string              `json:"number" gorm:"not null;unique"`
+	SupplierId uint                `json:"supplier_id" gorm:"not null;index"`
+	Status     string              `json:"status" gorm:"type:varchar(20);not null;default:ordered"`
+	TotalCost  float64             `json:"total_cost" gorm:"type:numeric(14,2);not null;default:0"`
+	Notes      string              `json:"notes"`
+	CreatedBy  string              `json:"created_by"`
+	ReceivedAt *time.Time          `json:"received_at"`
+	Supplier   Supplier            `json:"supplier" gorm:"foreignKey:SupplierId;references:Id"`
+	Items      []PurchaseOrderItem `json:"items" gorm:"foreignKey:PurchaseOrderId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// PurchaseOrderItem quantities are in the unit of the ingredient
+type PurchaseOrderItem struct {
+	GormModel
+	PurchaseOrderId  uint       `json:"purchase_order_id" gorm:"not null;index"`
+	IngredientId     uint       `json:"ingredient_id" gorm:"not null"`
+	Quantity         float64    `json:"quantity" gorm:"type:numeric(14,3);not null"`
+	ReceivedQuantity float64    `json:"received_quantity" gorm:"type:numeric(14,3);not null;default:0"`
+	UnitCost         float64    `json:"unit_cost" gorm:"type:numeric(14,2);not null;default:0"`
+	Ingredient       Ingredient `json:"ingredient" gorm:"foreignKey:IngredientId;references:Id"`
+}
+
+const (
+	PurchaseOrderStatusOrdered   = "ordered"
+	PurchaseOrderStatusReceived  = "received"
+	PurchaseOrderStatusCancelled = "cancelled"
+)
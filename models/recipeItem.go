@@ -0,0 +1,11 @@
+package models
+
+type RecipeItem struct {
+	GormModel
+	ProductId    uint       `json:"product_id" gorm:"not null;index"`
+	IngredientId uint       `json:"ingredient_id" gorm:"not null"`
+	Quantity     float64    `json:"quantity" gorm:"type:numeric(14,3);not null"`
+	UnitId       uint       `json:"unit_id" gorm:"not null"`
+	Ingredient   Ingredient `json:"ingredient" gorm:"foreignKey:IngredientId;references:Id"`
+	Unit         Unit       `json:"unit" gorm:"foreignKey:UnitId;references:Id"`
+}
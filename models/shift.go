@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// Shift is a cashier's turn on the cash drawer. It opens with a float, cash goes in and out through sales and
+// paid-in or paid-out entries, and it closes with the counted cash compared to what the drawer should hold.
+// Only one shift can be open at a time since the outlet has one drawer.
+type Shift struct {
+	GormModel
+	UserId       uint             `json:"user_id" gorm:"not null;index"`
+	User         *User            `json:"user,omitempty" gorm:"foreignKey:UserId;references:Id"`
+	Status       string           `json:"status" gorm:"type:varchar(20);not null;default:open;index"`
+	OpenedAt     time.Time        `json:"opened_at" gorm:"not null"`
+	OpeningFloat uint             `json:"opening_float" gorm:"not null;default:0"`
+	OpenNotes    string           `json:"open_notes"`
+	ClosedAt     *time.Time       `json:"closed_at"`
+	ClosedBy     string           `json:"closed_by"`
+	CashSales    uint             `json:"cash_sales" gorm:"not null;default:0"`
+	PaidIn       uint             `json:"paid_in" gorm:"not null;default:0"`
+	PaidOut      uint             `json:"paid_out" gorm:"not null;default:0"`
+	ExpectedCash uint             `json:"expected_cash" gorm:"not null;default:0"`
+	CountedCash  *uint            `json:"counted_cash"`
+	Variance     int              `json:"variance" gorm:"not null;default:0"`
+	CloseNotes   string           `json:"close_notes"`
+	CashEntries  []ShiftCashEntry `json:"cash_entries" gorm:"foreignKey:ShiftId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// ShiftCashEntry is cash put in or taken out of the drawer outside of sales, like change brought from the bank,
+// buying ice or handing back a cash refund
+type ShiftCashEntry struct {
+	GormModel
+	ShiftId   uint   `json:"shift_id" gorm:"not null;index"`
+	Type      string `json:"type" gorm:"type:varchar(20);not null"`
+	Amount    uint   `json:"amount" gorm:"not null"`
+	Reason    string `json:"reason" gorm:"not null"`
+	CreatedBy string `json:"created_by"`
+}
+
+const (
+	ShiftStatusOpen   = "open"
+	ShiftStatusClosed = "closed"
+)
+
+const (
+	ShiftCashPaidIn  = "paid_in"
+	ShiftCashPaidOut = "paid_out"
+)
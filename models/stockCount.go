@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type StockCount struct {
+	GormModel
+	CountedAt time.Time        `json:"counted_at" gorm:"not null;index"`
+	Notes     string           `json:"notes"`
+	CreatedBy string           `json:"created_by"`
+	Items     []StockCountItem `json:"items" gorm:"foreignKey:StockCountId;references:Id;constraint:OnDelete:CASCADE"`
+}
+
+// StockCountItem keeps the theoretical stock at the moment of counting next to what was actually counted
+type StockCountItem struct {
+	GormModel
+	StockCountId     uint       `json:"stock_count_id" gorm:"not null;index"`
+	IngredientId     uint       `json:"ingredient_id" gorm:"not null"`
+	ExpectedQuantity float64    `json:"expected_quantity" gorm:"type:numeric(14,3);not null"`
+	CountedQuantity  float64    `json:"counted_quantity" gorm:"type:numeric(14,3);not null"`
+	Ingredient       Ingredient `json:"ingredient" gorm:"foreignKey:IngredientId;references:Id"`
+}
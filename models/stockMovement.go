@@ -0,0 +1,27 @@
+package models
+
+type StockMovement struct {
+	GormModel
+	ProductId     uint   `json:"product_id" gorm:"not null;index"`
+	VariantId     *uint  `json:"variant_id" gorm:"index"`
+	Type          string `json:"type" gorm:"type:varchar(20);not null"`
+	Quantity      int    `json:"quantity" gorm:"not null"`
+	StockAfter    int    `json:"stock_after" gorm:"not null"`
+	TransactionId *uint  `json:"transaction_id" gorm:"index"`
+	Reference     string `json:"reference"`
+	Notes         string `json:"notes"`
+	CreatedBy     string `json:"created_by"`
+}
+
+const (
+	StockMovementSale       = "sale"
+	StockMovementRestock    = "restock"
+	StockMovementWaste      = "waste"
+	StockMovementAdjustment = "adjustment"
+)
+
+// Stock source recorded on an order line that reserved stock
+const (
+	StockSourceProduct = "product"
+	StockSourceVariant = "variant"
+)
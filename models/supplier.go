@@ -0,0 +1,10 @@
+package models
+
+type Supplier struct {
+	GormModel
+	Name    string `json:"name" gorm:"not null"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email"`
+	Address string `json:"address"`
+	Notes   string `json:"notes"`
+}
@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// Area groups tables of the outlet, like the terrace or the second floor
+type Area struct {
+	GormModel
+	Name      string  `json:"name" gorm:"not null;unique"`
+	SortOrder int     `json:"sort_order" gorm:"not null;default:0"`
+	Tables    []Table `json:"tables" gorm:"foreignKey:AreaId;references:Id"`
+}
+
+// Table is a dine-in table. Its status follows the orders placed on it: seating an order occupies it and once
+// its last open tab is closed it waits for cleaning, or is free again when nothing was paid.
+type Table struct {
+	GormModel
+	AreaId        uint       `json:"area_id" gorm:"not null;index"`
+	Area          *Area      `json:"area,omitempty" gorm:"foreignKey:AreaId;references:Id"`
+	Name          string     `json:"name" gorm:"type:varchar(50);not null;unique"`
+	Seats         uint       `json:"seats" gorm:"not null;default:2"`
+	Status        string     `json:"status" gorm:"type:varchar(20);not null;default:free"`
+	IsActive      bool       `json:"is_active" gorm:"not null;default:true"`
+	OccupiedSince *time.Time `json:"occupied_since"`
+	QrVersion     uint       `json:"qr_version" gorm:"not null;default:1"`
+}
+
+const (
+	TableStatusFree          = "free"
+	TableStatusOccupied      = "occupied"
+	TableStatusNeedsCleaning = "needs_cleaning"
+)
@@ -6,14 +6,24 @@ type Transaction struct {
 	GormModel
 	OrderNumber        string              `json:"order_number" gorm:"not null;unique"`
 	SubTotal           uint                `json:"sub_total" gorm:"not null"`
+	DiscountAmount     uint                `json:"discount_amount" gorm:"not null;default:0"`
+	PointsRedeemed     uint                `json:"points_redeemed" gorm:"not null;default:0"`
+	PointsDiscount     uint                `json:"points_discount" gorm:"not null;default:0"`
+	PointsEarned       uint                `json:"points_earned" gorm:"not null;default:0"`
 	TotalAmount        uint                `json:"total_amount" gorm:"not null"`
 	PaymentStatus      string              `json:"payment_status" gorm:"not null;default:pending"`
 	PaymentMethod      string              `json:"payment_method" gorm:"default:midtrans"`
 	MidtransToken      string              `json:"midtrans_token,omitempty" gorm:"column:midtrans_token"`
 	MidtransOrderID    string              `json:"midtrans_order_id,omitempty" gorm:"column:midtrans_order_id"`
+	OrderType          string              `json:"order_type" gorm:"type:varchar(20);not null;default:takeaway"`
+	TableId            *uint               `json:"table_id" gorm:"index"`
+	SessionId          *uint               `json:"session_id" gorm:"index"`
+	CustomerId         *uint               `json:"customer_id" gorm:"index"`
 	BuyerName          string              `json:"buyer_name" gorm:"not null"`
 	Phone              string              `json:"phone" gorm:"not null"`
+	PaymentStartedAt   *time.Time          `json:"payment_started_at"`
 	PaidAt             *time.Time          `json:"paid_at"`
+	RefundedAt         *time.Time          `json:"refunded_at"`
 	ExpiredAt          *time.Time          `json:"expired_at"`
 	TransactionDetails []TransactionDetail `json:"transaction_details" gorm:"foreignKey:TransactionId;references:Id"`
 }
@@ -24,4 +34,11 @@ const (
 	PaymentStatusFailed    = "failed"
 	PaymentStatusExpired   = "expired"
 	PaymentStatusCancelled = "cancelled"
+	PaymentStatusRefunded  = "refunded"
+)
+
+const (
+	OrderTypeDineIn   = "dine_in"
+	OrderTypeTakeaway = "takeaway"
+	OrderTypeDelivery = "delivery"
 )
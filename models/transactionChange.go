@@ -0,0 +1,23 @@
+package models
+
+// TransactionChange is the audit of an open tab: every line added or voided after checkout and the moment
+// payment started, with the totals before and after
+type TransactionChange struct {
+	GormModel
+	TransactionId       uint   `json:"transaction_id" gorm:"not null;index"`
+	TransactionDetailId *uint  `json:"transaction_detail_id" gorm:"index"`
+	Action              string `json:"action" gorm:"type:varchar(20);not null"`
+	ProductName         string `json:"product_name"`
+	Quantity            uint   `json:"quantity" gorm:"not null;default:0"`
+	Amount              uint   `json:"amount" gorm:"not null;default:0"`
+	Reason              string `json:"reason"`
+	TotalBefore         uint   `json:"total_before" gorm:"not null"`
+	TotalAfter          uint   `json:"total_after" gorm:"not null"`
+	ChangedBy           string `json:"changed_by" gorm:"not null"`
+}
+
+const (
+	TransactionChangeAddItem      = "add_item"
+	TransactionChangeVoidItem     = "void_item"
+	TransactionChangeStartPayment = "start_payment"
+)
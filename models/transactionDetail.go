@@ -1,14 +1,29 @@
 package models
 
+import "time"
+
 type TransactionDetail struct {
 	GormModel
-	TransactionId uint        `json:"transaction_id" gorm:"not null"`
-	ProductId     uint        `json:"product_id" gorm:"not null"`
-	ProductName   string      `json:"product_name" gorm:"not null"`
-	Quantity      uint        `json:"quantity" gorm:"not null"`
-	Price         uint        `json:"price" gorm:"not null"`
-	TotalPrice    uint        `json:"total_price" gorm:"not null"`
-	Notes         string      `json:"notes"`
-	Transaction   Transaction `json:"transaction" gorm:"foreignKey:TransactionId;references:Id"`
-	Product       Product     `json:"product" gorm:"foreignKey:ProductId;references:Id"`
+	TransactionId  uint                        `json:"transaction_id" gorm:"not null"`
+	ParentDetailId *uint                       `json:"parent_detail_id" gorm:"index"`
+	ProductId      uint                        `json:"product_id" gorm:"not null"`
+	ProductName    string                      `json:"product_name" gorm:"not null"`
+	VariantId      *uint                       `json:"variant_id"`
+	VariantName    string                      `json:"variant_name"`
+	Quantity       uint                        `json:"quantity" gorm:"not null"`
+	Price          uint                        `json:"price" gorm:"not null"`
+	PriceId        *uint                       `json:"price_id" gorm:"index"`
+	ModifiersPrice int                         `json:"modifiers_price" gorm:"not null;default:0"`
+	DiscountAmount uint                        `json:"discount_amount" gorm:"not null;default:0"`
+	TotalPrice     uint                        `json:"total_price" gorm:"not null"`
+	Notes          string                      `json:"notes"`
+	StockSource    string                      `json:"stock_source" gorm:"type:varchar(10)"`
+	VoidedAt       *time.Time                  `json:"voided_at"`
+	VoidedBy       string                      `json:"voided_by"`
+	VoidReason     string                      `json:"void_reason"`
+	Modifiers      []TransactionDetailModifier `json:"modifiers" gorm:"foreignKey:TransactionDetailId;references:Id;constraint:OnDelete:CASCADE"`
+	Discounts      []TransactionDetailDiscount `json:"discounts" gorm:"foreignKey:TransactionDetailId;references:Id;constraint:OnDelete:CASCADE"`
+	Components     []TransactionDetail         `json:"components,omitempty" gorm:"foreignKey:ParentDetailId;references:Id"`
+	Transaction    Transaction                 `json:"transaction" gorm:"foreignKey:TransactionId;references:Id"`
+	Product        Product                     `json:"product" gorm:"foreignKey:ProductId;references:Id"`
 }
@@ -0,0 +1,10 @@
+package models
+
+type TransactionDetailModifier struct {
+	GormModel
+	TransactionDetailId uint   `json:"transaction_detail_id" gorm:"not null;index"`
+	ModifierId          uint   `json:"modifier_id" gorm:"not null"`
+	GroupName           string `json:"group_name" gorm:"not null"`
+	Name                string `json:"name" gorm:"not null"`
+	PriceDelta          int    `json:"price_delta" gorm:"not null"`
+}
@@ -0,0 +1,24 @@
+package models
+
+type Unit struct {
+	GormModel
+	Symbol    string  `json:"symbol" gorm:"type:varchar(20);not null;unique"`
+	Name      string  `json:"name" gorm:"not null"`
+	Dimension string  `json:"dimension" gorm:"type:varchar(20);not null"`
+	Factor    float64 `json:"factor" gorm:"not null;default:1"`
+}
+
+// Units of the same dimension convert through Factor, the size of the unit in the dimension's base unit
+const (
+	UnitDimensionMass   = "mass"
+	UnitDimensionVolume = "volume"
+	UnitDimensionCount  = "count"
+)
+
+// Convert returns quantity, given in this unit, expressed in the target unit
+func (u Unit) Convert(quantity float64, target Unit) float64 {
+	if u.Id == target.Id || target.Factor == 0 {
+		return quantity
+	}
+	return quantity * u.Factor / target.Factor
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// ZReport is the daily report of a closed business day, kept exactly as it was when the day was closed.
+// Rows are never updated or deleted, a trigger refuses it.
+type ZReport struct {
+	GormModel
+	BusinessDate time.Time `json:"business_date" gorm:"type:date;not null;unique"`
+	ClosedAt     time.Time `json:"closed_at" gorm:"not null"`
+	ClosedBy     string    `json:"closed_by" gorm:"not null"`
+	Orders       int64     `json:"orders" gorm:"not null"`
+	NetSales     int64     `json:"net_sales" gorm:"not null"`
+	Report       string    `json:"report" gorm:"type:jsonb;not null"`
+}
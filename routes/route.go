@@ -7,6 +7,7 @@ import (
 	"deck/services"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"time"
 )
 
 func SetupRoutes() *gin.Engine {
@@ -24,16 +25,76 @@ func SetupRoutes() *gin.Engine {
 	}))
 
 	// Initialize services
-	transactionService := services.NewTransactionService(database.DB)
 	notificationService := services.NewNotificationService(database.DB)
-	productService := services.NewProductService(database.DB)
+	inventoryService := services.NewInventoryService(database.DB, notificationService)
+	ingredientService := services.NewIngredientService(database.DB, notificationService)
+	transactionService := services.NewTransactionService(database.DB, inventoryService, ingredientService)
+	purchaseOrderService := services.NewPurchaseOrderService(database.DB)
+	stockCountService := services.NewStockCountService(database.DB)
 	categoryService := services.NewCategoryService(database.DB)
+	productService := services.NewProductService(database.DB, categoryService)
+	availabilityService := services.NewAvailabilityService(database.DB)
+	productPriceService := services.NewProductPriceService(database.DB)
+	discountService := services.NewDiscountService(database.DB)
+	customerService := services.NewCustomerService(database.DB)
+	loyaltyService := services.NewLoyaltyService(database.DB)
+	tableService := services.NewTableService(database.DB)
+	orderingSessionService := services.NewOrderingSessionService(database.DB)
+	tabService := services.NewTabService(database.DB, inventoryService)
+	paymentService := services.NewPaymentService(database.DB, transactionService, notificationService)
+	receiptService := services.NewReceiptService(database.DB)
+	printerService := services.NewPrinterService(database.DB)
+	printJobService := services.NewPrintJobService(database.DB, notificationService)
+	shiftService := services.NewShiftService(database.DB, notificationService)
+	reportService := services.NewReportService(database.DB)
+	exportService := services.NewExportService(database.DB, notificationService, reportService, discountService, productPriceService, stockCountService)
+	productImportService := services.NewProductImportService(database.DB, productService, productPriceService)
+	productVariantService := services.NewProductVariantService(database.DB)
+	modifierService := services.NewModifierService(database.DB)
+	bundleService := services.NewBundleService(database.DB)
 
 	// Initialize controllers
 	transactionController := controllers.NewTransactionController(database.DB, transactionService, notificationService)
 	notificationController := controllers.NewNotificationController(notificationService)
-	productController := controllers.NewProductController(productService)
+	productController := controllers.NewProductController(productService, categoryService, availabilityService, productPriceService)
 	categoryController := controllers.NewCategoryController(categoryService)
+	productVariantController := controllers.NewProductVariantController(productVariantService)
+	modifierController := controllers.NewModifierController(modifierService)
+	bundleController := controllers.NewBundleController(bundleService)
+	inventoryController := controllers.NewInventoryController(inventoryService)
+	ingredientController := controllers.NewIngredientController(ingredientService)
+	purchaseOrderController := controllers.NewPurchaseOrderController(purchaseOrderService)
+	stockCountController := controllers.NewStockCountController(stockCountService)
+	availabilityController := controllers.NewAvailabilityController(availabilityService)
+	productPriceController := controllers.NewProductPriceController(productPriceService)
+	discountController := controllers.NewDiscountController(discountService)
+	customerController := controllers.NewCustomerController(customerService)
+	loyaltyController := controllers.NewLoyaltyController(loyaltyService)
+	tableController := controllers.NewTableController(tableService)
+	orderingSessionController := controllers.NewOrderingSessionController(orderingSessionService)
+	tabController := controllers.NewTabController(tabService)
+	paymentController := controllers.NewPaymentController(paymentService)
+	receiptController := controllers.NewReceiptController(receiptService)
+	printerController := controllers.NewPrinterController(printerService, printJobService)
+	shiftController := controllers.NewShiftController(shiftService)
+	reportController := controllers.NewReportController(reportService)
+	exportController := controllers.NewExportController(exportService)
+	productImportController := controllers.NewProductImportController(productImportService)
+
+	// Expire unpaid transactions and release their stock
+	go transactionService.StartExpiryWorker(time.Minute)
+
+	// Apply scheduled prices once they take effect
+	go productPriceService.StartPriceWorker(time.Minute)
+
+	// Expire old loyalty points every night
+	go loyaltyService.StartPointsExpiryWorker()
+
+	// Send queued kitchen tickets to the station printers
+	go printJobService.StartPrintWorker(2 * time.Second)
+
+	// Write the exports too large to stream in the request
+	go exportService.StartExportWorker(5 * time.Second)
 
 	apiRouter := router.Group("/api/")
 
@@ -48,20 +109,193 @@ func SetupRoutes() *gin.Engine {
 
 	// router product
 	apiRouter.GET("products", productController.GetProducts)
+	apiRouter.GET("products/search", productController.SearchProducts)
+	apiRouter.GET("products/lookup", productController.LookupProduct)
 	apiRouter.POST("products", middlewares.AuthMiddleware(), productController.CreateProduct)
+	apiRouter.GET("products/export", middlewares.AuthMiddleware(), productImportController.ExportProducts)
+	apiRouter.POST("products/import", middlewares.AuthMiddleware(), productImportController.ImportProducts)
 	apiRouter.GET("products/:id", middlewares.AuthMiddleware(), productController.GetProductById)
-	apiRouter.PUT("products/:id", middlewares.AuthMiddleware(), controllers.UpdateProduct)
+	apiRouter.PUT("products/:id", middlewares.AuthMiddleware(), productController.UpdateProduct)
 	apiRouter.DELETE("products/:id", middlewares.AuthMiddleware(), productController.DeleteProduct)
 
+	// route product variant
+	apiRouter.GET("products/:id/variants", productVariantController.GetVariants)
+	apiRouter.POST("products/:id/option-groups", middlewares.AuthMiddleware(), productVariantController.CreateOptionGroup)
+	apiRouter.DELETE("products/:id/option-groups/:groupId", middlewares.AuthMiddleware(), productVariantController.DeleteOptionGroup)
+	apiRouter.POST("products/:id/variants", middlewares.AuthMiddleware(), productVariantController.CreateVariant)
+	apiRouter.PUT("products/:id/variants/:variantId", middlewares.AuthMiddleware(), productVariantController.UpdateVariant)
+	apiRouter.DELETE("products/:id/variants/:variantId", middlewares.AuthMiddleware(), productVariantController.DeleteVariant)
+
+	// route product bundle
+	apiRouter.GET("products/:id/bundle", bundleController.GetBundle)
+	apiRouter.PUT("products/:id/bundle", middlewares.AuthMiddleware(), bundleController.SetBundle)
+	apiRouter.DELETE("products/:id/bundle", middlewares.AuthMiddleware(), bundleController.RemoveBundle)
+
+	// route modifier
+	apiRouter.GET("modifier-groups", modifierController.GetModifierGroups)
+	apiRouter.GET("modifier-groups/:id", modifierController.GetModifierGroupById)
+	apiRouter.POST("modifier-groups", middlewares.AuthMiddleware(), modifierController.CreateModifierGroup)
+	apiRouter.PUT("modifier-groups/:id", middlewares.AuthMiddleware(), modifierController.UpdateModifierGroup)
+	apiRouter.DELETE("modifier-groups/:id", middlewares.AuthMiddleware(), modifierController.DeleteModifierGroup)
+	apiRouter.PUT("products/:id/modifier-groups", middlewares.AuthMiddleware(), modifierController.SetProductModifierGroups)
+
+	// route inventory
+	apiRouter.GET("inventory", middlewares.AuthMiddleware(), inventoryController.GetInventory)
+	apiRouter.GET("inventory/movements", middlewares.AuthMiddleware(), inventoryController.GetMovements)
+	apiRouter.POST("inventory/movements", middlewares.AuthMiddleware(), inventoryController.CreateMovement)
+	apiRouter.PUT("products/:id/stock", middlewares.AuthMiddleware(), inventoryController.UpdateStockSettings)
+
+	// route ingredient
+	apiRouter.GET("units", middlewares.AuthMiddleware(), ingredientController.GetUnits)
+	apiRouter.POST("units", middlewares.AuthMiddleware(), ingredientController.CreateUnit)
+	apiRouter.GET("ingredients", middlewares.AuthMiddleware(), ingredientController.GetIngredients)
+	apiRouter.GET("ingredients/:id", middlewares.AuthMiddleware(), ingredientController.GetIngredientById)
+	apiRouter.POST("ingredients", middlewares.AuthMiddleware(), ingredientController.CreateIngredient)
+	apiRouter.PUT("ingredients/:id", middlewares.AuthMiddleware(), ingredientController.UpdateIngredient)
+	apiRouter.DELETE("ingredients/:id", middlewares.AuthMiddleware(), ingredientController.DeleteIngredient)
+	apiRouter.GET("ingredients/:id/movements", middlewares.AuthMiddleware(), ingredientController.GetMovements)
+	apiRouter.POST("ingredients/:id/movements", middlewares.AuthMiddleware(), ingredientController.CreateMovement)
+	apiRouter.GET("products/:id/recipe", middlewares.AuthMiddleware(), ingredientController.GetRecipe)
+	apiRouter.PUT("products/:id/recipe", middlewares.AuthMiddleware(), ingredientController.SetRecipe)
+
+	// route supplier and purchase order
+	apiRouter.GET("suppliers", middlewares.AuthMiddleware(), purchaseOrderController.GetSuppliers)
+	apiRouter.POST("suppliers", middlewares.AuthMiddleware(), purchaseOrderController.CreateSupplier)
+	apiRouter.PUT("suppliers/:id", middlewares.AuthMiddleware(), purchaseOrderController.UpdateSupplier)
+	apiRouter.DELETE("suppliers/:id", middlewares.AuthMiddleware(), purchaseOrderController.DeleteSupplier)
+	apiRouter.GET("purchase-orders", middlewares.AuthMiddleware(), purchaseOrderController.GetPurchaseOrders)
+	apiRouter.GET("purchase-orders/:id", middlewares.AuthMiddleware(), purchaseOrderController.GetPurchaseOrderById)
+	apiRouter.POST("purchase-orders", middlewares.AuthMiddleware(), purchaseOrderController.CreatePurchaseOrder)
+	apiRouter.POST("purchase-orders/:id/receive", middlewares.AuthMiddleware(), purchaseOrderController.ReceivePurchaseOrder)
+	apiRouter.POST("purchase-orders/:id/cancel", middlewares.AuthMiddleware(), purchaseOrderController.CancelPurchaseOrder)
+
+	// route stock count
+	apiRouter.GET("stock-counts", middlewares.AuthMiddleware(), stockCountController.GetStockCounts)
+	apiRouter.GET("stock-counts/:id", middlewares.AuthMiddleware(), stockCountController.GetStockCountById)
+	apiRouter.POST("stock-counts", middlewares.AuthMiddleware(), stockCountController.CreateStockCount)
+	apiRouter.GET("reports/ingredient-variance", middlewares.AuthMiddleware(), exportController.Export(services.ExportIngredientVariance), stockCountController.GetVarianceReport)
+
+	// route availability schedule
+	apiRouter.GET("availability-schedules", middlewares.AuthMiddleware(), availabilityController.GetSchedules)
+	apiRouter.POST("availability-schedules", middlewares.AuthMiddleware(), availabilityController.CreateSchedule)
+	apiRouter.PUT("availability-schedules/:id", middlewares.AuthMiddleware(), availabilityController.UpdateSchedule)
+	apiRouter.DELETE("availability-schedules/:id", middlewares.AuthMiddleware(), availabilityController.DeleteSchedule)
+	apiRouter.GET("availability-blackouts", middlewares.AuthMiddleware(), availabilityController.GetBlackouts)
+	apiRouter.POST("availability-blackouts", middlewares.AuthMiddleware(), availabilityController.CreateBlackout)
+	apiRouter.DELETE("availability-blackouts/:id", middlewares.AuthMiddleware(), availabilityController.DeleteBlackout)
+
+	// route price history
+	apiRouter.GET("products/:id/prices", middlewares.AuthMiddleware(), productPriceController.GetPriceHistory)
+	apiRouter.POST("products/:id/prices", middlewares.AuthMiddleware(), productPriceController.SchedulePrice)
+	apiRouter.DELETE("products/:id/prices/:priceId", middlewares.AuthMiddleware(), productPriceController.DeleteScheduledPrice)
+	apiRouter.GET("reports/price-periods", middlewares.AuthMiddleware(), exportController.Export(services.ExportPricePeriodReport), productPriceController.GetPricePeriodReport)
+
+	// route automatic discount
+	apiRouter.GET("discounts", middlewares.AuthMiddleware(), discountController.GetDiscounts)
+	apiRouter.GET("discounts/:id", middlewares.AuthMiddleware(), discountController.GetDiscountById)
+	apiRouter.POST("discounts", middlewares.AuthMiddleware(), discountController.CreateDiscount)
+	apiRouter.PUT("discounts/:id", middlewares.AuthMiddleware(), discountController.UpdateDiscount)
+	apiRouter.DELETE("discounts/:id", middlewares.AuthMiddleware(), discountController.DeleteDiscount)
+	apiRouter.GET("reports/discounts", middlewares.AuthMiddleware(), exportController.Export(services.ExportDiscountReport), discountController.GetDiscountReport)
+
+	// route customer
+	apiRouter.GET("customers", middlewares.AuthMiddleware(), customerController.GetCustomers)
+	apiRouter.GET("customers/:id", middlewares.AuthMiddleware(), customerController.GetCustomerById)
+	apiRouter.PUT("customers/:id", middlewares.AuthMiddleware(), customerController.UpdateCustomer)
+	apiRouter.GET("customers/:id/transactions", middlewares.AuthMiddleware(), customerController.GetCustomerTransactions)
+
+	// route loyalty
+	apiRouter.GET("loyalty/settings", middlewares.AuthMiddleware(), loyaltyController.GetSetting)
+	apiRouter.PUT("loyalty/settings", middlewares.AuthMiddleware(), loyaltyController.UpdateSetting)
+	apiRouter.GET("customers/:id/points", middlewares.AuthMiddleware(), loyaltyController.GetLedger)
+	apiRouter.POST("customers/:id/points", middlewares.AuthMiddleware(), loyaltyController.AdjustPoints)
+
 	// route category
 	apiRouter.GET("categories", categoryController.GetCategories)
 	apiRouter.GET("categories/:value", categoryController.GetCategoryByValue)
+	apiRouter.POST("categories", middlewares.AuthMiddleware(), categoryController.CreateCategory)
+	apiRouter.PUT("categories/:id", middlewares.AuthMiddleware(), categoryController.UpdateCategory)
+	apiRouter.DELETE("categories/:id", middlewares.AuthMiddleware(), categoryController.DeleteCategory)
+
+	// route table
+	apiRouter.GET("areas", middlewares.AuthMiddleware(), tableController.GetAreas)
+	apiRouter.POST("areas", middlewares.AuthMiddleware(), tableController.CreateArea)
+	apiRouter.PUT("areas/:id", middlewares.AuthMiddleware(), tableController.UpdateArea)
+	apiRouter.DELETE("areas/:id", middlewares.AuthMiddleware(), tableController.DeleteArea)
+	apiRouter.GET("tables", middlewares.AuthMiddleware(), tableController.GetTables)
+	apiRouter.GET("tables/tabs", middlewares.AuthMiddleware(), tableController.GetOpenTabs)
+	apiRouter.POST("tables", middlewares.AuthMiddleware(), tableController.CreateTable)
+	apiRouter.GET("tables/:id", middlewares.AuthMiddleware(), tableController.GetTableById)
+	apiRouter.PUT("tables/:id", middlewares.AuthMiddleware(), tableController.UpdateTable)
+	apiRouter.DELETE("tables/:id", middlewares.AuthMiddleware(), tableController.DeleteTable)
+	apiRouter.PUT("tables/:id/status", middlewares.AuthMiddleware(), tableController.UpdateTableStatus)
+	apiRouter.GET("tables/:id/tabs", middlewares.AuthMiddleware(), tableController.GetOpenTabs)
+	apiRouter.GET("tables/:id/qr", middlewares.AuthMiddleware(), orderingSessionController.GetTableQr)
+	apiRouter.GET("tables/:id/qr.png", middlewares.AuthMiddleware(), orderingSessionController.GetTableQrImage)
+	apiRouter.POST("tables/:id/qr/rotate", middlewares.AuthMiddleware(), orderingSessionController.RotateTableQr)
+
+	// route guest ordering session
+	apiRouter.POST("ordering-sessions", orderingSessionController.OpenSession)
+	apiRouter.GET("ordering-sessions/:token", orderingSessionController.GetSession)
+	apiRouter.POST("ordering-sessions/:token/orders", transactionController.CreateSessionOrder)
 
 	// route transaction
 	apiRouter.POST("transactions", transactionController.CreateTransaction)
 	//apiRouter.GET("transactions/:order_number", transactionController.GetTransaction)
-	apiRouter.GET("transactions", middlewares.AuthMiddleware(), transactionController.GetAllTransactions)
+	apiRouter.GET("transactions", middlewares.AuthMiddleware(), exportController.Export(services.ExportTransactions), transactionController.GetAllTransactions)
 	apiRouter.GET("transactions/:id", transactionController.GetTransactionByID)
+	apiRouter.PUT("transactions/:id/status", middlewares.AuthMiddleware(), transactionController.UpdateTransactionStatus)
+
+	// route open tab
+	apiRouter.POST("transactions/:id/items", middlewares.AuthMiddleware(), tabController.AddItems)
+	apiRouter.POST("transactions/:id/items/:detailId/void", middlewares.AuthMiddleware(), tabController.VoidItem)
+	apiRouter.POST("transactions/:id/start-payment", middlewares.AuthMiddleware(), tabController.StartPayment)
+	apiRouter.GET("transactions/:id/changes", middlewares.AuthMiddleware(), tabController.GetChanges)
+
+	// route payment
+	apiRouter.GET("transactions/:id/payments", middlewares.AuthMiddleware(), paymentController.GetPayments)
+	apiRouter.POST("transactions/:id/payments", middlewares.AuthMiddleware(), paymentController.CreatePayment)
+	apiRouter.POST("transactions/:id/payments/:paymentId/cancel", middlewares.AuthMiddleware(), paymentController.CancelPayment)
+	apiRouter.POST("payments/midtrans/notification", paymentController.MidtransNotification)
+
+	// route receipt
+	apiRouter.GET("transactions/:id/receipt", middlewares.AuthMiddleware(), receiptController.GetReceipt)
+
+	// route printer
+	apiRouter.GET("printers", middlewares.AuthMiddleware(), printerController.GetPrinters)
+	apiRouter.POST("printers", middlewares.AuthMiddleware(), printerController.CreatePrinter)
+	apiRouter.PUT("printers/:id", middlewares.AuthMiddleware(), printerController.UpdatePrinter)
+	apiRouter.DELETE("printers/:id", middlewares.AuthMiddleware(), printerController.DeletePrinter)
+	apiRouter.GET("stations", middlewares.AuthMiddleware(), printerController.GetStations)
+	apiRouter.POST("stations", middlewares.AuthMiddleware(), printerController.CreateStation)
+	apiRouter.GET("stations/:id", middlewares.AuthMiddleware(), printerController.GetStationById)
+	apiRouter.PUT("stations/:id", middlewares.AuthMiddleware(), printerController.UpdateStation)
+	apiRouter.DELETE("stations/:id", middlewares.AuthMiddleware(), printerController.DeleteStation)
+	apiRouter.GET("print-jobs", middlewares.AuthMiddleware(), printerController.GetPrintJobs)
+	apiRouter.GET("print-jobs/:id", middlewares.AuthMiddleware(), printerController.GetPrintJobById)
+	apiRouter.POST("print-jobs/:id/retry", middlewares.AuthMiddleware(), printerController.RetryPrintJob)
+	apiRouter.POST("transactions/:id/kitchen-tickets/reprint", middlewares.AuthMiddleware(), printerController.ReprintKitchenTickets)
+
+	// route shift
+	apiRouter.GET("shifts", middlewares.AuthMiddleware(), shiftController.GetShifts)
+	apiRouter.GET("shifts/current", middlewares.AuthMiddleware(), shiftController.GetCurrentShift)
+	apiRouter.GET("shifts/:id", middlewares.AuthMiddleware(), shiftController.GetShiftById)
+	apiRouter.POST("shifts", middlewares.AuthMiddleware(), shiftController.OpenShift)
+	apiRouter.POST("shifts/:id/cash-entries", middlewares.AuthMiddleware(), shiftController.AddCashEntry)
+	apiRouter.POST("shifts/:id/close", middlewares.AuthMiddleware(), shiftController.CloseShift)
+
+	// route report
+	apiRouter.GET("reports/daily", middlewares.AuthMiddleware(), exportController.Export(services.ExportDailyReport), reportController.GetDailyReport)
+	apiRouter.POST("reports/daily/close", middlewares.AuthMiddleware(), reportController.CloseDay)
+	apiRouter.GET("reports/z-reports", middlewares.AuthMiddleware(), exportController.Export(services.ExportZReports), reportController.GetZReports)
+	apiRouter.GET("reports/z-reports/:id", middlewares.AuthMiddleware(), exportController.Export(services.ExportZReport), reportController.GetZReportById)
+	apiRouter.GET("reports/products", middlewares.AuthMiddleware(), exportController.Export(services.ExportProductPerformance), reportController.GetProductPerformance)
+	apiRouter.GET("reports/heatmap", middlewares.AuthMiddleware(), exportController.Export(services.ExportSalesHeatmap), reportController.GetSalesHeatmap)
+
+	// route export
+	apiRouter.GET("exports", middlewares.AuthMiddleware(), exportController.GetExportJobs)
+	apiRouter.GET("exports/:id", middlewares.AuthMiddleware(), exportController.GetExportJobById)
+	apiRouter.GET("exports/:id/download", middlewares.AuthMiddleware(), exportController.DownloadExport)
 
 	// route notification
 	apiRouter.GET("notifications", middlewares.AuthMiddleware(), notificationController.GetNotifications)
@@ -0,0 +1,355 @@
+package services
+
+import (
+	"deck/config"
+	"deck/models"
+	"deck/structs"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var scheduleTimePattern = regexp.MustCompile(`^([01][0-9]|2[0-3]):[0-5][0-9]$`)
+
+var weekdayNames = []string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"}
+
+type AvailabilityService struct {
+	db *gorm.DB
+}
+
+func NewAvailabilityService(db *gorm.DB) *AvailabilityService {
+	return &AvailabilityService{db: db}
+}
+
+// ProductAvailability is the computed availability of a product at a moment
+type ProductAvailability struct {
+	Available bool
+	Reason    string
+}
+
+// menuAvailability holds every schedule and blackout so a whole menu can be evaluated with two queries
+type menuAvailability struct {
+	productSchedules  map[uint][]models.AvailabilitySchedule
+	categorySchedules map[uint][]models.AvailabilitySchedule
+	productBlackouts  map[uint][]models.AvailabilityBlackout
+	categoryBlackouts map[uint][]models.AvailabilityBlackout
+}
+
+// Get schedules, optionally only those of a product or category
+func (as *AvailabilityService) GetSchedules(productId uint, categoryId uint) ([]models.AvailabilitySchedule, error) {
+	query := as.db.Order("id ASC")
+	if productId != 0 {
+		query = query.Where("product_id = ?", productId)
+	}
+	if categoryId != 0 {
+		query = query.Where("category_id = ?", categoryId)
+	}
+
+	var schedules []models.AvailabilitySchedule
+	err := query.Find(&schedules).Error
+
+	return schedules, err
+}
+
+// Create Schedule
+func (as *AvailabilityService) CreateSchedule(req *structs.AvailabilityScheduleRequest) (*models.AvailabilitySchedule, error) {
+	schedule := models.AvailabilitySchedule{}
+	if err := as.fillSchedule(&schedule, req); err != nil {
+		return nil, err
+	}
+
+	if err := as.db.Create(&schedule).Error; err != nil {
+		return nil, fmt.Errorf("failed to create schedule: %v", err)
+	}
+
+	return &schedule, nil
+}
+
+// Update Schedule
+func (as *AvailabilityService) UpdateSchedule(id uint, req *structs.AvailabilityScheduleRequest) (*models.AvailabilitySchedule, error) {
+	var schedule models.AvailabilitySchedule
+	if err := as.db.First(&schedule, id).Error; err != nil {
+		return nil, notFoundError("schedule not found")
+	}
+
+	if err := as.fillSchedule(&schedule, req); err != nil {
+		return nil, err
+	}
+
+	if err := as.db.Save(&schedule).Error; err != nil {
+		return nil, fmt.Errorf("failed to update schedule: %v", err)
+	}
+
+	return &schedule, nil
+}
+
+// Delete Schedule
+func (as *AvailabilityService) DeleteSchedule(id uint) error {
+	result := as.db.Delete(&models.AvailabilitySchedule{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete schedule: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return notFoundError("schedule not found")
+	}
+
+	return nil
+}
+
+// Get blackouts that have not ended yet, or every blackout when includePast is set
+func (as *AvailabilityService) GetBlackouts(includePast bool) ([]models.AvailabilityBlackout, error) {
+	query := as.db.Order("start_date ASC, id ASC")
+	if !includePast {
+		query = query.Where("end_date >= ?", time.Now().In(config.Location()).Format("2006-01-02"))
+	}
+
+	var blackouts []models.AvailabilityBlackout
+	err := query.Find(&blackouts).Error
+
+	return blackouts, err
+}
+
+// Create Blackout
+func (as *AvailabilityService) CreateBlackout(req *structs.AvailabilityBlackoutRequest) (*models.AvailabilityBlackout, error) {
+	if err := as.validateTarget(req.ProductId, req.CategoryId); err != nil {
+		return nil, err
+	}
+
+	startDate, err := time.Parse("2006-01-02", req.StartDate)
+	if err != nil {
+		return nil, invalidError("invalid start_date, use YYYY-MM-DD")
+	}
+	endDate, err := time.Parse("2006-01-02", req.EndDate)
+	if err != nil {
+		return nil, invalidError("invalid end_date, use YYYY-MM-DD")
+	}
+	if endDate.Before(startDate) {
+		return nil, invalidError("invalid date range, end_date is before start_date")
+	}
+
+	blackout := models.AvailabilityBlackout{
+		ProductId:  req.ProductId,
+		CategoryId: req.CategoryId,
+		StartDate:  startDate,
+		EndDate:    endDate,
+		Reason:     strings.TrimSpace(req.Reason),
+	}
+
+	if err := as.db.Create(&blackout).Error; err != nil {
+		return nil, fmt.Errorf("failed to create blackout: %v", err)
+	}
+
+	return &blackout, nil
+}
+
+// Delete Blackout
+func (as *AvailabilityService) DeleteBlackout(id uint) error {
+	result := as.db.Delete(&models.AvailabilityBlackout{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete blackout: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return notFoundError("blackout not found")
+	}
+
+	return nil
+}
+
+// Evaluate computes the availability of products right now in the outlet timezone
+func (as *AvailabilityService) Evaluate(products []models.Product) (map[uint]ProductAvailability, error) {
+	availability, err := loadMenuAvailability(as.db)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().In(config.Location())
+	result := make(map[uint]ProductAvailability, len(products))
+	for _, product := range products {
+		result[product.Id] = availability.check(&product, now)
+	}
+
+	return result, nil
+}
+
+func (as *AvailabilityService) fillSchedule(schedule *models.AvailabilitySchedule, req *structs.AvailabilityScheduleRequest) error {
+	if err := as.validateTarget(req.ProductId, req.CategoryId); err != nil {
+		return err
+	}
+
+	if !scheduleTimePattern.MatchString(req.StartTime) || !scheduleTimePattern.MatchString(req.EndTime) {
+		return invalidError("invalid time, use HH:MM between 00:00 and 23:59")
+	}
+
+	days := 0
+	for _, day := range req.Days {
+		days |= 1 << uint(day)
+	}
+
+	schedule.ProductId = req.ProductId
+	schedule.CategoryId = req.CategoryId
+	schedule.Label = strings.TrimSpace(req.Label)
+	schedule.Days = days
+	schedule.StartTime = req.StartTime
+	schedule.EndTime = req.EndTime
+
+	return nil
+}
+
+// validateTarget checks that exactly one of product and category is given and exists
+func (as *AvailabilityService) validateTarget(productId *uint, categoryId *uint) error {
+	if (productId == nil) == (categoryId == nil) {
+		return invalidError("invalid target, set either product_id or category_id")
+	}
+
+	if productId != nil {
+		if err := as.db.First(&models.Product{}, *productId).Error; err != nil {
+			return invalidError("invalid target, product not found")
+		}
+	}
+	if categoryId != nil {
+		if err := as.db.First(&models.Category{}, *categoryId).Error; err != nil {
+			return invalidError("invalid target, category not found")
+		}
+	}
+
+	return nil
+}
+
+func loadMenuAvailability(db *gorm.DB) (*menuAvailability, error) {
+	var schedules []models.AvailabilitySchedule
+	if err := db.Order("start_time ASC, id ASC").Find(&schedules).Error; err != nil {
+		return nil, fmt.Errorf("failed to load schedules: %v", err)
+	}
+
+	var blackouts []models.AvailabilityBlackout
+	today := time.Now().In(config.Location()).Format("2006-01-02")
+	if err := db.Where("end_date >= ?", today).Order("end_date ASC").Find(&blackouts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load blackouts: %v", err)
+	}
+
+	availability := &menuAvailability{
+		productSchedules:  make(map[uint][]models.AvailabilitySchedule),
+		categorySchedules: make(map[uint][]models.AvailabilitySchedule),
+		productBlackouts:  make(map[uint][]models.AvailabilityBlackout),
+		categoryBlackouts: make(map[uint][]models.AvailabilityBlackout),
+	}
+	for _, schedule := range schedules {
+		if schedule.ProductId != nil {
+			availability.productSchedules[*schedule.ProductId] = append(availability.productSchedules[*schedule.ProductId], schedule)
+		} else if schedule.CategoryId != nil {
+			availability.categorySchedules[*schedule.CategoryId] = append(availability.categorySchedules[*schedule.CategoryId], schedule)
+		}
+	}
+	for _, blackout := range blackouts {
+		if blackout.ProductId != nil {
+			availability.productBlackouts[*blackout.ProductId] = append(availability.productBlackouts[*blackout.ProductId], blackout)
+		} else if blackout.CategoryId != nil {
+			availability.categoryBlackouts[*blackout.CategoryId] = append(availability.categoryBlackouts[*blackout.CategoryId], blackout)
+		}
+	}
+
+	return availability, nil
+}
+
+// check evaluates a product at local time now. Blackouts of the product and its category both apply,
+// schedules of the product replace those of its category. Without any schedule a product is always available.
+func (m *menuAvailability) check(product *models.Product, now time.Time) ProductAvailability {
+	if !product.IsAvailable {
+		return ProductAvailability{Available: false, Reason: "not available"}
+	}
+
+	blackouts := append(append([]models.AvailabilityBlackout{}, m.productBlackouts[product.Id]...), m.categoryBlackouts[product.CategoryId]...)
+	for _, blackout := range blackouts {
+		if blackout.Covers(now) {
+			reason := fmt.Sprintf("not available until %s", blackout.EndDate.Format("2006-01-02"))
+			if blackout.Reason != "" {
+				reason += " (" + blackout.Reason + ")"
+			}
+			return ProductAvailability{Available: false, Reason: reason}
+		}
+	}
+
+	schedules := m.productSchedules[product.Id]
+	if len(schedules) == 0 {
+		schedules = m.categorySchedules[product.CategoryId]
+	}
+	if len(schedules) == 0 {
+		return ProductAvailability{Available: true}
+	}
+
+	windows := make([]string, 0, len(schedules))
+	for _, schedule := range schedules {
+		if schedule.Covers(now) {
+			return ProductAvailability{Available: true}
+		}
+		windows = append(windows, describeSchedule(schedule))
+	}
+
+	return ProductAvailability{Available: false, Reason: "only available " + strings.Join(windows, ", ")}
+}
+
+// checkOrderAvailability rejects order lines whose product is outside its availability window
+func checkOrderAvailability(tx *gorm.DB, lines []*models.TransactionDetail) error {
+	availability, err := loadMenuAvailability(tx)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now().In(config.Location())
+	checked := make(map[uint]bool)
+	for _, line := range lines {
+		if checked[line.ProductId] {
+			continue
+		}
+		checked[line.ProductId] = true
+
+		var product models.Product
+		if err := tx.Select("id", "name", "category_id", "is_available").First(&product, line.ProductId).Error; err != nil {
+			return invalidError("product not found: %d", line.ProductId)
+		}
+
+		if result := availability.check(&product, now); !result.Available {
+			return invalidError("product not available at this time: %s is %s", product.Name, result.Reason)
+		}
+	}
+
+	return nil
+}
+
+// describeSchedule renders a window like "Mon-Fri 07:00-11:00" or "Breakfast (Mon-Fri 07:00-11:00)"
+func describeSchedule(schedule models.AvailabilitySchedule) string {
+	hours := "all day"
+	if schedule.StartTime != schedule.EndTime {
+		hours = schedule.StartTime + "-" + schedule.EndTime
+	}
+
+	description := describeDays(schedule.Days) + " " + hours
+	if schedule.Label != "" {
+		return schedule.Label + " (" + description + ")"
+	}
+
+	return description
+}
+
+func describeDays(days int) string {
+	switch days & models.AllDays {
+	case models.AllDays:
+		return "every day"
+	case 0b0111110:
+		return "Mon-Fri"
+	case 0b1000001:
+		return "Sat-Sun"
+	}
+
+	var names []string
+	for day, name := range weekdayNames {
+		if days&(1<<uint(day)) != 0 {
+			names = append(names, name)
+		}
+	}
+
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,218 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+type BundleService struct {
+	db *gorm.DB
+}
+
+func NewBundleService(db *gorm.DB) *BundleService {
+	return &BundleService{db: db}
+}
+
+// Get bundle product with its components and choices
+func (bs *BundleService) GetBundle(productId uint) (*models.Product, error) {
+	var product models.Product
+	err := preloadBundleComponents(bs.db).First(&product, productId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("product not found")
+		}
+		return nil, fmt.Errorf("failed to find product: %v", err)
+	}
+
+	return &product, nil
+}
+
+// Set bundle composition, replacing the previous components and turning the product into a bundle
+func (bs *BundleService) SetBundle(productId uint, req *structs.BundleRequest) (*models.Product, error) {
+	var product models.Product
+	if err := bs.db.First(&product, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	// Bundles do not nest, the stock and the sales of a bundle inside a bundle would not be tracked
+	var usage int64
+	if err := bs.db.Model(&models.BundleComponent{}).Where("product_id = ?", productId).Count(&usage).Error; err != nil {
+		return nil, fmt.Errorf("failed to check bundle usage: %v", err)
+	}
+	if usage == 0 {
+		if err := bs.db.Model(&models.BundleComponentChoice{}).Where("product_id = ?", productId).Count(&usage).Error; err != nil {
+			return nil, fmt.Errorf("failed to check bundle usage: %v", err)
+		}
+	}
+	if usage > 0 {
+		return nil, invalidError("invalid bundle, %s is a component of another bundle", product.Name)
+	}
+
+	var components []models.BundleComponent
+	for i, item := range req.Components {
+		if err := bs.validateComponentProduct(productId, item.ProductId); err != nil {
+			return nil, err
+		}
+
+		component := models.BundleComponent{
+			BundleProductId: productId,
+			Name:            item.Name,
+			ProductId:       item.ProductId,
+			Quantity:        item.Quantity,
+			SortOrder:       item.SortOrder,
+		}
+		if component.SortOrder == 0 {
+			component.SortOrder = i
+		}
+
+		seen := map[uint]bool{item.ProductId: true}
+		for _, choice := range item.Choices {
+			if seen[choice.ProductId] {
+				return nil, invalidError("invalid choices for component %s, duplicate product %d", item.Name, choice.ProductId)
+			}
+			seen[choice.ProductId] = true
+
+			if err := bs.validateComponentProduct(productId, choice.ProductId); err != nil {
+				return nil, err
+			}
+
+			component.Choices = append(component.Choices, models.BundleComponentChoice{
+				ProductId:  choice.ProductId,
+				PriceDelta: choice.PriceDelta,
+			})
+		}
+
+		components = append(components, component)
+	}
+
+	err := bs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_product_id = ?", productId).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Create(&components).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&product).Update("type", models.ProductTypeBundle).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update bundle: %v", err)
+	}
+
+	return bs.GetBundle(productId)
+}
+
+// Remove bundle composition and turn the product back into a single item
+func (bs *BundleService) RemoveBundle(productId uint) error {
+	var product models.Product
+	if err := bs.db.First(&product, productId).Error; err != nil {
+		return notFoundError("product not found")
+	}
+
+	err := bs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("bundle_product_id = ?", productId).Delete(&models.BundleComponent{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&product).Update("type", models.ProductTypeSingle).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to remove bundle: %v", err)
+	}
+
+	return nil
+}
+
+func (bs *BundleService) validateComponentProduct(bundleId uint, productId uint) error {
+	if productId == bundleId {
+		return invalidError("invalid component, a bundle cannot contain itself")
+	}
+
+	var product models.Product
+	if err := bs.db.First(&product, productId).Error; err != nil {
+		return invalidError("invalid component, product %d not found", productId)
+	}
+
+	if product.Type == models.ProductTypeBundle {
+		return invalidError("invalid component, %s is a bundle itself", product.Name)
+	}
+
+	return nil
+}
+
+func preloadBundleComponents(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Components", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Preload("Components.Product").
+		Preload("Components.Choices", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Components.Choices.Product")
+}
+
+// resolveBundleSelection builds the component lines of a bundle order line. Every component must be available,
+// selections may swap a component for one of its choices. It returns the lines and the summed choice price delta per bundle.
+func resolveBundleSelection(tx *gorm.DB, bundle *models.Product, selections []structs.BundleChoiceSelection, quantity uint) ([]models.TransactionDetail, int, error) {
+	if bundle.Type != models.ProductTypeBundle {
+		if len(selections) > 0 {
+			return nil, 0, invalidError("invalid bundle choices, product %s is not a bundle", bundle.Name)
+		}
+		return nil, 0, nil
+	}
+
+	var components []models.BundleComponent
+	if err := tx.Preload("Product").Preload("Choices.Product").
+		Where("bundle_product_id = ?", bundle.Id).Order("sort_order ASC, id ASC").
+		Find(&components).Error; err != nil {
+		return nil, 0, fmt.Errorf("failed to load bundle components: %v", err)
+	}
+
+	swaps := make(map[uint]uint)
+	for _, selection := range selections {
+		swaps[selection.ComponentId] = selection.ProductId
+	}
+
+	var lines []models.TransactionDetail
+	var priceDelta int
+
+	for _, component := range components {
+		product := component.Product
+
+		if swapId, ok := swaps[component.Id]; ok && swapId != component.ProductId {
+			found := false
+			for _, choice := range component.Choices {
+				if choice.ProductId == swapId {
+					product = choice.Product
+					priceDelta += choice.PriceDelta
+					found = true
+					break
+				}
+			}
+			if !found {
+				return nil, 0, invalidError("bundle choice not found for component %s of %s", component.Name, bundle.Name)
+			}
+		}
+		delete(swaps, component.Id)
+
+		if !product.IsAvailable {
+			return nil, 0, invalidError("bundle component not available: %s in %s", product.Name, bundle.Name)
+		}
+
+		lines = append(lines, models.TransactionDetail{
+			ProductId:   product.Id,
+			ProductName: product.Name,
+			Quantity:    component.Quantity * quantity,
+			Price:       0,
+			TotalPrice:  0,
+			Notes:       component.Name,
+		})
+	}
+
+	if len(swaps) > 0 {
+		return nil, 0, invalidError("bundle component not found in %s", bundle.Name)
+	}
+
+	return lines, priceDelta, nil
+}
@@ -1,55 +1,238 @@
 package services
 
 import (
-	"deck/enums"
+	"deck/models"
 	"deck/structs"
 	"errors"
+	"fmt"
 	"gorm.io/gorm"
+	"regexp"
+	"sync"
+	"time"
 )
 
+const categoryCacheTTL = 5 * time.Minute
+
+var categorySlugPattern = regexp.MustCompile(`^[a-z0-9]+(_[a-z0-9]+)*$`)
+
 type CategoryService struct {
 	db *gorm.DB
+
+	mu       sync.RWMutex
+	cache    []models.Category
+	cachedAt time.Time
 }
 
 func NewCategoryService(db *gorm.DB) *CategoryService {
 	return &CategoryService{db: db}
 }
 
+// cachedCategories returns every category ordered for display, reloading from the database when the cache is stale
+func (cs *CategoryService) cachedCategories() ([]models.Category, error) {
+	cs.mu.RLock()
+	if cs.cache != nil && time.Since(cs.cachedAt) < categoryCacheTTL {
+		categories := cs.cache
+		cs.mu.RUnlock()
+		return categories, nil
+	}
+	cs.mu.RUnlock()
+
+	var categories []models.Category
+	if err := cs.db.Order("sort_order ASC, display_name ASC").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	cs.mu.Lock()
+	cs.cache = categories
+	cs.cachedAt = time.Now()
+	cs.mu.Unlock()
+
+	return categories, nil
+}
+
+// invalidateCache drops cached categories after a write
+func (cs *CategoryService) invalidateCache() {
+	cs.mu.Lock()
+	cs.cache = nil
+	cs.mu.Unlock()
+}
+
 // Get All Categories
-func (cs *CategoryService) GetCategories() []structs.CategoryResponse {
-	allCategories := enums.GetAllCategories()
-
-	categories := make([]structs.CategoryResponse, len(allCategories))
-	for i, category := range allCategories {
-		categories[i] = structs.CategoryResponse{
-			Value: string(category),
-			Label: category.GetDisplayName(),
+func (cs *CategoryService) GetCategories(includeInactive bool) ([]structs.CategoryResponse, error) {
+	allCategories, err := cs.cachedCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	categories := make([]structs.CategoryResponse, 0, len(allCategories))
+	for _, category := range allCategories {
+		if !category.IsActive && !includeInactive {
+			continue
 		}
+		categories = append(categories, toCategoryResponse(&category))
 	}
 
-	return categories
+	return categories, nil
 }
 
-// Get Category By Value returns a single category by its value
+// Get Category By Value returns a single category by its slug
 func (cs *CategoryService) GetCategoryByValue(value string) (*structs.CategoryResponse, error) {
-	categoryType := enums.CategoryType(value)
+	category, err := cs.GetCategoryBySlug(value)
+	if err != nil {
+		return nil, err
+	}
+
+	response := toCategoryResponse(category)
+	return &response, nil
+}
+
+// Get Category By Slug from cache
+func (cs *CategoryService) GetCategoryBySlug(slug string) (*models.Category, error) {
+	categories, err := cs.cachedCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range categories {
+		if category.Slug == slug {
+			return &category, nil
+		}
+	}
+
+	return nil, notFoundError("invalid category value")
+}
+
+// Get Category By Id from cache
+func (cs *CategoryService) GetCategoryById(id uint) (*models.Category, error) {
+	categories, err := cs.cachedCategories()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, category := range categories {
+		if category.Id == id {
+			return &category, nil
+		}
+	}
+
+	return nil, notFoundError("category not found")
+}
+
+// Get Active Category By Slug, used to validate product input
+func (cs *CategoryService) GetActiveCategoryBySlug(slug string) (*models.Category, error) {
+	category, err := cs.GetCategoryBySlug(slug)
+	if err != nil || !category.IsActive {
+		return nil, invalidError("invalid category type")
+	}
+
+	return category, nil
+}
+
+// Create Category
+func (cs *CategoryService) CreateCategory(req *structs.CategoryCreateRequest) (*models.Category, error) {
+	if !categorySlugPattern.MatchString(req.Slug) {
+		return nil, invalidError("invalid slug, use lowercase letters, digits and underscores")
+	}
+
+	var count int64
+	if err := cs.db.Model(&models.Category{}).Where("slug = ?", req.Slug).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check category slug: %v", err)
+	}
+	if count > 0 {
+		return nil, invalidError("category slug already exists")
+	}
+
+	category := models.Category{
+		Slug:        req.Slug,
+		DisplayName: req.DisplayName,
+		SortOrder:   req.SortOrder,
+		Icon:        req.Icon,
+		IsActive:    req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := cs.db.Create(&category).Error; err != nil {
+		return nil, fmt.Errorf("failed to create category: %v", err)
+	}
+
+	cs.invalidateCache()
+
+	return &category, nil
+}
+
+// Update Category
+func (cs *CategoryService) UpdateCategory(id uint, req *structs.CategoryUpdateRequest) (*models.Category, error) {
+	if !categorySlugPattern.MatchString(req.Slug) {
+		return nil, invalidError("invalid slug, use lowercase letters, digits and underscores")
+	}
+
+	var category models.Category
+	if err := cs.db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("category not found")
+		}
+		return nil, fmt.Errorf("failed to find category: %v", err)
+	}
 
-	allCategories := enums.GetAllCategories()
-	isValid := false
+	var count int64
+	if err := cs.db.Model(&models.Category{}).Where("slug = ? AND id <> ?", req.Slug, id).Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to check category slug: %v", err)
+	}
+	if count > 0 {
+		return nil, invalidError("category slug already exists")
+	}
+
+	category.Slug = req.Slug
+	category.DisplayName = req.DisplayName
+	category.SortOrder = req.SortOrder
+	category.Icon = req.Icon
+	if req.IsActive != nil {
+		category.IsActive = *req.IsActive
+	}
 
-	for _, validCategory := range allCategories {
-		if validCategory == categoryType {
-			isValid = true
-			break
+	if err := cs.db.Save(&category).Error; err != nil {
+		return nil, fmt.Errorf("failed to update category: %v", err)
+	}
+
+	cs.invalidateCache()
+
+	return &category, nil
+}
+
+// Delete Category, only when no product uses it
+func (cs *CategoryService) DeleteCategory(id uint) error {
+	var category models.Category
+	if err := cs.db.First(&category, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return notFoundError("category not found")
 		}
+		return fmt.Errorf("failed to find category: %v", err)
+	}
+
+	var productCount int64
+	if err := cs.db.Model(&models.Product{}).Where("category_id = ?", id).Count(&productCount).Error; err != nil {
+		return fmt.Errorf("failed to count category products: %v", err)
+	}
+	if productCount > 0 {
+		return invalidError("category is in use by %d products, deactivate it instead", productCount)
 	}
 
-	if !isValid {
-		return nil, errors.New("invalid category value")
+	if err := cs.db.Delete(&category).Error; err != nil {
+		return fmt.Errorf("failed to delete category: %v", err)
 	}
 
-	return &structs.CategoryResponse{
-		Value: string(categoryType),
-		Label: categoryType.GetDisplayName(),
-	}, nil
+	cs.invalidateCache()
+
+	return nil
+}
+
+// Convert model to response
+func toCategoryResponse(category *models.Category) structs.CategoryResponse {
+	return structs.CategoryResponse{
+		Id:        category.Id,
+		Value:     category.Slug,
+		Label:     category.DisplayName,
+		SortOrder: category.SortOrder,
+		Icon:      category.Icon,
+		IsActive:  category.IsActive,
+	}
 }
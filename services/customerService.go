@@ -0,0 +1,151 @@
+package services
+
+import (
+	"deck/config"
+	"deck/helpers"
+	"deck/models"
+	"deck/structs"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type CustomerService struct {
+	db *gorm.DB
+}
+
+func NewCustomerService(db *gorm.DB) *CustomerService {
+	return &CustomerService{db: db}
+}
+
+// CustomerWithStats is a customer together with figures computed from their paid transactions
+type CustomerWithStats struct {
+	models.Customer
+	OrderCount    int64
+	LifetimeValue int64
+	VisitDays     int64
+	FirstOrderAt  *time.Time
+	LastOrderAt   *time.Time
+}
+
+var customerSortOrders = map[string]string{
+	"recent":   "last_order_at DESC NULLS LAST, c.id DESC",
+	"value":    "lifetime_value DESC, c.id DESC",
+	"orders":   "order_count DESC, c.id DESC",
+	"name":     "c.name ASC, c.id ASC",
+	"newest":   "c.created_at DESC, c.id DESC",
+	"frequent": "visit_days DESC, c.id DESC",
+}
+
+// Get customers with their stats, optionally filtered by name or phone
+func (cs *CustomerService) GetCustomers(keyword string, sort string, limit int) ([]CustomerWithStats, error) {
+	order, ok := customerSortOrders[sort]
+	if !ok {
+		return nil, invalidError("invalid sort, use recent, value, orders, frequent, name or newest")
+	}
+
+	query := cs.withStats().Order(order).Limit(limit)
+	if keyword = strings.TrimSpace(keyword); keyword != "" {
+		like := "%" + keyword + "%"
+		if phone, err := helpers.NormalizePhone(keyword); err == nil {
+			query = query.Where("c.name ILIKE ? OR c.phone LIKE ?", like, "%"+phone+"%")
+		} else {
+			query = query.Where("c.name ILIKE ? OR c.phone LIKE ?", like, like)
+		}
+	}
+
+	var customers []CustomerWithStats
+	err := query.Scan(&customers).Error
+
+	return customers, err
+}
+
+// Get Customer By Id with stats
+func (cs *CustomerService) GetCustomerById(id uint) (*CustomerWithStats, error) {
+	var customers []CustomerWithStats
+	if err := cs.withStats().Where("c.id = ?", id).Scan(&customers).Error; err != nil {
+		return nil, err
+	}
+	if len(customers) == 0 {
+		return nil, notFoundError("customer not found")
+	}
+
+	return &customers[0], nil
+}
+
+// Update Customer profile, the phone number is the identity and stays unchanged
+func (cs *CustomerService) UpdateCustomer(id uint, req *structs.CustomerUpdateRequest) (*CustomerWithStats, error) {
+	var customer models.Customer
+	if err := cs.db.First(&customer, id).Error; err != nil {
+		return nil, notFoundError("customer not found")
+	}
+
+	customer.Name = strings.TrimSpace(req.Name)
+	customer.Email = strings.TrimSpace(req.Email)
+	customer.Notes = strings.TrimSpace(req.Notes)
+
+	if err := cs.db.Save(&customer).Error; err != nil {
+		return nil, fmt.Errorf("failed to update customer: %v", err)
+	}
+
+	return cs.GetCustomerById(id)
+}
+
+// Get the order history of a customer, newest first
+func (cs *CustomerService) GetCustomerTransactions(id uint, limit int) ([]models.Transaction, error) {
+	if err := cs.db.First(&models.Customer{}, id).Error; err != nil {
+		return nil, notFoundError("customer not found")
+	}
+
+	var transactions []models.Transaction
+	err := cs.db.Preload("TransactionDetails.Modifiers").Preload("TransactionDetails.Discounts").
+		Where("customer_id = ?", id).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&transactions).Error
+
+	return transactions, err
+}
+
+// withStats selects customers joined with their paid order count, lifetime value and visits. A visit is a
+// distinct day with a paid order in the outlet timezone.
+func (cs *CustomerService) withStats() *gorm.DB {
+	stats := cs.db.Table("transactions").
+		Select(`customer_id,
+			COUNT(*) AS order_count,
+			COALESCE(SUM(total_amount), 0) AS lifetime_value,
+			COUNT(DISTINCT DATE(created_at AT TIME ZONE ?)) AS visit_days,
+			MIN(created_at) AS first_order_at,
+			MAX(created_at) AS last_order_at`, config.Timezone()).
+		Where("customer_id IS NOT NULL AND payment_status = ?", models.PaymentStatusPaid).
+		Group("customer_id")
+
+	return cs.db.Table("customers c").
+		Select(`c.*,
+			COALESCE(s.order_count, 0) AS order_count,
+			COALESCE(s.lifetime_value, 0) AS lifetime_value,
+			COALESCE(s.visit_days, 0) AS visit_days,
+			s.first_order_at, s.last_order_at`).
+		Joins("LEFT JOIN (?) s ON s.customer_id = c.id", stats)
+}
+
+// upsertCustomer finds the customer of a phone number or creates one, keeping the latest name they ordered with
+func upsertCustomer(tx *gorm.DB, name string, phone string) (*models.Customer, error) {
+	customer := models.Customer{
+		Phone: phone,
+		Name:  strings.TrimSpace(name),
+	}
+
+	err := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "phone"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name", "updated_at"}),
+	}).Create(&customer).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to save customer: %v", err)
+	}
+
+	return &customer, nil
+}
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/structs"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type DiscountService struct {
+	db *gorm.DB
+}
+
+func NewDiscountService(db *gorm.DB) *DiscountService {
+	return &DiscountService{db: db}
+}
+
+// Get All Discounts, optionally only the active ones
+func (ds *DiscountService) GetDiscounts(activeOnly bool) ([]models.Discount, error) {
+	query := ds.db.Order("priority DESC, id ASC")
+	if activeOnly {
+		query = query.Where("is_active = ?", true)
+	}
+
+	var discounts []models.Discount
+	err := query.Find(&discounts).Error
+
+	return discounts, err
+}
+
+// Get Discount By Id
+func (ds *DiscountService) GetDiscountById(id uint) (*models.Discount, error) {
+	var discount models.Discount
+	if err := ds.db.First(&discount, id).Error; err != nil {
+		return nil, notFoundError("discount not found")
+	}
+
+	return &discount, nil
+}
+
+// Create Discount
+func (ds *DiscountService) CreateDiscount(req *structs.DiscountRequest) (*models.Discount, error) {
+	discount := models.Discount{IsActive: true}
+	if err := ds.fillDiscount(&discount, req); err != nil {
+		return nil, err
+	}
+
+	if err := ds.db.Create(&discount).Error; err != nil {
+		return nil, fmt.Errorf("failed to create discount: %v", err)
+	}
+
+	return &discount, nil
+}
+
+// Update Discount
+func (ds *DiscountService) UpdateDiscount(id uint, req *structs.DiscountRequest) (*models.Discount, error) {
+	discount, err := ds.GetDiscountById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ds.fillDiscount(discount, req); err != nil {
+		return nil, err
+	}
+
+	if err := ds.db.Save(discount).Error; err != nil {
+		return nil, fmt.Errorf("failed to update discount: %v", err)
+	}
+
+	return discount, nil
+}
+
+// Delete Discount, lines that already used it keep their snapshot
+func (ds *DiscountService) DeleteDiscount(id uint) error {
+	result := ds.db.Delete(&models.Discount{}, id)
+	if result.Error != nil {
+		return fmt.Errorf("failed to delete discount: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return notFoundError("discount not found")
+	}
+
+	return nil
+}
+
+// Get how much each discount took off paid orders within the date range
+func (ds *DiscountService) GetDiscountReport(fromDate string, toDate string) (*structs.DiscountReport, error) {
+	from, to, err := helpers.ParseLocalDateRange(fromDate, toDate)
+	if err != nil {
+		return nil, invalidError("%v", err)
+	}
+
+	query := ds.db.Table("transaction_detail_discounts tdd").
+		Joins("JOIN transaction_details td ON td.id = tdd.transaction_detail_id").
+		Joins("JOIN transactions t ON t.id = td.transaction_id").
+		Select(`tdd.discount_id, MAX(tdd.name) AS name,
+			COUNT(DISTINCT td.transaction_id) AS orders,
+			COUNT(DISTINCT td.id) AS lines,
+			COALESCE(SUM(td.quantity), 0) AS quantity,
+			COALESCE(SUM(tdd.amount), 0) AS discount_amount,
+			COALESCE(SUM(td.total_price), 0) AS net_revenue`).
+		Where("t.payment_status = ? AND td.voided_at IS NULL", models.PaymentStatusPaid).
+		Group("tdd.discount_id").
+		Order("discount_amount DESC")
+	if from != nil {
+		query = query.Where("t.created_at >= ?", *from)
+	}
+	if to != nil {
+		query = query.Where("t.created_at < ?", *to)
+	}
+
+	rows := []structs.DiscountReportRow{}
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to load discount report: %v", err)
+	}
+
+	report := &structs.DiscountReport{
+		From:      optionalString(fromDate),
+		To:        optionalString(toDate),
+		Discounts: rows,
+	}
+	for _, row := range rows {
+		report.TotalDiscount += row.DiscountAmount
+	}
+
+	return report, nil
+}
+
+func (ds *DiscountService) fillDiscount(discount *models.Discount, req *structs.DiscountRequest) error {
+	if req.ProductId != nil && req.CategoryId != nil {
+		return invalidError("invalid target, set either product_id or category_id, or neither for the whole menu")
+	}
+	if req.ProductId != nil {
+		if err := ds.db.First(&models.Product{}, *req.ProductId).Error; err != nil {
+			return invalidError("invalid target, product not found")
+		}
+	}
+	if req.CategoryId != nil {
+		if err := ds.db.First(&models.Category{}, *req.CategoryId).Error; err != nil {
+			return invalidError("invalid target, category not found")
+		}
+	}
+
+	if req.Type == models.DiscountTypePercentage && req.Value > 100 {
+		return invalidError("invalid value, a percentage discount cannot exceed 100")
+	}
+
+	startTime, endTime := req.StartTime, req.EndTime
+	if startTime == "" && endTime == "" {
+		startTime, endTime = "00:00", "00:00"
+	}
+	if !scheduleTimePattern.MatchString(startTime) || !scheduleTimePattern.MatchString(endTime) {
+		return invalidError("invalid time, use HH:MM between 00:00 and 23:59")
+	}
+
+	days := models.AllDays
+	if len(req.Days) > 0 {
+		days = 0
+		for _, day := range req.Days {
+			days |= 1 << uint(day)
+		}
+	}
+
+	var startDate, endDate *time.Time
+	if req.StartDate != "" {
+		date, err := time.Parse("2006-01-02", req.StartDate)
+		if err != nil {
+			return invalidError("invalid start_date, use YYYY-MM-DD")
+		}
+		startDate = &date
+	}
+	if req.EndDate != "" {
+		date, err := time.Parse("2006-01-02", req.EndDate)
+		if err != nil {
+			return invalidError("invalid end_date, use YYYY-MM-DD")
+		}
+		endDate = &date
+	}
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		return invalidError("invalid date range, end_date is before start_date")
+	}
+
+	discount.Name = strings.TrimSpace(req.Name)
+	discount.Type = req.Type
+	discount.Value = req.Value
+	discount.ProductId = req.ProductId
+	discount.CategoryId = req.CategoryId
+	discount.Days = days
+	discount.StartTime = startTime
+	discount.EndTime = endTime
+	discount.StartDate = startDate
+	discount.EndDate = endDate
+	discount.Stackable = req.Stackable
+	discount.Priority = req.Priority
+	if req.IsActive != nil {
+		discount.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+// loadRunningDiscounts returns the active discounts whose window covers local time now, highest priority first
+func loadRunningDiscounts(tx *gorm.DB, now time.Time) ([]models.Discount, error) {
+	var discounts []models.Discount
+	if err := tx.Where("is_active = ?", true).Order("priority DESC, id ASC").Find(&discounts).Error; err != nil {
+		return nil, fmt.Errorf("failed to load discounts: %v", err)
+	}
+
+	running := make([]models.Discount, 0, len(discounts))
+	for _, discount := range discounts {
+		if discount.Covers(now) {
+			running = append(running, discount)
+		}
+	}
+
+	return running, nil
+}
+
+// applyAutomaticDiscounts picks the discounts for an order line and returns their snapshots and the total taken off.
+// Stackable discounts are applied one after another in priority order, each on what is left of the line, and
+// their combined amount competes with the best single non-stackable discount.
+func applyAutomaticDiscounts(discounts []models.Discount, product *models.Product, lineTotal uint, quantity uint) ([]models.TransactionDetailDiscount, uint) {
+	var stacked []models.TransactionDetailDiscount
+	var stackedAmount uint
+	var best *models.TransactionDetailDiscount
+
+	for _, discount := range discounts {
+		if !discount.Targets(product) {
+			continue
+		}
+
+		if discount.Stackable {
+			amount := discount.AmountFor(lineTotal-stackedAmount, quantity)
+			if amount == 0 {
+				continue
+			}
+			stacked = append(stacked, discountSnapshot(discount, amount))
+			stackedAmount += amount
+			continue
+		}
+
+		amount := discount.AmountFor(lineTotal, quantity)
+		if amount > 0 && (best == nil || amount > best.Amount) {
+			snapshot := discountSnapshot(discount, amount)
+			best = &snapshot
+		}
+	}
+
+	if best != nil && best.Amount >= stackedAmount {
+		return []models.TransactionDetailDiscount{*best}, best.Amount
+	}
+
+	return stacked, stackedAmount
+}
+
+func discountSnapshot(discount models.Discount, amount uint) models.TransactionDetailDiscount {
+	return models.TransactionDetailDiscount{
+		DiscountId: discount.Id,
+		Name:       discount.Name,
+		Type:       discount.Type,
+		Value:      discount.Value,
+		Amount:     amount,
+	}
+}
@@ -0,0 +1,49 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Kinds of the errors a service returns for a request it cannot serve. Controllers check them with errors.Is to
+// pick the response status, any other error is a failure of the server.
+var (
+	// ErrNotFound is a record named by the request that does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrInvalid is a request the outlet rules reject, like a bad value, a duplicate or a record still in use
+	ErrInvalid = errors.New("invalid request")
+	// ErrTooManyRequests is a request over a rate limit
+	ErrTooManyRequests = errors.New("too many requests")
+	// ErrPaymentGateway is a payment Midtrans failed or refused to create
+	ErrPaymentGateway = errors.New("payment gateway error")
+)
+
+// serviceError is a message for the client tagged with the kind of error it is
+type serviceError struct {
+	kind    error
+	message string
+}
+
+func (e *serviceError) Error() string {
+	return e.message
+}
+
+func (e *serviceError) Unwrap() error {
+	return e.kind
+}
+
+func notFoundError(format string, args ...interface{}) error {
+	return &serviceError{kind: ErrNotFound, message: fmt.Sprintf(format, args...)}
+}
+
+func invalidError(format string, args ...interface{}) error {
+	return &serviceError{kind: ErrInvalid, message: fmt.Sprintf(format, args...)}
+}
+
+func tooManyRequestsError(format string, args ...interface{}) error {
+	return &serviceError{kind: ErrTooManyRequests, message: fmt.Sprintf(format, args...)}
+}
+
+func paymentGatewayError(format string, args ...interface{}) error {
+	return &serviceError{kind: ErrPaymentGateway, message: fmt.Sprintf(format, args...)}
+}
@@ -0,0 +1,1158 @@
+package services
+
+import (
+	"deck/config"
+	"deck/helpers"
+	"deck/models"
+	"deck/structs"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Export kinds, one for each list or report that can be downloaded as CSV or XLSX
+const (
+	ExportTransactions       = "transactions"
+	ExportDailyReport        = "daily_report"
+	ExportZReports           = "z_reports"
+	ExportZReport            = "z_report"
+	ExportProductPerformance = "product_performance"
+	ExportSalesHeatmap       = "sales_heatmap"
+	ExportDiscountReport     = "discount_report"
+	ExportPricePeriodReport  = "price_period_report"
+	ExportIngredientVariance = "ingredient_variance"
+)
+
+const (
+	ExportLanguageIndonesian = "id"
+	ExportLanguageEnglish    = "en"
+)
+
+// exportDir holds the files of background exports until they expire
+const exportDir = "exports"
+
+// Column kinds, they decide how a value is formatted in CSV and which number format it gets in XLSX. A phone is
+// text that is written as it is, its leading + is not a formula.
+const (
+	exportText = iota
+	exportPhone
+	exportNumber
+	exportDecimal
+	exportRupiah
+	exportPercent
+)
+
+// exportLabel is a text in each export language
+type exportLabel struct {
+	id string
+	en string
+}
+
+func (l exportLabel) in(language string) string {
+	if language == ExportLanguageEnglish {
+		return l.en
+	}
+
+	return l.id
+}
+
+type exportColumn struct {
+	label exportLabel
+	kind  int
+}
+
+// exportCell overrides the kind of its column for one value, for sheets that list figures of different kinds
+type exportCell struct {
+	value interface{}
+	kind  int
+}
+
+// exportSheet is one table of an export. Its rows are produced by a callback so that a long list is written
+// while it is read from the database instead of being loaded into memory first.
+type exportSheet struct {
+	title   exportLabel
+	columns []exportColumn
+	rows    func(emit func(values ...interface{}) error) error
+}
+
+// ExportDocument is an export that is ready to be written. Rows is the number of rows known before writing,
+// only set for lists that can grow large.
+type ExportDocument struct {
+	Name     string
+	Language string
+	Rows     int64
+	sheets   []exportSheet
+}
+
+// exportValueLabels translate the status, order type and payment method codes written in exports
+var exportValueLabels = map[string]exportLabel{
+	models.PaymentStatusPending:   {"Menunggu", "Pending"},
+	models.PaymentStatusPaid:      {"Lunas", "Paid"},
+	models.PaymentStatusFailed:    {"Gagal", "Failed"},
+	models.PaymentStatusExpired:   {"Kedaluwarsa", "Expired"},
+	models.PaymentStatusCancelled: {"Dibatalkan", "Cancelled"},
+	models.PaymentStatusRefunded:  {"Direfund", "Refunded"},
+	models.OrderTypeDineIn:        {"Makan di tempat", "Dine in"},
+	models.OrderTypeTakeaway:      {"Bawa pulang", "Takeaway"},
+	models.OrderTypeDelivery:      {"Pesan antar", "Delivery"},
+	models.PaymentMethodCash:      {"Tunai", "Cash"},
+	models.PaymentMethodCard:      {"Kartu", "Card"},
+	models.PaymentMethodQris:      {"QRIS", "QRIS"},
+	models.PaymentMethodTransfer:  {"Transfer", "Transfer"},
+	models.PaymentMethodMidtrans:  {"Midtrans", "Midtrans"},
+	models.PaymentMethodSplit:     {"Split", "Split"},
+}
+
+var exportWeekdays = []exportLabel{
+	{"Senin", "Monday"}, {"Selasa", "Tuesday"}, {"Rabu", "Wednesday"}, {"Kamis", "Thursday"},
+	{"Jumat", "Friday"}, {"Sabtu", "Saturday"}, {"Minggu", "Sunday"},
+}
+
+type ExportService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+	reportService       *ReportService
+	discountService     *DiscountService
+	priceService        *ProductPriceService
+	stockCountService   *StockCountService
+}
+
+func NewExportService(db *gorm.DB, notificationService *NotificationService, reportService *ReportService, discountService *DiscountService, priceService *ProductPriceService, stockCountService *StockCountService) *ExportService {
+	return &ExportService{
+		db:                  db,
+		notificationService: notificationService,
+		reportService:       reportService,
+		discountService:     discountService,
+		priceService:        priceService,
+		stockCountService:   stockCountService,
+	}
+}
+
+// Prepare builds the export of a kind from the same query params as its JSON endpoint. Reports are computed
+// here so invalid params fail before anything is written, long lists only count their rows.
+func (es *ExportService) Prepare(kind string, language string, params map[string]string) (*ExportDocument, error) {
+	if language != ExportLanguageIndonesian && language != ExportLanguageEnglish {
+		return nil, invalidError("invalid language, use id or en")
+	}
+
+	var doc *ExportDocument
+	var err error
+	switch kind {
+	case ExportTransactions:
+		doc, err = es.prepareTransactions(params)
+	case ExportDailyReport:
+		doc, err = es.prepareDailyReport(params)
+	case ExportZReports:
+		doc, err = es.prepareZReports(params)
+	case ExportZReport:
+		doc, err = es.prepareZReport(params)
+	case ExportProductPerformance:
+		doc, err = es.prepareProductPerformance(params)
+	case ExportSalesHeatmap:
+		doc, err = es.prepareSalesHeatmap(params)
+	case ExportDiscountReport:
+		doc, err = es.prepareDiscountReport(params)
+	case ExportPricePeriodReport:
+		doc, err = es.preparePricePeriodReport(params)
+	case ExportIngredientVariance:
+		doc, err = es.prepareIngredientVariance(params)
+	default:
+		return nil, invalidError("invalid export %s", kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Language = language
+	return doc, nil
+}
+
+// Large tells whether the export has more rows than EXPORT_SYNC_MAX_ROWS, 5000 by default, and should be
+// written in the background
+func (doc *ExportDocument) Large() bool {
+	return doc.Rows > exportSyncMaxRows()
+}
+
+// Write writes the export as CSV or XLSX and returns how many rows it wrote
+func (doc *ExportDocument) Write(format string, w io.Writer) (int64, error) {
+	switch format {
+	case "csv":
+		return doc.writeCsv(w)
+	case "xlsx":
+		return doc.writeXlsx(w)
+	default:
+		return 0, invalidError("invalid format, use csv or xlsx")
+	}
+}
+
+// writeCsv writes the sheets one after the other, each under its title when there are several. Indonesian
+// spreadsheets read a comma as the decimal separator, so Indonesian exports separate columns with semicolons.
+// Rupiah amounts are whole numbers without thousands separators so they stay numbers once opened.
+func (doc *ExportDocument) writeCsv(w io.Writer) (int64, error) {
+	// The byte order mark makes spreadsheets read the file as UTF-8
+	if _, err := w.Write([]byte("\xef\xbb\xbf")); err != nil {
+		return 0, err
+	}
+
+	writer := csv.NewWriter(w)
+	if doc.Language == ExportLanguageIndonesian {
+		writer.Comma = ';'
+	}
+
+	var rows int64
+	for i, sheet := range doc.sheets {
+		if len(doc.sheets) > 1 {
+			if i > 0 {
+				writer.Write([]string{})
+			}
+			writer.Write([]string{sheet.title.in(doc.Language)})
+		}
+
+		header := make([]string, len(sheet.columns))
+		for j, column := range sheet.columns {
+			header[j] = column.label.in(doc.Language)
+			switch column.kind {
+			case exportRupiah:
+				header[j] += " (Rp)"
+			case exportPercent:
+				header[j] += " (%)"
+			}
+		}
+		writer.Write(header)
+
+		err := sheet.rows(func(values ...interface{}) error {
+			record := make([]string, len(values))
+			for j, value := range values {
+				value, kind := exportValue(value, sheet.columns[j].kind)
+				record[j] = csvValue(value, kind, doc.Language)
+			}
+			if err := writer.Write(record); err != nil {
+				return err
+			}
+
+			rows++
+			if rows%500 == 0 {
+				writer.Flush()
+				return writer.Error()
+			}
+			return nil
+		})
+		if err != nil {
+			return rows, fmt.Errorf("failed to write csv: %v", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return rows, fmt.Errorf("failed to write csv: %v", err)
+	}
+
+	return rows, nil
+}
+
+// writeXlsx writes each sheet on its own worksheet through the excelize stream writer. Amounts stay numbers with
+// a Rupiah number format, the thousands separator follows the language of the spreadsheet that opens them.
+func (doc *ExportDocument) writeXlsx(w io.Writer) (int64, error) {
+	file := excelize.NewFile()
+	defer file.Close()
+
+	styles := map[int]int{}
+	numberFormats := map[int]string{
+		exportNumber:  "#,##0",
+		exportDecimal: "#,##0.###",
+		exportRupiah:  `"Rp "#,##0;-"Rp "#,##0`,
+		exportPercent: `0.0"%"`,
+	}
+	for kind, numberFormat := range numberFormats {
+		style, err := file.NewStyle(&excelize.Style{CustomNumFmt: &numberFormat})
+		if err != nil {
+			return 0, fmt.Errorf("failed to write xlsx: %v", err)
+		}
+		styles[kind] = style
+	}
+	headerStyle, err := file.NewStyle(&excelize.Style{Font: &excelize.Font{Bold: true}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to write xlsx: %v", err)
+	}
+
+	var rows int64
+	for i, sheet := range doc.sheets {
+		name := xlsxSheetName(sheet.title.in(doc.Language))
+		if i == 0 {
+			err = file.SetSheetName(file.GetSheetName(0), name)
+		} else {
+			_, err = file.NewSheet(name)
+		}
+		if err != nil {
+			return rows, fmt.Errorf("failed to write xlsx: %v", err)
+		}
+
+		stream, err := file.NewStreamWriter(name)
+		if err != nil {
+			return rows, fmt.Errorf("failed to write xlsx: %v", err)
+		}
+		if err := stream.SetColWidth(1, len(sheet.columns), 18); err != nil {
+			return rows, fmt.Errorf("failed to write xlsx: %v", err)
+		}
+		if err := stream.SetPanes(&excelize.Panes{Freeze: true, YSplit: 1, TopLeftCell: "A2", ActivePane: "bottomLeft"}); err != nil {
+			return rows, fmt.Errorf("failed to write xlsx: %v", err)
+		}
+
+		header := make([]interface{}, len(sheet.columns))
+		for j, column := range sheet.columns {
+			header[j] = excelize.Cell{StyleID: headerStyle, Value: column.label.in(doc.Language)}
+		}
+		if err := stream.SetRow("A1", header); err != nil {
+			return rows, fmt.Errorf("failed to write xlsx: %v", err)
+		}
+
+		line := 1
+		err = sheet.rows(func(values ...interface{}) error {
+			cells := make([]interface{}, len(values))
+			for j, value := range values {
+				value, kind := exportValue(value, sheet.columns[j].kind)
+				if label, ok := value.(exportLabel); ok {
+					value = label.in(doc.Language)
+				}
+				cells[j] = excelize.Cell{StyleID: styles[kind], Value: value}
+			}
+
+			line++
+			rows++
+			cell, _ := excelize.CoordinatesToCellName(1, line)
+			return stream.SetRow(cell, cells)
+		})
+		if err != nil {
+			return rows, fmt.Errorf("failed to write xlsx: %v", err)
+		}
+		if err := stream.Flush(); err != nil {
+			return rows, fmt.Errorf("failed to write xlsx: %v", err)
+		}
+	}
+
+	if err := file.Write(w); err != nil {
+		return rows, fmt.Errorf("failed to write xlsx: %v", err)
+	}
+
+	return rows, nil
+}
+
+// exportValue unwraps a value for writing: pointers become their value or nil and times are shown in the outlet
+// timezone
+func exportValue(value interface{}, kind int) (interface{}, int) {
+	if cell, ok := value.(exportCell); ok {
+		value, kind = cell.value, cell.kind
+	}
+
+	switch v := value.(type) {
+	case *string:
+		if v == nil {
+			return nil, kind
+		}
+		value = *v
+	case *time.Time:
+		if v == nil {
+			return nil, kind
+		}
+		value = v.In(config.Location()).Format("2006-01-02 15:04:05")
+	case time.Time:
+		value = v.In(config.Location()).Format("2006-01-02 15:04:05")
+	case *float64:
+		if v == nil {
+			return nil, kind
+		}
+		value = *v
+	case *int:
+		if v == nil {
+			return nil, kind
+		}
+		value = *v
+	}
+
+	return value, kind
+}
+
+// exportCode writes a status, order type or payment method code as its label
+func exportCode(code string) interface{} {
+	if label, ok := exportValueLabels[code]; ok {
+		return label
+	}
+
+	return code
+}
+
+func csvValue(value interface{}, kind int, language string) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case exportLabel:
+		return v.in(language)
+	case string:
+		// Buyer names and notes come from the public order page, a spreadsheet would run one starting like a
+		// formula. The quote makes it plain text.
+		if kind == exportText && v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	case float64:
+		if kind == exportRupiah {
+			return strconv.FormatInt(int64(math.Round(v)), 10)
+		}
+		text := strconv.FormatFloat(v, 'f', -1, 64)
+		if language == ExportLanguageIndonesian {
+			text = strings.Replace(text, ".", ",", 1)
+		}
+		return text
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// xlsxSheetName fits a title in the 31 characters a worksheet name may have, without the characters it may not
+func xlsxSheetName(title string) string {
+	name := strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`[]:*?/\`, r) {
+			return '-'
+		}
+		return r
+	}, title)
+	if runes := []rune(name); len(runes) > 31 {
+		name = string(runes[:31])
+	}
+
+	return name
+}
+
+// exportRows emits the rows of a slice that is already in memory
+func exportRows[T any](items []T, values func(item T) []interface{}) func(emit func(values ...interface{}) error) error {
+	return func(emit func(values ...interface{}) error) error {
+		for _, item := range items {
+			if err := emit(values(item)...); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+type transactionExportRow struct {
+	OrderNumber    string
+	CreatedAt      time.Time
+	PaidAt         *time.Time
+	RefundedAt     *time.Time
+	PaymentStatus  string
+	PaymentMethod  string
+	OrderType      string
+	BuyerName      string
+	Phone          string
+	Items          int64
+	SubTotal       uint
+	DiscountAmount uint
+	PointsDiscount uint
+	TotalAmount    uint
+}
+
+// prepareTransactions lists the transactions created between the from and to dates, optionally of one status.
+// The rows are read with a cursor while the file is written.
+func (es *ExportService) prepareTransactions(params map[string]string) (*ExportDocument, error) {
+	from, to, err := helpers.ParseLocalDateRange(params["from"], params["to"])
+	if err != nil {
+		return nil, invalidError("%v", err)
+	}
+	status := params["status"]
+	switch status {
+	case "", models.PaymentStatusPending, models.PaymentStatusPaid, models.PaymentStatusFailed,
+		models.PaymentStatusExpired, models.PaymentStatusCancelled, models.PaymentStatusRefunded:
+	default:
+		return nil, invalidError("invalid status %s", status)
+	}
+
+	filter := func() *gorm.DB {
+		query := es.db.Table("transactions t")
+		if from != nil {
+			query = query.Where("t.created_at >= ?", *from)
+		}
+		if to != nil {
+			query = query.Where("t.created_at < ?", *to)
+		}
+		if status != "" {
+			query = query.Where("t.payment_status = ?", status)
+		}
+		return query
+	}
+
+	var count int64
+	if err := filter().Count(&count).Error; err != nil {
+		return nil, fmt.Errorf("failed to count transactions: %v", err)
+	}
+
+	taxRate := taxRatePercent()
+	sheet := exportSheet{
+		title: exportLabel{"Transaksi", "Transactions"},
+		columns: []exportColumn{
+			{exportLabel{"No. Pesanan", "Order Number"}, exportText},
+			{exportLabel{"Dibuat", "Created At"}, exportText},
+			{exportLabel{"Dibayar", "Paid At"}, exportText},
+			{exportLabel{"Direfund", "Refunded At"}, exportText},
+			{exportLabel{"Status", "Status"}, exportText},
+			{exportLabel{"Metode Bayar", "Payment Method"}, exportText},
+			{exportLabel{"Jenis Pesanan", "Order Type"}, exportText},
+			{exportLabel{"Pembeli", "Buyer"}, exportText},
+			{exportLabel{"Telepon", "Phone"}, exportPhone},
+			{exportLabel{"Jumlah Item", "Items"}, exportNumber},
+			{exportLabel{"Subtotal", "Subtotal"}, exportRupiah},
+			{exportLabel{"Diskon", "Discount"}, exportRupiah},
+			{exportLabel{"Diskon Poin", "Points Discount"}, exportRupiah},
+			{exportLabel{"Total", "Total"}, exportRupiah},
+			{exportLabel{fmt.Sprintf("Termasuk PPN %d%%", taxRate), fmt.Sprintf("Incl. Tax %d%%", taxRate)}, exportRupiah},
+		},
+		rows: func(emit func(values ...interface{}) error) error {
+			rows, err := filter().
+				Select(`t.order_number, t.created_at, t.paid_at, t.refunded_at, t.payment_status, t.payment_method,
+					t.order_type, t.buyer_name, t.phone, t.sub_total, t.discount_amount, t.points_discount, t.total_amount,
+					(SELECT COALESCE(SUM(td.quantity), 0) FROM transaction_details td
+						WHERE td.transaction_id = t.id AND td.parent_detail_id IS NULL AND td.voided_at IS NULL) AS items`).
+				Order("t.created_at ASC, t.id ASC").
+				Rows()
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var row transactionExportRow
+				if err := es.db.ScanRows(rows, &row); err != nil {
+					return err
+				}
+				if err := emit(row.OrderNumber, row.CreatedAt, row.PaidAt, row.RefundedAt, exportCode(row.PaymentStatus),
+					exportCode(row.PaymentMethod), exportCode(row.OrderType), row.BuyerName, row.Phone, row.Items, row.SubTotal,
+					row.DiscountAmount, row.PointsDiscount, row.TotalAmount, includedTax(row.TotalAmount, taxRate)); err != nil {
+					return err
+				}
+			}
+			return rows.Err()
+		},
+	}
+
+	return &ExportDocument{
+		Name:   "transactions-" + time.Now().In(config.Location()).Format("20060102150405"),
+		Rows:   count,
+		sheets: []exportSheet{sheet},
+	}, nil
+}
+
+func (es *ExportService) prepareDailyReport(params map[string]string) (*ExportDocument, error) {
+	report, err := es.reportService.GetDailyReport(params["date"])
+	if err != nil {
+		return nil, err
+	}
+
+	return dailyReportDocument("daily-report-"+report.Date, report), nil
+}
+
+func (es *ExportService) prepareZReport(params map[string]string) (*ExportDocument, error) {
+	id, err := strconv.ParseUint(params["id"], 10, 32)
+	if err != nil {
+		return nil, invalidError("invalid z-report ID")
+	}
+
+	zReport, err := es.reportService.GetZReportById(uint(id))
+	if err != nil {
+		return nil, err
+	}
+	report, err := ZReportData(zReport)
+	if err != nil {
+		return nil, err
+	}
+
+	return dailyReportDocument("z-report-"+report.Date, report), nil
+}
+
+// dailyReportDocument lays out a daily report as a summary with the payment methods and categories after it
+func dailyReportDocument(name string, report *structs.DailyReport) *ExportDocument {
+	summary := [][]interface{}{
+		{exportLabel{"Tanggal", "Date"}, report.Date},
+		{exportLabel{"Zona Waktu", "Timezone"}, report.Timezone},
+		{exportLabel{"Pesanan", "Orders"}, exportCell{report.Orders, exportNumber}},
+		{exportLabel{"Item Terjual", "Items Sold"}, exportCell{report.ItemsSold, exportNumber}},
+		{exportLabel{"Penjualan Kotor", "Gross Sales"}, exportCell{report.GrossSales, exportRupiah}},
+		{exportLabel{"Diskon", "Discounts"}, exportCell{report.Discounts, exportRupiah}},
+		{exportLabel{"Diskon Poin", "Points Discounts"}, exportCell{report.PointsDiscounts, exportRupiah}},
+		{exportLabel{"Refund", "Refunds"}, exportCell{report.Refunds, exportRupiah}},
+		{exportLabel{"Jumlah Refund", "Refund Count"}, exportCell{report.RefundCount, exportNumber}},
+		{exportLabel{"Penjualan Bersih", "Net Sales"}, exportCell{report.NetSales, exportRupiah}},
+		{exportLabel{"Tarif PPN", "Tax Rate"}, exportCell{float64(report.TaxRate), exportPercent}},
+		{exportLabel{"PPN", "Tax"}, exportCell{report.Tax, exportRupiah}},
+		{exportLabel{"Penjualan Bersih Sebelum PPN", "Net Sales Before Tax"}, exportCell{report.NetSalesBeforeTax, exportRupiah}},
+		{exportLabel{"Rata-rata Pesanan", "Average Order Value"}, exportCell{report.AverageOrderValue, exportRupiah}},
+		{exportLabel{"Pesanan Dibatalkan", "Cancelled Orders"}, exportCell{report.CancelledOrders, exportNumber}},
+		{exportLabel{"Pesanan Kedaluwarsa", "Expired Orders"}, exportCell{report.ExpiredOrders, exportNumber}},
+	}
+	if report.ZReport != nil {
+		summary = append(summary,
+			[]interface{}{exportLabel{"Ditutup Pada", "Closed At"}, report.ZReport.ClosedAt},
+			[]interface{}{exportLabel{"Ditutup Oleh", "Closed By"}, report.ZReport.ClosedBy},
+		)
+	}
+
+	return &ExportDocument{
+		Name: name,
+		sheets: []exportSheet{
+			{
+				title: exportLabel{"Ringkasan", "Summary"},
+				columns: []exportColumn{
+					{exportLabel{"Keterangan", "Item"}, exportText},
+					{exportLabel{"Nilai", "Value"}, exportText},
+				},
+				rows: exportRows(summary, func(row []interface{}) []interface{} { return row }),
+			},
+			{
+				title: exportLabel{"Metode Pembayaran", "Payment Methods"},
+				columns: []exportColumn{
+					{exportLabel{"Metode", "Method"}, exportText},
+					{exportLabel{"Transaksi", "Transactions"}, exportNumber},
+					{exportLabel{"Nominal", "Amount"}, exportRupiah},
+				},
+				rows: exportRows(report.PaymentMethods, func(row structs.DailyReportPaymentRow) []interface{} {
+					return []interface{}{exportCode(row.Method), row.Count, row.Amount}
+				}),
+			},
+			{
+				title: exportLabel{"Kategori", "Categories"},
+				columns: []exportColumn{
+					{exportLabel{"Kategori", "Category"}, exportText},
+					{exportLabel{"Qty", "Quantity"}, exportNumber},
+					{exportLabel{"Penjualan Kotor", "Gross Sales"}, exportRupiah},
+					{exportLabel{"Diskon", "Discount"}, exportRupiah},
+					{exportLabel{"Penjualan Bersih", "Net Sales"}, exportRupiah},
+				},
+				rows: exportRows(report.Categories, func(row structs.DailyReportCategoryRow) []interface{} {
+					return []interface{}{row.Category, row.Quantity, row.GrossSales, row.DiscountAmount, row.NetSales}
+				}),
+			},
+		},
+	}
+}
+
+func (es *ExportService) prepareZReports(params map[string]string) (*ExportDocument, error) {
+	zReports, err := es.reportService.GetZReports(params["from"], params["to"])
+	if err != nil {
+		return nil, err
+	}
+
+	reports := make([]*structs.DailyReport, 0, len(zReports))
+	for _, zReport := range zReports {
+		report, err := ZReportData(&zReport)
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, report)
+	}
+
+	return &ExportDocument{
+		Name: "z-reports-" + time.Now().In(config.Location()).Format("20060102150405"),
+		sheets: []exportSheet{{
+			title: exportLabel{"Z-Report", "Z-Reports"},
+			columns: []exportColumn{
+				{exportLabel{"Tanggal", "Date"}, exportText},
+				{exportLabel{"Ditutup Pada", "Closed At"}, exportText},
+				{exportLabel{"Ditutup Oleh", "Closed By"}, exportText},
+				{exportLabel{"Pesanan", "Orders"}, exportNumber},
+				{exportLabel{"Penjualan Kotor", "Gross Sales"}, exportRupiah},
+				{exportLabel{"Diskon", "Discounts"}, exportRupiah},
+				{exportLabel{"Diskon Poin", "Points Discounts"}, exportRupiah},
+				{exportLabel{"Refund", "Refunds"}, exportRupiah},
+				{exportLabel{"Penjualan Bersih", "Net Sales"}, exportRupiah},
+				{exportLabel{"PPN", "Tax"}, exportRupiah},
+			},
+			rows: exportRows(reports, func(report *structs.DailyReport) []interface{} {
+				closedAt, closedBy := "", ""
+				if report.ZReport != nil {
+					closedAt, closedBy = report.ZReport.ClosedAt, report.ZReport.ClosedBy
+				}
+				return []interface{}{report.Date, closedAt, closedBy, report.Orders, report.GrossSales, report.Discounts,
+					report.PointsDiscounts, report.Refunds, report.NetSales, report.Tax}
+			}),
+		}},
+	}, nil
+}
+
+func (es *ExportService) prepareProductPerformance(params map[string]string) (*ExportDocument, error) {
+	query := structs.ProductPerformanceQuery{
+		From:    params["from"],
+		To:      params["to"],
+		GroupBy: params["group_by"],
+		SortBy:  params["sort_by"],
+		Bucket:  params["bucket"],
+	}
+	if value := params["limit"]; value != "" {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > 100 {
+			return nil, invalidError("invalid limit, use 1 to 100")
+		}
+		query.Limit = limit
+	}
+
+	report, err := es.reportService.GetProductPerformance(query)
+	if err != nil {
+		return nil, err
+	}
+
+	name := exportLabel{"Produk", "Product"}
+	if report.GroupBy == "category" {
+		name = exportLabel{"Kategori", "Category"}
+	}
+
+	type seriesRow struct {
+		name     string
+		bucket   string
+		quantity int64
+		revenue  int64
+	}
+	var series []seriesRow
+	for _, row := range report.Rows {
+		for i, bucket := range report.Buckets {
+			if i < len(row.QuantitySeries) && i < len(row.RevenueSeries) {
+				series = append(series, seriesRow{row.Name, bucket, row.QuantitySeries[i], row.RevenueSeries[i]})
+			}
+		}
+	}
+
+	return &ExportDocument{
+		Name: fmt.Sprintf("product-performance-%s-%s", report.From, report.To),
+		sheets: []exportSheet{
+			{
+				title: exportLabel{"Performa", "Performance"},
+				columns: []exportColumn{
+					{exportLabel{"Peringkat", "Rank"}, exportNumber},
+					{name, exportText},
+					{exportLabel{"Qty", "Quantity"}, exportNumber},
+					{exportLabel{"Pendapatan", "Revenue"}, exportRupiah},
+					{exportLabel{"Diskon", "Discount"}, exportRupiah},
+					{exportLabel{"Pesanan", "Orders"}, exportNumber},
+					{exportLabel{"Harga Rata-rata", "Average Price"}, exportRupiah},
+					{exportLabel{"Porsi Pendapatan", "Revenue Share"}, exportPercent},
+					{exportLabel{"Tingkat Modifier", "Modifier Attach Rate"}, exportPercent},
+					{exportLabel{"Peringkat Sebelumnya", "Previous Rank"}, exportNumber},
+					{exportLabel{"Qty Sebelumnya", "Previous Quantity"}, exportNumber},
+					{exportLabel{"Pendapatan Sebelumnya", "Previous Revenue"}, exportRupiah},
+					{exportLabel{"Perubahan Qty", "Quantity Change"}, exportPercent},
+					{exportLabel{"Perubahan Pendapatan", "Revenue Change"}, exportPercent},
+				},
+				rows: exportRows(report.Rows, func(row structs.ProductPerformanceRow) []interface{} {
+					return []interface{}{row.Rank, row.Name, row.Quantity, row.Revenue, row.DiscountAmount, row.Orders,
+						row.AveragePrice, row.RevenueShare, row.ModifierAttachRate, row.PreviousRank, row.PreviousQuantity,
+						row.PreviousRevenue, row.QuantityChange, row.RevenueChange}
+				}),
+			},
+			{
+				title: exportLabel{"Tren", "Trend"},
+				columns: []exportColumn{
+					{name, exportText},
+					{exportLabel{"Periode", "Period"}, exportText},
+					{exportLabel{"Qty", "Quantity"}, exportNumber},
+					{exportLabel{"Pendapatan", "Revenue"}, exportRupiah},
+				},
+				rows: exportRows(series, func(row seriesRow) []interface{} {
+					return []interface{}{row.name, row.bucket, row.quantity, row.revenue}
+				}),
+			},
+			{
+				title: exportLabel{"Modifier", "Modifiers"},
+				columns: []exportColumn{
+					{exportLabel{"Grup", "Group"}, exportText},
+					{exportLabel{"Modifier", "Modifier"}, exportText},
+					{exportLabel{"Qty Terpasang", "Attached Quantity"}, exportNumber},
+					{exportLabel{"Qty Tersedia", "Eligible Quantity"}, exportNumber},
+					{exportLabel{"Tingkat Pasang", "Attach Rate"}, exportPercent},
+				},
+				rows: exportRows(report.Modifiers, func(row structs.ModifierAttachRow) []interface{} {
+					return []interface{}{row.GroupName, row.Name, row.AttachedQuantity, row.EligibleQuantity, row.AttachRate}
+				}),
+			},
+		},
+	}, nil
+}
+
+// prepareSalesHeatmap lays the heatmap out as a weekday by hour grid, once for orders and once for revenue
+func (es *ExportService) prepareSalesHeatmap(params map[string]string) (*ExportDocument, error) {
+	heatmap, err := es.reportService.GetSalesHeatmap(params["from"], params["to"])
+	if err != nil {
+		return nil, err
+	}
+
+	grid := func(title exportLabel, kind int, value func(cell structs.SalesHeatmapCell) int64) exportSheet {
+		columns := []exportColumn{{exportLabel{"Hari", "Day"}, exportText}}
+		for hour := 0; hour < 24; hour++ {
+			label := fmt.Sprintf("%02d:00", hour)
+			columns = append(columns, exportColumn{exportLabel{label, label}, kind})
+		}
+		columns = append(columns, exportColumn{exportLabel{"Total", "Total"}, kind})
+
+		return exportSheet{
+			title:   title,
+			columns: columns,
+			rows: exportRows(heatmap.Weekdays, func(weekday structs.SalesHeatmapWeekday) []interface{} {
+				values := []interface{}{exportWeekdays[weekday.Weekday-1]}
+				var total int64
+				for _, cell := range heatmap.Cells[(weekday.Weekday-1)*24 : weekday.Weekday*24] {
+					amount := value(cell)
+					values = append(values, amount)
+					total += amount
+				}
+				return append(values, total)
+			}),
+		}
+	}
+
+	return &ExportDocument{
+		Name: fmt.Sprintf("sales-heatmap-%s-%s", heatmap.From, heatmap.To),
+		sheets: []exportSheet{
+			grid(exportLabel{"Pesanan", "Orders"}, exportNumber, func(cell structs.SalesHeatmapCell) int64 { return cell.Orders }),
+			grid(exportLabel{"Pendapatan", "Revenue"}, exportRupiah, func(cell structs.SalesHeatmapCell) int64 { return cell.Revenue }),
+		},
+	}, nil
+}
+
+func (es *ExportService) prepareDiscountReport(params map[string]string) (*ExportDocument, error) {
+	report, err := es.discountService.GetDiscountReport(params["from"], params["to"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportDocument{
+		Name: "discount-report-" + time.Now().In(config.Location()).Format("20060102150405"),
+		sheets: []exportSheet{{
+			title: exportLabel{"Diskon", "Discounts"},
+			columns: []exportColumn{
+				{exportLabel{"Diskon", "Discount"}, exportText},
+				{exportLabel{"Pesanan", "Orders"}, exportNumber},
+				{exportLabel{"Baris", "Lines"}, exportNumber},
+				{exportLabel{"Qty", "Quantity"}, exportNumber},
+				{exportLabel{"Nominal Diskon", "Discount Amount"}, exportRupiah},
+				{exportLabel{"Pendapatan Bersih", "Net Revenue"}, exportRupiah},
+			},
+			rows: exportRows(report.Discounts, func(row structs.DiscountReportRow) []interface{} {
+				return []interface{}{row.Name, row.Orders, row.Lines, row.Quantity, row.DiscountAmount, row.NetRevenue}
+			}),
+		}},
+	}, nil
+}
+
+func (es *ExportService) preparePricePeriodReport(params map[string]string) (*ExportDocument, error) {
+	productId, err := strconv.ParseUint(params["product_id"], 10, 32)
+	if err != nil || productId == 0 {
+		return nil, invalidError("invalid product ID")
+	}
+
+	report, err := es.priceService.GetPricePeriodReport(uint(productId), params["from"], params["to"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportDocument{
+		Name: fmt.Sprintf("price-periods-%d", report.ProductId),
+		sheets: []exportSheet{{
+			title: exportLabel{"Periode Harga", "Price Periods"},
+			columns: []exportColumn{
+				{exportLabel{"Harga", "Price"}, exportRupiah},
+				{exportLabel{"Berlaku Dari", "Effective From"}, exportText},
+				{exportLabel{"Berlaku Sampai", "Effective To"}, exportText},
+				{exportLabel{"Pesanan", "Orders"}, exportNumber},
+				{exportLabel{"Qty", "Quantity"}, exportNumber},
+				{exportLabel{"Pendapatan", "Revenue"}, exportRupiah},
+			},
+			rows: exportRows(report.Periods, func(row structs.PricePeriodRevenue) []interface{} {
+				return []interface{}{row.Price, row.EffectiveFrom, row.EffectiveTo, row.Orders, row.Quantity, row.Revenue}
+			}),
+		}},
+	}, nil
+}
+
+func (es *ExportService) prepareIngredientVariance(params map[string]string) (*ExportDocument, error) {
+	var countId uint64
+	if value := params["count_id"]; value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return nil, invalidError("invalid stock count ID")
+		}
+		countId = parsed
+	}
+
+	report, err := es.stockCountService.GetVarianceReport(uint(countId))
+	if err != nil {
+		return nil, err
+	}
+
+	return &ExportDocument{
+		Name: fmt.Sprintf("ingredient-variance-%d", report.StockCountId),
+		sheets: []exportSheet{{
+			title: exportLabel{"Selisih Bahan", "Ingredient Variance"},
+			columns: []exportColumn{
+				{exportLabel{"Bahan", "Ingredient"}, exportText},
+				{exportLabel{"Satuan", "Unit"}, exportText},
+				{exportLabel{"Awal", "Opening"}, exportDecimal},
+				{exportLabel{"Dibeli", "Purchased"}, exportDecimal},
+				{exportLabel{"Dipakai", "Used"}, exportDecimal},
+				{exportLabel{"Terbuang", "Wasted"}, exportDecimal},
+				{exportLabel{"Penyesuaian", "Adjusted"}, exportDecimal},
+				{exportLabel{"Teoretis", "Theoretical"}, exportDecimal},
+				{exportLabel{"Aktual", "Actual"}, exportDecimal},
+				{exportLabel{"Selisih", "Variance"}, exportDecimal},
+				{exportLabel{"Nilai Selisih", "Variance Cost"}, exportRupiah},
+			},
+			rows: exportRows(report.Items, func(row structs.IngredientVarianceItem) []interface{} {
+				return []interface{}{row.IngredientName, row.Unit, row.Opening, row.Purchased, row.Used, row.Wasted,
+					row.Adjusted, row.Theoretical, row.Actual, row.Variance, math.Round(row.VarianceCost)}
+			}),
+		}},
+	}, nil
+}
+
+// QueueExport stores an export to be written by the export worker
+func (es *ExportService) QueueExport(kind string, format string, language string, params map[string]string, createdBy string) (*models.ExportJob, error) {
+	encoded, err := json.Marshal(params)
+	if err != nil {
+		return nil, fmt.Errorf("failed to queue export: %v", err)
+	}
+
+	job := models.ExportJob{
+		Kind:      kind,
+		Format:    format,
+		Language:  language,
+		Params:    string(encoded),
+		Status:    models.ExportJobStatusPending,
+		CreatedBy: createdBy,
+	}
+	if err := es.db.Create(&job).Error; err != nil {
+		return nil, fmt.Errorf("failed to queue export: %v", err)
+	}
+
+	return &job, nil
+}
+
+// Get All Export Jobs, newest first, optionally filtered by status
+func (es *ExportService) GetExportJobs(status string) ([]models.ExportJob, error) {
+	query := es.db.Order("created_at DESC").Limit(100)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var jobs []models.ExportJob
+	err := query.Find(&jobs).Error
+
+	return jobs, err
+}
+
+// Get Export Job By Id
+func (es *ExportService) GetExportJobById(id uint) (*models.ExportJob, error) {
+	var job models.ExportJob
+	if err := es.db.First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("export not found")
+		}
+		return nil, fmt.Errorf("failed to find export: %v", err)
+	}
+
+	return &job, nil
+}
+
+// GetExportFile returns the path of a completed export file
+func (es *ExportService) GetExportFile(id uint) (*models.ExportJob, string, error) {
+	job, err := es.GetExportJobById(id)
+	if err != nil {
+		return nil, "", err
+	}
+	if job.Status != models.ExportJobStatusCompleted {
+		return nil, "", invalidError("invalid export, it is %s", job.Status)
+	}
+
+	return job, exportFilePath(job), nil
+}
+
+// ProcessExportJobs writes the pending exports one at a time and returns how many completed
+func (es *ExportService) ProcessExportJobs() (int, error) {
+	completed := 0
+	for {
+		job, err := es.claimExportJob()
+		if err != nil || job == nil {
+			return completed, err
+		}
+
+		rows, runErr := es.runExportJob(job)
+		now := time.Now()
+		updates := map[string]interface{}{"rows": rows, "completed_at": now}
+		if runErr == nil {
+			updates["status"] = models.ExportJobStatusCompleted
+			updates["expires_at"] = now.Add(exportRetention())
+			completed++
+		} else {
+			os.Remove(exportFilePath(job))
+			updates["status"] = models.ExportJobStatusFailed
+			updates["error"] = runErr.Error()
+		}
+
+		if err := es.db.Model(&models.ExportJob{}).Where("id = ?", job.Id).Updates(updates).Error; err != nil {
+			fmt.Printf("Failed to update export job %d: %v\n", job.Id, err)
+			continue
+		}
+		es.notifyExport(job, runErr)
+	}
+}
+
+// StartExportWorker writes the queued exports and removes expired files at each interval, it blocks so start it
+// in its own goroutine
+func (es *ExportService) StartExportWorker(interval time.Duration) {
+	// Jobs left running by a previous run never finished their file, write them again
+	es.db.Model(&models.ExportJob{}).Where("status = ?", models.ExportJobStatusRunning).
+		Update("status", models.ExportJobStatusPending)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := es.ProcessExportJobs(); err != nil {
+			fmt.Printf("Failed to process export jobs: %v\n", err)
+		}
+		if err := es.removeExpiredExports(); err != nil {
+			fmt.Printf("Failed to remove expired exports: %v\n", err)
+		}
+	}
+}
+
+// claimExportJob marks the oldest pending job as running so a second worker does not write it too
+func (es *ExportService) claimExportJob() (*models.ExportJob, error) {
+	var jobs []models.ExportJob
+	err := es.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", models.ExportJobStatusPending).
+			Order("id ASC").Limit(1).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		return tx.Model(&jobs[0]).Update("status", models.ExportJobStatusRunning).Error
+	})
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	return &jobs[0], nil
+}
+
+func (es *ExportService) runExportJob(job *models.ExportJob) (int64, error) {
+	var params map[string]string
+	if err := json.Unmarshal([]byte(job.Params), &params); err != nil {
+		return 0, fmt.Errorf("failed to read export params: %v", err)
+	}
+
+	doc, err := es.Prepare(job.Kind, job.Language, params)
+	if err != nil {
+		return 0, err
+	}
+
+	job.FileName = doc.Name + "." + job.Format
+	if err := es.db.Model(&models.ExportJob{}).Where("id = ?", job.Id).Update("file_name", job.FileName).Error; err != nil {
+		return 0, fmt.Errorf("failed to update export: %v", err)
+	}
+
+	if err := os.MkdirAll(exportDir, 0755); err != nil {
+		return 0, fmt.Errorf("failed to create export directory: %v", err)
+	}
+	file, err := os.Create(exportFilePath(job))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create export file: %v", err)
+	}
+
+	rows, err := doc.Write(job.Format, file)
+	if closeErr := file.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("failed to save export file: %v", closeErr)
+	}
+
+	return rows, err
+}
+
+func (es *ExportService) removeExpiredExports() error {
+	var jobs []models.ExportJob
+	if err := es.db.Where("status = ? AND expires_at <= ?", models.ExportJobStatusCompleted, time.Now()).
+		Find(&jobs).Error; err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		if err := os.Remove(exportFilePath(&job)); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Failed to remove export file %s: %v\n", exportFilePath(&job), err)
+			continue
+		}
+		es.db.Model(&models.ExportJob{}).Where("id = ?", job.Id).Update("status", models.ExportJobStatusExpired)
+	}
+
+	return nil
+}
+
+func (es *ExportService) notifyExport(job *models.ExportJob, runErr error) {
+	title := "Ekspor Siap"
+	message := fmt.Sprintf("Ekspor %s siap diunduh", job.FileName)
+	notificationType := "export_ready"
+	data := map[string]interface{}{
+		"export_job_id": job.Id,
+		"kind":          job.Kind,
+		"format":        job.Format,
+	}
+	if runErr != nil {
+		title = "Ekspor Gagal"
+		message = fmt.Sprintf("Ekspor %s gagal: %v", job.Kind, runErr)
+		notificationType = "export_failed"
+	} else {
+		data["download_url"] = ExportDownloadUrl(job.Id)
+	}
+
+	if err := es.notificationService.BroadcastToAdmins(notificationType, title, message, data); err != nil {
+		fmt.Printf("Failed to broadcast notification: %v\n", err)
+	}
+}
+
+// ExportDownloadUrl is the API path a completed export is downloaded from
+func ExportDownloadUrl(id uint) string {
+	return fmt.Sprintf("/api/exports/%d/download", id)
+}
+
+// exportFilePath is where the file of a job is kept, prefixed with its id as names repeat
+func exportFilePath(job *models.ExportJob) string {
+	return filepath.Join(exportDir, fmt.Sprintf("%d-%s", job.Id, job.FileName))
+}
+
+// exportSyncMaxRows is how many rows an export may have to be streamed in the request, EXPORT_SYNC_MAX_ROWS
+// defaults to 5000
+func exportSyncMaxRows() int64 {
+	rows, err := strconv.ParseInt(config.GetEnv("EXPORT_SYNC_MAX_ROWS", "5000"), 10, 64)
+	if err != nil || rows < 0 {
+		return 5000
+	}
+
+	return rows
+}
+
+// exportRetention is how long a background export can be downloaded, EXPORT_RETENTION_HOURS defaults to 24
+func exportRetention() time.Duration {
+	hours, err := strconv.Atoi(config.GetEnv("EXPORT_RETENTION_HOURS", "24"))
+	if err != nil || hours < 1 {
+		return 24 * time.Hour
+	}
+
+	return time.Duration(hours) * time.Hour
+}
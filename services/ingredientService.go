@@ -0,0 +1,483 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IngredientService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+func NewIngredientService(db *gorm.DB, notificationService *NotificationService) *IngredientService {
+	return &IngredientService{
+		db:                  db,
+		notificationService: notificationService,
+	}
+}
+
+type ingredientAlert struct {
+	IngredientId uint    `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Unit         string  `json:"unit"`
+	Stock        float64 `json:"stock"`
+	Level        float64 `json:"low_stock_level"`
+}
+
+// Get All Units
+func (ig *IngredientService) GetUnits() ([]models.Unit, error) {
+	var units []models.Unit
+	err := ig.db.Order("dimension ASC, factor ASC").Find(&units).Error
+
+	return units, err
+}
+
+// Create Unit
+func (ig *IngredientService) CreateUnit(req *structs.UnitCreateRequest) (*models.Unit, error) {
+	unit := models.Unit{
+		Symbol:    strings.TrimSpace(req.Symbol),
+		Name:      strings.TrimSpace(req.Name),
+		Dimension: req.Dimension,
+		Factor:    req.Factor,
+	}
+
+	var count int64
+	ig.db.Model(&models.Unit{}).Where("symbol = ?", unit.Symbol).Count(&count)
+	if count > 0 {
+		return nil, invalidError("unit %s already exists", unit.Symbol)
+	}
+
+	if err := ig.db.Create(&unit).Error; err != nil {
+		return nil, fmt.Errorf("failed to create unit: %v", err)
+	}
+
+	return &unit, nil
+}
+
+// Get All Ingredients
+func (ig *IngredientService) GetIngredients(lowStockOnly bool) ([]models.Ingredient, error) {
+	query := ig.db.Preload("Unit").Order("name ASC")
+	if lowStockOnly {
+		query = query.Where("is_active = ? AND stock_quantity <= low_stock_level", true)
+	}
+
+	var ingredients []models.Ingredient
+	err := query.Find(&ingredients).Error
+
+	return ingredients, err
+}
+
+// Get Ingredient By Id
+func (ig *IngredientService) GetIngredientById(id uint) (*models.Ingredient, error) {
+	var ingredient models.Ingredient
+	if err := ig.db.Preload("Unit").First(&ingredient, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("ingredient not found")
+		}
+		return nil, fmt.Errorf("failed to find ingredient: %v", err)
+	}
+
+	return &ingredient, nil
+}
+
+// Create Ingredient, stock starts at zero and comes in through purchase orders or stock counts
+func (ig *IngredientService) CreateIngredient(req *structs.IngredientRequest) (*models.Ingredient, error) {
+	if err := ig.validateIngredientRequest(0, req); err != nil {
+		return nil, err
+	}
+
+	ingredient := models.Ingredient{
+		Name:          strings.TrimSpace(req.Name),
+		UnitId:        req.UnitId,
+		LowStockLevel: req.LowStockLevel,
+		CostPerUnit:   req.CostPerUnit,
+		IsActive:      req.IsActive == nil || *req.IsActive,
+	}
+
+	if err := ig.db.Create(&ingredient).Error; err != nil {
+		return nil, fmt.Errorf("failed to create ingredient: %v", err)
+	}
+
+	return ig.GetIngredientById(ingredient.Id)
+}
+
+// Update Ingredient. The unit can only change within the same dimension, the stock and the recorded movements,
+// stock counts and purchase orders are converted along so reports keep adding up quantities of one unit. An
+// unchanged cost_per_unit is converted too, a new one is taken to be per the new unit.
+func (ig *IngredientService) UpdateIngredient(id uint, req *structs.IngredientRequest) (*models.Ingredient, error) {
+	ingredient, err := ig.GetIngredientById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := ig.validateIngredientRequest(id, req); err != nil {
+		return nil, err
+	}
+
+	updates := map[string]interface{}{
+		"name":            strings.TrimSpace(req.Name),
+		"low_stock_level": req.LowStockLevel,
+		"cost_per_unit":   req.CostPerUnit,
+	}
+	if req.IsActive != nil {
+		updates["is_active"] = *req.IsActive
+	}
+
+	err = ig.db.Transaction(func(tx *gorm.DB) error {
+		if req.UnitId != ingredient.UnitId {
+			var unit models.Unit
+			if err := tx.First(&unit, req.UnitId).Error; err != nil {
+				return err
+			}
+			if unit.Dimension != ingredient.Unit.Dimension {
+				return invalidError("invalid unit, %s cannot be converted to %s", ingredient.Unit.Symbol, unit.Symbol)
+			}
+
+			var locked models.Ingredient
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&locked, id).Error; err != nil {
+				return err
+			}
+
+			updates["unit_id"] = unit.Id
+			updates["stock_quantity"] = roundQuantity(ingredient.Unit.Convert(locked.StockQuantity, unit))
+			if req.CostPerUnit == locked.CostPerUnit {
+				updates["cost_per_unit"] = math.Round(unit.Convert(locked.CostPerUnit, ingredient.Unit)*100) / 100
+			}
+
+			if err := convertIngredientHistory(tx, id, ingredient.Unit, unit); err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Ingredient{}).Where("id = ?", id).Updates(updates).Error
+	})
+	if err != nil {
+		var serviceErr *serviceError
+		if errors.As(err, &serviceErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to update ingredient: %v", err)
+	}
+
+	return ig.GetIngredientById(id)
+}
+
+// convertIngredientHistory rewrites the quantities recorded for an ingredient from one unit to another
+func convertIngredientHistory(tx *gorm.DB, ingredientId uint, from models.Unit, to models.Unit) error {
+	if from.Id == to.Id || from.Factor == 0 || to.Factor == 0 {
+		return nil
+	}
+
+	quantity := func(column string) clause.Expr {
+		return gorm.Expr("ROUND(("+column+" * ? / ?)::numeric, 3)", from.Factor, to.Factor)
+	}
+
+	if err := tx.Model(&models.IngredientMovement{}).Where("ingredient_id = ?", ingredientId).
+		UpdateColumns(map[string]interface{}{
+			"quantity":    quantity("quantity"),
+			"stock_after": quantity("stock_after"),
+		}).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(&models.StockCountItem{}).Where("ingredient_id = ?", ingredientId).
+		UpdateColumns(map[string]interface{}{
+			"expected_quantity": quantity("expected_quantity"),
+			"counted_quantity":  quantity("counted_quantity"),
+		}).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.PurchaseOrderItem{}).Where("ingredient_id = ?", ingredientId).
+		UpdateColumns(map[string]interface{}{
+			"quantity":          quantity("quantity"),
+			"received_quantity": quantity("received_quantity"),
+			"unit_cost":         gorm.Expr("ROUND((unit_cost * ? / ?)::numeric, 2)", to.Factor, from.Factor),
+		}).Error
+}
+
+// Delete Ingredient when no recipe uses it
+func (ig *IngredientService) DeleteIngredient(id uint) error {
+	ingredient, err := ig.GetIngredientById(id)
+	if err != nil {
+		return err
+	}
+
+	var usage int64
+	ig.db.Model(&models.RecipeItem{}).Where("ingredient_id = ?", id).Count(&usage)
+	if usage > 0 {
+		return invalidError("ingredient %s is in use by %d recipe item(s)", ingredient.Name, usage)
+	}
+
+	if err := ig.db.Delete(ingredient).Error; err != nil {
+		return fmt.Errorf("failed to delete ingredient: %v", err)
+	}
+
+	return nil
+}
+
+// Record waste or a manual adjustment of an ingredient
+func (ig *IngredientService) RecordMovement(id uint, req *structs.IngredientMovementRequest, createdBy string) (*models.IngredientMovement, error) {
+	change := req.Quantity
+	if req.Type == models.IngredientMovementWaste {
+		if req.Quantity < 0 {
+			return nil, invalidError("invalid quantity, waste must be positive")
+		}
+		change = -req.Quantity
+	}
+
+	var movement *models.IngredientMovement
+	var alert *ingredientAlert
+
+	err := ig.db.Transaction(func(tx *gorm.DB) error {
+		var ingredient models.Ingredient
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Unit").First(&ingredient, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return notFoundError("ingredient not found")
+			}
+			return err
+		}
+
+		var err error
+		movement, alert, err = applyIngredientChange(tx, &ingredient, change, models.IngredientMovement{
+			Type:      req.Type,
+			Notes:     req.Notes,
+			CreatedBy: createdBy,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if alert != nil {
+		ig.notifyLowIngredients([]ingredientAlert{*alert})
+	}
+
+	return movement, nil
+}
+
+// Get movements of an ingredient, newest first
+func (ig *IngredientService) GetMovements(id uint, limit int) ([]models.IngredientMovement, error) {
+	if _, err := ig.GetIngredientById(id); err != nil {
+		return nil, err
+	}
+
+	var movements []models.IngredientMovement
+	if err := ig.db.Where("ingredient_id = ?", id).Order("created_at DESC, id DESC").Limit(limit).Find(&movements).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch ingredient movements: %v", err)
+	}
+
+	return movements, nil
+}
+
+// Get the recipe of a product
+func (ig *IngredientService) GetRecipe(productId uint) ([]models.RecipeItem, error) {
+	if err := ig.db.First(&models.Product{}, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	var items []models.RecipeItem
+	err := ig.db.Preload("Ingredient.Unit").Preload("Unit").
+		Where("product_id = ?", productId).Order("id ASC").
+		Find(&items).Error
+
+	return items, err
+}
+
+// Set the recipe of a product, replacing the previous one. Items without a unit use the ingredient unit.
+func (ig *IngredientService) SetRecipe(productId uint, req *structs.RecipeRequest) ([]models.RecipeItem, error) {
+	if err := ig.db.First(&models.Product{}, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	var items []models.RecipeItem
+	seen := make(map[uint]bool)
+	for _, item := range req.Items {
+		if seen[item.IngredientId] {
+			return nil, invalidError("invalid recipe, ingredient %d is listed more than once", item.IngredientId)
+		}
+		seen[item.IngredientId] = true
+
+		ingredient, err := ig.GetIngredientById(item.IngredientId)
+		if err != nil {
+			return nil, invalidError("invalid recipe, ingredient %d not found", item.IngredientId)
+		}
+
+		unitId := item.UnitId
+		if unitId == 0 {
+			unitId = ingredient.UnitId
+		}
+		if unitId != ingredient.UnitId {
+			var unit models.Unit
+			if err := ig.db.First(&unit, unitId).Error; err != nil {
+				return nil, invalidError("invalid recipe, unit %d not found", unitId)
+			}
+			if unit.Dimension != ingredient.Unit.Dimension {
+				return nil, invalidError("invalid recipe, %s cannot be measured in %s", ingredient.Name, unit.Symbol)
+			}
+		}
+
+		items = append(items, models.RecipeItem{
+			ProductId:    productId,
+			IngredientId: ingredient.Id,
+			Quantity:     item.Quantity,
+			UnitId:       unitId,
+		})
+	}
+
+	err := ig.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("product_id = ?", productId).Delete(&models.RecipeItem{}).Error; err != nil {
+			return err
+		}
+		if len(items) == 0 {
+			return nil
+		}
+		return tx.Create(&items).Error
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update recipe: %v", err)
+	}
+
+	return ig.GetRecipe(productId)
+}
+
+// depleteIngredients books the theoretical ingredient usage of a paid transaction from the recipes of its lines
+func (ig *IngredientService) depleteIngredients(tx *gorm.DB, transaction *models.Transaction) ([]ingredientAlert, error) {
+	var details []models.TransactionDetail
+	if err := tx.Where("transaction_id = ? AND voided_at IS NULL", transaction.Id).Find(&details).Error; err != nil {
+		return nil, fmt.Errorf("failed to load transaction details: %v", err)
+	}
+
+	sold := make(map[uint]float64)
+	var productIds []uint
+	for _, detail := range details {
+		if _, ok := sold[detail.ProductId]; !ok {
+			productIds = append(productIds, detail.ProductId)
+		}
+		sold[detail.ProductId] += float64(detail.Quantity)
+	}
+	if len(productIds) == 0 {
+		return nil, nil
+	}
+
+	var recipe []models.RecipeItem
+	if err := tx.Preload("Unit").Preload("Ingredient.Unit").Where("product_id IN ?", productIds).Find(&recipe).Error; err != nil {
+		return nil, fmt.Errorf("failed to load recipes: %v", err)
+	}
+
+	usage := make(map[uint]float64)
+	for _, item := range recipe {
+		usage[item.IngredientId] += item.Unit.Convert(item.Quantity, item.Ingredient.Unit) * sold[item.ProductId]
+	}
+
+	ingredientIds := make([]int, 0, len(usage))
+	for id := range usage {
+		ingredientIds = append(ingredientIds, int(id))
+	}
+	sort.Ints(ingredientIds)
+
+	var alerts []ingredientAlert
+	transactionId := transaction.Id
+	for _, id := range ingredientIds {
+		var ingredient models.Ingredient
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Unit").First(&ingredient, id).Error; err != nil {
+			return nil, fmt.Errorf("failed to lock ingredient %d: %v", id, err)
+		}
+
+		_, alert, err := applyIngredientChange(tx, &ingredient, -usage[uint(id)], models.IngredientMovement{
+			Type:          models.IngredientMovementSale,
+			TransactionId: &transactionId,
+			Reference:     transaction.OrderNumber,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if alert != nil {
+			alerts = append(alerts, *alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+// notifyLowIngredients tells the admins about ingredients that reached their low stock level
+func (ig *IngredientService) notifyLowIngredients(alerts []ingredientAlert) {
+	for _, alert := range alerts {
+		title := "Stok Bahan Menipis"
+		message := fmt.Sprintf("Stok %s tinggal %s %s", alert.Name, formatQuantity(alert.Stock), alert.Unit)
+		if alert.Stock <= 0 {
+			title = "Stok Bahan Habis"
+			message = fmt.Sprintf("Stok %s habis", alert.Name)
+		}
+
+		if err := ig.notificationService.BroadcastToAdmins("low_ingredient", title, message, alert); err != nil {
+			fmt.Printf("Failed to broadcast notification: %v\n", err)
+		}
+	}
+}
+
+func (ig *IngredientService) validateIngredientRequest(id uint, req *structs.IngredientRequest) error {
+	if err := ig.db.First(&models.Unit{}, req.UnitId).Error; err != nil {
+		return invalidError("invalid unit, unit %d not found", req.UnitId)
+	}
+
+	var count int64
+	ig.db.Model(&models.Ingredient{}).Where("LOWER(name) = LOWER(?) AND id <> ?", strings.TrimSpace(req.Name), id).Count(&count)
+	if count > 0 {
+		return invalidError("ingredient %s already exists", req.Name)
+	}
+
+	return nil
+}
+
+// applyIngredientChange changes the stock of a locked ingredient and writes the ledger entry. Sales may take stock
+// below zero since recipes are theoretical, the next stock count corrects it.
+func applyIngredientChange(tx *gorm.DB, ingredient *models.Ingredient, change float64, movement models.IngredientMovement) (*models.IngredientMovement, *ingredientAlert, error) {
+	change = roundQuantity(change)
+	stockAfter := roundQuantity(ingredient.StockQuantity + change)
+
+	if err := tx.Model(&models.Ingredient{}).Where("id = ?", ingredient.Id).Update("stock_quantity", stockAfter).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to update ingredient stock: %v", err)
+	}
+
+	movement.IngredientId = ingredient.Id
+	movement.Quantity = change
+	movement.StockAfter = stockAfter
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to record ingredient movement: %v", err)
+	}
+
+	var alert *ingredientAlert
+	threshold := math.Max(ingredient.LowStockLevel, 0)
+	if ingredient.IsActive && change < 0 && ingredient.StockQuantity > threshold && stockAfter <= threshold {
+		alert = &ingredientAlert{
+			IngredientId: ingredient.Id,
+			Name:         ingredient.Name,
+			Unit:         ingredient.Unit.Symbol,
+			Stock:        stockAfter,
+			Level:        ingredient.LowStockLevel,
+		}
+	}
+
+	ingredient.StockQuantity = stockAfter
+
+	return &movement, alert, nil
+}
+
+// roundQuantity keeps quantities at the three decimals stored in the database
+func roundQuantity(quantity float64) float64 {
+	return math.Round(quantity*1000) / 1000
+}
+
+func formatQuantity(quantity float64) string {
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.3f", quantity), "0"), ".")
+}
@@ -0,0 +1,567 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"sort"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InventoryService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+func NewInventoryService(db *gorm.DB, notificationService *NotificationService) *InventoryService {
+	return &InventoryService{
+		db:                  db,
+		notificationService: notificationService,
+	}
+}
+
+// stockKey identifies the row holding the stock of an order line, the variant when it tracks stock, otherwise the product
+type stockKey struct {
+	productId uint
+	variantId uint
+}
+
+func (k stockKey) source() string {
+	if k.variantId != 0 {
+		return models.StockSourceVariant
+	}
+	return models.StockSourceProduct
+}
+
+// stockItem is a locked stock row of either a product or a variant
+type stockItem struct {
+	key         stockKey
+	name        string
+	stock       int
+	reserved    int
+	lowLevel    int
+	isAvailable bool
+}
+
+type lowStockAlert struct {
+	ProductId uint   `json:"product_id"`
+	VariantId *uint  `json:"variant_id"`
+	Name      string `json:"name"`
+	Stock     int    `json:"stock"`
+	Level     int    `json:"low_stock_level"`
+}
+
+// Get stock of every product and variant that tracks stock
+func (is *InventoryService) GetInventory(lowStockOnly bool) ([]structs.InventoryItemResponse, error) {
+	var products []models.Product
+	if err := is.db.Where("track_stock = ?", true).Order("name ASC").Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch inventory: %v", err)
+	}
+
+	var variants []models.ProductVariant
+	if err := is.db.Where("track_stock = ?", true).Order("product_id ASC, id ASC").Find(&variants).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch inventory: %v", err)
+	}
+
+	productNames := make(map[uint]string)
+	if len(variants) > 0 {
+		var owners []models.Product
+		productIds := make([]uint, 0, len(variants))
+		for _, variant := range variants {
+			productIds = append(productIds, variant.ProductId)
+		}
+		is.db.Select("id", "name").Where("id IN ?", uniqueIds(productIds)).Find(&owners)
+		for _, owner := range owners {
+			productNames[owner.Id] = owner.Name
+		}
+	}
+
+	items := make([]structs.InventoryItemResponse, 0, len(products)+len(variants))
+	for _, product := range products {
+		items = append(items, toInventoryItemResponse(product.Id, nil, product.Name,
+			product.StockQuantity, product.ReservedStock, product.LowStockLevel, product.IsAvailable))
+	}
+	for _, variant := range variants {
+		variantId := variant.Id
+		items = append(items, toInventoryItemResponse(variant.ProductId, &variantId, productNames[variant.ProductId]+" - "+variant.Name,
+			variant.StockQuantity, variant.ReservedStock, variant.LowStockLevel, variant.IsAvailable))
+	}
+
+	if lowStockOnly {
+		filtered := items[:0]
+		for _, item := range items {
+			if item.IsLowStock {
+				filtered = append(filtered, item)
+			}
+		}
+		items = filtered
+	}
+
+	return items, nil
+}
+
+// Update stock tracking settings of a product or one of its variants
+func (is *InventoryService) UpdateStockSettings(productId uint, req *structs.StockSettingsRequest) error {
+	var product models.Product
+	if err := is.db.First(&product, productId).Error; err != nil {
+		return notFoundError("product not found")
+	}
+
+	if product.Type == models.ProductTypeBundle && req.TrackStock != nil && *req.TrackStock {
+		return invalidError("invalid stock settings, bundle stock is tracked on its components")
+	}
+
+	updates := make(map[string]interface{})
+	if req.TrackStock != nil {
+		updates["track_stock"] = *req.TrackStock
+	}
+	if req.LowStockLevel != nil {
+		updates["low_stock_level"] = *req.LowStockLevel
+	}
+	if len(updates) == 0 {
+		return nil
+	}
+
+	query := is.db.Model(&models.Product{}).Where("id = ?", productId)
+	if req.VariantId != nil {
+		query = is.db.Model(&models.ProductVariant{}).Where("id = ? AND product_id = ?", *req.VariantId, productId)
+	}
+
+	result := query.Updates(updates)
+	if result.Error != nil {
+		return fmt.Errorf("failed to update stock settings: %v", result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return notFoundError("variant not found")
+	}
+
+	return nil
+}
+
+// Record a manual restock, waste or adjustment movement
+func (is *InventoryService) RecordMovement(req *structs.StockMovementRequest, createdBy string) (*models.StockMovement, error) {
+	var change int
+	switch req.Type {
+	case models.StockMovementRestock:
+		change = req.Quantity
+	case models.StockMovementWaste:
+		change = -req.Quantity
+	case models.StockMovementAdjustment:
+		change = req.Quantity
+	default:
+		return nil, invalidError("invalid movement type: %s", req.Type)
+	}
+
+	if change == 0 || (req.Type != models.StockMovementAdjustment && req.Quantity < 0) {
+		return nil, invalidError("invalid quantity for this movement type")
+	}
+
+	key := stockKey{productId: req.ProductId}
+	if req.VariantId != nil {
+		key.variantId = *req.VariantId
+	}
+
+	var movement *models.StockMovement
+	var alerts []lowStockAlert
+
+	err := is.db.Transaction(func(tx *gorm.DB) error {
+		item, err := lockStockItem(tx, key, true)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			return invalidError("invalid stock item, stock tracking is disabled for this product")
+		}
+
+		if item.stock+change < 0 {
+			return invalidError("invalid quantity, only %d in stock", item.stock)
+		}
+		// Waste only comes out of what open orders do not hold, or paying them would fail. A counted adjustment
+		// is the shelf itself and may go below the reservations.
+		if req.Type == models.StockMovementWaste && item.stock-item.reserved+change < 0 {
+			return invalidError("invalid quantity, only %d in stock is not reserved by open orders", max(item.stock-item.reserved, 0))
+		}
+
+		var alert *lowStockAlert
+		movement, alert, err = applyStockChange(tx, item, change, 0, models.StockMovement{
+			Type:      req.Type,
+			Notes:     req.Notes,
+			CreatedBy: createdBy,
+		})
+		if err != nil {
+			return err
+		}
+		if alert != nil {
+			alerts = append(alerts, *alert)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	is.notifyLowStock(alerts)
+
+	return movement, nil
+}
+
+// Get stock movements, newest first
+func (is *InventoryService) GetMovements(productId uint, variantId uint, movementType string, limit int) ([]models.StockMovement, error) {
+	query := is.db.Order("created_at DESC, id DESC").Limit(limit)
+	if productId != 0 {
+		query = query.Where("product_id = ?", productId)
+	}
+	if variantId != 0 {
+		query = query.Where("variant_id = ?", variantId)
+	}
+	if movementType != "" {
+		query = query.Where("type = ?", movementType)
+	}
+
+	var movements []models.StockMovement
+	if err := query.Find(&movements).Error; err != nil {
+		return nil, fmt.Errorf("failed to fetch stock movements: %v", err)
+	}
+
+	return movements, nil
+}
+
+// reserveStock holds stock for the order lines of a new transaction. Rows are locked in a fixed order
+// so concurrent checkouts wait for each other instead of overselling or deadlocking.
+func (is *InventoryService) reserveStock(tx *gorm.DB, lines []*models.TransactionDetail) error {
+	needs := make(map[stockKey]int)
+	linesByKey := make(map[stockKey][]*models.TransactionDetail)
+
+	for _, line := range lines {
+		key, tracked, err := resolveStockKey(tx, line)
+		if err != nil {
+			return err
+		}
+		if !tracked {
+			continue
+		}
+		needs[key] += int(line.Quantity)
+		linesByKey[key] = append(linesByKey[key], line)
+	}
+
+	for _, key := range sortedStockKeys(needs) {
+		item, err := lockStockItem(tx, key, true)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			continue
+		}
+
+		if available := item.stock - item.reserved; available < needs[key] {
+			if available <= 0 {
+				return invalidError("insufficient stock for %s, sold out", item.name)
+			}
+			return invalidError("insufficient stock for %s, only %d left", item.name, available)
+		}
+
+		if err := updateStockItem(tx, key, map[string]interface{}{
+			"reserved_stock": gorm.Expr("reserved_stock + ?", needs[key]),
+		}); err != nil {
+			return err
+		}
+
+		for _, line := range linesByKey[key] {
+			line.StockSource = key.source()
+		}
+	}
+
+	return nil
+}
+
+// consumeReservedStock turns the reservations of a paid transaction into sale movements
+func (is *InventoryService) consumeReservedStock(tx *gorm.DB, transaction *models.Transaction) ([]lowStockAlert, error) {
+	reserved, err := reservedStockOf(tx, transaction.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []lowStockAlert
+	for _, key := range sortedStockKeys(reserved) {
+		item, err := lockStockItem(tx, key, false)
+		if err != nil {
+			return nil, err
+		}
+		if item == nil {
+			continue
+		}
+
+		transactionId := transaction.Id
+		_, alert, err := applyStockChange(tx, item, -reserved[key], reserved[key], models.StockMovement{
+			Type:          models.StockMovementSale,
+			TransactionId: &transactionId,
+			Reference:     transaction.OrderNumber,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if alert != nil {
+			alerts = append(alerts, *alert)
+		}
+	}
+
+	return alerts, nil
+}
+
+// releaseReservedStock gives back the stock held by a transaction that will not be paid
+func (is *InventoryService) releaseReservedStock(tx *gorm.DB, transaction *models.Transaction) error {
+	reserved, err := reservedStockOf(tx, transaction.Id)
+	if err != nil {
+		return err
+	}
+
+	return releaseStock(tx, reserved)
+}
+
+// releaseLineStock gives back the stock held by order lines voided from a pending transaction
+func (is *InventoryService) releaseLineStock(tx *gorm.DB, lines []models.TransactionDetail) error {
+	return releaseStock(tx, reservedStockOfLines(lines))
+}
+
+func releaseStock(tx *gorm.DB, reserved map[stockKey]int) error {
+	for _, key := range sortedStockKeys(reserved) {
+		item, err := lockStockItem(tx, key, false)
+		if err != nil {
+			return err
+		}
+		if item == nil {
+			continue
+		}
+
+		if err := updateStockItem(tx, key, map[string]interface{}{
+			"reserved_stock": max(item.reserved-reserved[key], 0),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// notifyLowStock tells the admins about items that reached their low stock level or sold out
+func (is *InventoryService) notifyLowStock(alerts []lowStockAlert) {
+	for _, alert := range alerts {
+		title := "Stok Menipis"
+		message := fmt.Sprintf("Stok %s tinggal %d", alert.Name, alert.Stock)
+		if alert.Stock <= 0 {
+			title = "Stok Habis"
+			message = fmt.Sprintf("Stok %s habis, produk ditandai tidak tersedia", alert.Name)
+		}
+
+		if err := is.notificationService.BroadcastToAdmins("low_stock", title, message, alert); err != nil {
+			fmt.Printf("Failed to broadcast notification: %v\n", err)
+		}
+	}
+}
+
+// resolveStockKey finds where the stock of an order line is kept. Bundle lines are skipped, their components carry the stock.
+func resolveStockKey(tx *gorm.DB, line *models.TransactionDetail) (stockKey, bool, error) {
+	var product models.Product
+	if err := tx.Select("id", "type", "track_stock").First(&product, line.ProductId).Error; err != nil {
+		return stockKey{}, false, invalidError("product not found: %d", line.ProductId)
+	}
+
+	if product.Type == models.ProductTypeBundle {
+		return stockKey{}, false, nil
+	}
+
+	if line.VariantId != nil {
+		var variant models.ProductVariant
+		if err := tx.Select("id", "track_stock").First(&variant, *line.VariantId).Error; err != nil {
+			return stockKey{}, false, invalidError("variant not found: %d", *line.VariantId)
+		}
+		if variant.TrackStock {
+			return stockKey{productId: product.Id, variantId: variant.Id}, true, nil
+		}
+	}
+
+	return stockKey{productId: product.Id}, product.TrackStock, nil
+}
+
+// reservedStockOf sums the reserved quantities of a transaction per stock row
+func reservedStockOf(tx *gorm.DB, transactionId uint) (map[stockKey]int, error) {
+	var details []models.TransactionDetail
+	if err := tx.Where("transaction_id = ? AND voided_at IS NULL AND stock_source IN ?", transactionId,
+		[]string{models.StockSourceProduct, models.StockSourceVariant}).Find(&details).Error; err != nil {
+		return nil, fmt.Errorf("failed to load reserved stock: %v", err)
+	}
+
+	return reservedStockOfLines(details), nil
+}
+
+// reservedStockOfLines sums the quantities the given order lines reserved per stock row
+func reservedStockOfLines(details []models.TransactionDetail) map[stockKey]int {
+	reserved := make(map[stockKey]int)
+	for _, detail := range details {
+		if detail.StockSource != models.StockSourceProduct && detail.StockSource != models.StockSourceVariant {
+			continue
+		}
+		key := stockKey{productId: detail.ProductId}
+		if detail.StockSource == models.StockSourceVariant && detail.VariantId != nil {
+			key.variantId = *detail.VariantId
+		}
+		reserved[key] += int(detail.Quantity)
+	}
+
+	return reserved
+}
+
+// lockStockItem loads a stock row with SELECT ... FOR UPDATE. With trackedOnly it returns nil for rows that do not track stock.
+func lockStockItem(tx *gorm.DB, key stockKey, trackedOnly bool) (*stockItem, error) {
+	locked := tx.Clauses(clause.Locking{Strength: "UPDATE"})
+
+	if key.variantId != 0 {
+		var variant models.ProductVariant
+		query := locked.Where("id = ? AND product_id = ?", key.variantId, key.productId)
+		if trackedOnly {
+			query = query.Where("track_stock = ?", true)
+		}
+		if err := query.First(&variant).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				if trackedOnly {
+					return nil, nil
+				}
+				return nil, notFoundError("variant not found: %d", key.variantId)
+			}
+			return nil, fmt.Errorf("failed to lock stock: %v", err)
+		}
+
+		var product models.Product
+		tx.Select("id", "name").First(&product, key.productId)
+
+		return &stockItem{
+			key:         key,
+			name:        product.Name + " - " + variant.Name,
+			stock:       variant.StockQuantity,
+			reserved:    variant.ReservedStock,
+			lowLevel:    variant.LowStockLevel,
+			isAvailable: variant.IsAvailable,
+		}, nil
+	}
+
+	var product models.Product
+	query := locked.Where("id = ?", key.productId)
+	if trackedOnly {
+		query = query.Where("track_stock = ?", true)
+	}
+	if err := query.First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			if trackedOnly {
+				return nil, nil
+			}
+			return nil, notFoundError("product not found: %d", key.productId)
+		}
+		return nil, fmt.Errorf("failed to lock stock: %v", err)
+	}
+
+	return &stockItem{
+		key:         key,
+		name:        product.Name,
+		stock:       product.StockQuantity,
+		reserved:    product.ReservedStock,
+		lowLevel:    product.LowStockLevel,
+		isAvailable: product.IsAvailable,
+	}, nil
+}
+
+// applyStockChange changes the on hand stock of a locked row, releases the given reservation, writes the ledger entry
+// and flips availability. Items that sell out become unavailable, any change that brings a sold out item back into
+// stock makes it available again.
+func applyStockChange(tx *gorm.DB, item *stockItem, change int, release int, movement models.StockMovement) (*models.StockMovement, *lowStockAlert, error) {
+	stockAfter := item.stock + change
+	updates := map[string]interface{}{
+		"stock_quantity": stockAfter,
+	}
+	if release > 0 {
+		updates["reserved_stock"] = max(item.reserved-release, 0)
+	}
+	if stockAfter <= 0 && item.isAvailable {
+		updates["is_available"] = false
+	}
+	if item.stock <= 0 && stockAfter > 0 && !item.isAvailable {
+		updates["is_available"] = true
+	}
+
+	if err := updateStockItem(tx, item.key, updates); err != nil {
+		return nil, nil, err
+	}
+
+	movement.ProductId = item.key.productId
+	movement.Quantity = change
+	movement.StockAfter = stockAfter
+	if item.key.variantId != 0 {
+		variantId := item.key.variantId
+		movement.VariantId = &variantId
+	}
+	if err := tx.Create(&movement).Error; err != nil {
+		return nil, nil, fmt.Errorf("failed to record stock movement: %v", err)
+	}
+
+	var alert *lowStockAlert
+	threshold := max(item.lowLevel, 0)
+	if change < 0 && item.stock > threshold && stockAfter <= threshold {
+		alert = &lowStockAlert{
+			ProductId: item.key.productId,
+			VariantId: movement.VariantId,
+			Name:      item.name,
+			Stock:     stockAfter,
+			Level:     item.lowLevel,
+		}
+	}
+
+	return &movement, alert, nil
+}
+
+func updateStockItem(tx *gorm.DB, key stockKey, updates map[string]interface{}) error {
+	var err error
+	if key.variantId != 0 {
+		err = tx.Model(&models.ProductVariant{}).Where("id = ?", key.variantId).Updates(updates).Error
+	} else {
+		err = tx.Model(&models.Product{}).Where("id = ?", key.productId).Updates(updates).Error
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update stock: %v", err)
+	}
+
+	return nil
+}
+
+// sortedStockKeys orders keys by product then variant, the lock order shared by every stock update
+func sortedStockKeys(quantities map[stockKey]int) []stockKey {
+	keys := make([]stockKey, 0, len(quantities))
+	for key := range quantities {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].productId != keys[j].productId {
+			return keys[i].productId < keys[j].productId
+		}
+		return keys[i].variantId < keys[j].variantId
+	})
+
+	return keys
+}
+
+func toInventoryItemResponse(productId uint, variantId *uint, name string, stock int, reserved int, lowLevel int, isAvailable bool) structs.InventoryItemResponse {
+	return structs.InventoryItemResponse{
+		ProductId:      productId,
+		VariantId:      variantId,
+		Name:           name,
+		StockQuantity:  stock,
+		ReservedStock:  reserved,
+		AvailableStock: stock - reserved,
+		LowStockLevel:  lowLevel,
+		IsLowStock:     stock <= max(lowLevel, 0),
+		IsAvailable:    isAvailable,
+	}
+}
@@ -0,0 +1,364 @@
+package services
+
+import (
+	"deck/config"
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type LoyaltyService struct {
+	db *gorm.DB
+}
+
+func NewLoyaltyService(db *gorm.DB) *LoyaltyService {
+	return &LoyaltyService{db: db}
+}
+
+// Get the loyalty program rules
+func (ls *LoyaltyService) GetSetting() (*models.LoyaltySetting, error) {
+	return loadLoyaltySetting(ls.db)
+}
+
+// Update the loyalty program rules
+func (ls *LoyaltyService) UpdateSetting(req *structs.LoyaltySettingRequest) (*models.LoyaltySetting, error) {
+	if req.MaxRedeemPercent > 100 {
+		return nil, invalidError("invalid max_redeem_percent, use 0 to 100")
+	}
+
+	setting, err := loadLoyaltySetting(ls.db)
+	if err != nil {
+		return nil, err
+	}
+
+	setting.IsActive = req.IsActive
+	setting.EarnAmount = req.EarnAmount
+	setting.MinimumSpend = req.MinimumSpend
+	setting.PointValue = req.PointValue
+	setting.MinRedeemPoints = req.MinRedeemPoints
+	setting.MaxRedeemPercent = req.MaxRedeemPercent
+	setting.ExpiryDays = req.ExpiryDays
+
+	if err := ls.db.Save(setting).Error; err != nil {
+		return nil, fmt.Errorf("failed to update loyalty setting: %v", err)
+	}
+
+	return setting, nil
+}
+
+// Get the points ledger of a customer, newest first
+func (ls *LoyaltyService) GetLedger(customerId uint, limit int) ([]models.LoyaltyPointEntry, error) {
+	if err := ls.db.First(&models.Customer{}, customerId).Error; err != nil {
+		return nil, notFoundError("customer not found")
+	}
+
+	var entries []models.LoyaltyPointEntry
+	err := ls.db.Where("customer_id = ?", customerId).Order("created_at DESC, id DESC").Limit(limit).Find(&entries).Error
+
+	return entries, err
+}
+
+// AdjustPoints adds or removes points by hand, for goodwill gestures or corrections
+func (ls *LoyaltyService) AdjustPoints(customerId uint, req *structs.LoyaltyAdjustRequest, createdBy string) (*models.LoyaltyPointEntry, error) {
+	if req.Points == 0 {
+		return nil, invalidError("invalid points, use a positive or negative number")
+	}
+
+	var entry *models.LoyaltyPointEntry
+	err := ls.db.Transaction(func(tx *gorm.DB) error {
+		customer, err := lockCustomer(tx, customerId)
+		if err != nil {
+			return err
+		}
+		if req.Points < 0 && customer.PointsBalance+req.Points < 0 {
+			return invalidError("invalid points, the customer only has %d points", customer.PointsBalance)
+		}
+
+		setting, err := loadLoyaltySetting(tx)
+		if err != nil {
+			return err
+		}
+
+		entry, err = changePoints(tx, customer, setting, models.LoyaltyPointEntry{
+			Type:      models.LoyaltyEntryAdjust,
+			Points:    req.Points,
+			Notes:     strings.TrimSpace(req.Notes),
+			CreatedBy: createdBy,
+		})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// ExpirePoints writes off points whose expiry date has passed and returns how many entries expired
+func (ls *LoyaltyService) ExpirePoints() (int, error) {
+	var customerIds []uint
+	if err := ls.db.Model(&models.LoyaltyPointEntry{}).
+		Where("remaining > 0 AND expires_at IS NOT NULL AND expires_at <= ?", time.Now()).
+		Distinct().Pluck("customer_id", &customerIds).Error; err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, customerId := range customerIds {
+		err := ls.db.Transaction(func(tx *gorm.DB) error {
+			customer, err := lockCustomer(tx, customerId)
+			if err != nil {
+				return err
+			}
+
+			var entries []models.LoyaltyPointEntry
+			if err := tx.Where("customer_id = ? AND remaining > 0 AND expires_at IS NOT NULL AND expires_at <= ?", customerId, time.Now()).
+				Order("expires_at ASC, id ASC").Find(&entries).Error; err != nil {
+				return err
+			}
+
+			for _, entry := range entries {
+				points := entry.Remaining
+				if err := tx.Model(&entry).Update("remaining", 0).Error; err != nil {
+					return err
+				}
+
+				customer.PointsBalance -= points
+				expiry := models.LoyaltyPointEntry{
+					CustomerId:   customer.Id,
+					Type:         models.LoyaltyEntryExpire,
+					Points:       -points,
+					BalanceAfter: customer.PointsBalance,
+					Notes:        fmt.Sprintf("expired points of entry #%d", entry.Id),
+				}
+				if err := tx.Create(&expiry).Error; err != nil {
+					return err
+				}
+				expired++
+			}
+
+			return tx.Model(customer).Update("points_balance", customer.PointsBalance).Error
+		})
+		if err != nil {
+			fmt.Printf("Failed to expire points of customer %d: %v\n", customerId, err)
+		}
+	}
+
+	return expired, nil
+}
+
+// StartPointsExpiryWorker runs ExpirePoints every night shortly after midnight in the outlet timezone,
+// it blocks so start it in its own goroutine
+func (ls *LoyaltyService) StartPointsExpiryWorker() {
+	for {
+		now := time.Now().In(config.Location())
+		next := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 5, 0, 0, now.Location())
+		time.Sleep(time.Until(next))
+
+		if _, err := ls.ExpirePoints(); err != nil {
+			fmt.Printf("Failed to expire loyalty points: %v\n", err)
+		}
+	}
+}
+
+func loadLoyaltySetting(db *gorm.DB) (*models.LoyaltySetting, error) {
+	var setting models.LoyaltySetting
+	if err := db.Order("id ASC").FirstOrCreate(&setting).Error; err != nil {
+		return nil, fmt.Errorf("failed to load loyalty setting: %v", err)
+	}
+
+	return &setting, nil
+}
+
+func lockCustomer(tx *gorm.DB, customerId uint) (*models.Customer, error) {
+	var customer models.Customer
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, customerId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("customer not found")
+		}
+		return nil, err
+	}
+
+	return &customer, nil
+}
+
+// quoteRedemption checks that a locked customer may redeem points on an order of orderTotal and returns the
+// discount the points are worth
+func quoteRedemption(tx *gorm.DB, customer *models.Customer, points uint, orderTotal uint) (uint, error) {
+	setting, err := loadLoyaltySetting(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	switch {
+	case !setting.IsActive:
+		return 0, invalidError("invalid redeem_points, the loyalty program is not active")
+	case int(points) > customer.PointsBalance:
+		return 0, invalidError("invalid redeem_points, the customer only has %d points", customer.PointsBalance)
+	case points < setting.MinRedeemPoints:
+		return 0, invalidError("invalid redeem_points, redeem at least %d points", setting.MinRedeemPoints)
+	}
+
+	discount := points * setting.PointValue
+	limit := orderTotal * setting.MaxRedeemPercent / 100
+	if discount > limit {
+		maxPoints := uint(0)
+		if setting.PointValue > 0 {
+			maxPoints = limit / setting.PointValue
+		}
+		return 0, invalidError("invalid redeem_points, at most %d points can be used on this order", maxPoints)
+	}
+
+	return discount, nil
+}
+
+// recordRedemption takes the points redeemed on a new transaction off the customer balance
+func recordRedemption(tx *gorm.DB, customer *models.Customer, transaction *models.Transaction) error {
+	if transaction.PointsRedeemed == 0 {
+		return nil
+	}
+
+	_, err := changePoints(tx, customer, nil, models.LoyaltyPointEntry{
+		TransactionId: &transaction.Id,
+		Type:          models.LoyaltyEntryRedeem,
+		Points:        -int(transaction.PointsRedeemed),
+		Notes:         fmt.Sprintf("redeemed on order %s", transaction.OrderNumber),
+	})
+
+	return err
+}
+
+// earnPoints credits the customer of a transaction that just became paid
+func earnPoints(tx *gorm.DB, transaction *models.Transaction) error {
+	if transaction.CustomerId == nil {
+		return nil
+	}
+
+	setting, err := loadLoyaltySetting(tx)
+	if err != nil {
+		return err
+	}
+	if !setting.IsActive || setting.EarnAmount == 0 || transaction.TotalAmount < setting.MinimumSpend {
+		return nil
+	}
+
+	points := transaction.TotalAmount / setting.EarnAmount
+	if points == 0 {
+		return nil
+	}
+
+	customer, err := lockCustomer(tx, *transaction.CustomerId)
+	if err != nil {
+		return err
+	}
+
+	_, err = changePoints(tx, customer, setting, models.LoyaltyPointEntry{
+		TransactionId: &transaction.Id,
+		Type:          models.LoyaltyEntryEarn,
+		Points:        int(points),
+		Notes:         fmt.Sprintf("earned on order %s", transaction.OrderNumber),
+	})
+	if err != nil {
+		return err
+	}
+
+	transaction.PointsEarned = points
+	return nil
+}
+
+// reversePoints undoes the loyalty effects of a transaction that is cancelled or refunded: redeemed points are
+// given back and points earned on it are taken away again, which may leave a negative balance
+func reversePoints(tx *gorm.DB, transaction *models.Transaction) error {
+	if transaction.CustomerId == nil || (transaction.PointsRedeemed == 0 && transaction.PointsEarned == 0) {
+		return nil
+	}
+
+	customer, err := lockCustomer(tx, *transaction.CustomerId)
+	if err != nil {
+		return err
+	}
+	setting, err := loadLoyaltySetting(tx)
+	if err != nil {
+		return err
+	}
+
+	if transaction.PointsRedeemed > 0 {
+		if _, err := changePoints(tx, customer, setting, models.LoyaltyPointEntry{
+			TransactionId: &transaction.Id,
+			Type:          models.LoyaltyEntryAdjust,
+			Points:        int(transaction.PointsRedeemed),
+			Notes:         fmt.Sprintf("returned redeemed points, order %s is %s", transaction.OrderNumber, transaction.PaymentStatus),
+		}); err != nil {
+			return err
+		}
+	}
+
+	if transaction.PointsEarned > 0 {
+		if _, err := changePoints(tx, customer, setting, models.LoyaltyPointEntry{
+			TransactionId: &transaction.Id,
+			Type:          models.LoyaltyEntryAdjust,
+			Points:        -int(transaction.PointsEarned),
+			Notes:         fmt.Sprintf("reversed earned points, order %s is %s", transaction.OrderNumber, transaction.PaymentStatus),
+		}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// changePoints writes a ledger entry for a locked customer and moves their balance. Added points get an expiry
+// date from the setting, removed points are taken from the oldest entries that still have points left.
+func changePoints(tx *gorm.DB, customer *models.Customer, setting *models.LoyaltySetting, entry models.LoyaltyPointEntry) (*models.LoyaltyPointEntry, error) {
+	if entry.Points > 0 {
+		entry.Remaining = entry.Points
+		if setting != nil && setting.ExpiryDays > 0 {
+			expiresAt := time.Now().AddDate(0, 0, int(setting.ExpiryDays))
+			entry.ExpiresAt = &expiresAt
+		}
+	} else if err := consumePoints(tx, customer.Id, -entry.Points); err != nil {
+		return nil, err
+	}
+
+	customer.PointsBalance += entry.Points
+	entry.CustomerId = customer.Id
+	entry.BalanceAfter = customer.PointsBalance
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return nil, fmt.Errorf("failed to record points: %v", err)
+	}
+	if err := tx.Model(customer).Update("points_balance", customer.PointsBalance).Error; err != nil {
+		return nil, fmt.Errorf("failed to update points balance: %v", err)
+	}
+
+	return &entry, nil
+}
+
+// consumePoints lowers the remaining points of a customer's entries, soonest to expire first
+func consumePoints(tx *gorm.DB, customerId uint, points int) error {
+	var entries []models.LoyaltyPointEntry
+	if err := tx.Where("customer_id = ? AND remaining > 0", customerId).
+		Order("expires_at ASC NULLS LAST, id ASC").Find(&entries).Error; err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if points == 0 {
+			break
+		}
+
+		used := min(entry.Remaining, points)
+		if err := tx.Model(&entry).Update("remaining", entry.Remaining-used).Error; err != nil {
+			return err
+		}
+		points -= used
+	}
+
+	return nil
+}
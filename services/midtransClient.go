@@ -0,0 +1,151 @@
+package services
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha512"
+	"deck/config"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// snapTransaction is the part of a Midtrans Snap request the outlet fills in
+type snapTransaction struct {
+	OrderId     string
+	GrossAmount uint
+	BuyerName   string
+	Phone       string
+}
+
+type snapResult struct {
+	Token       string `json:"token"`
+	RedirectUrl string `json:"redirect_url"`
+}
+
+// MidtransNotification is the payload Midtrans posts to the payment notification URL
+type MidtransNotification struct {
+	OrderId           string `json:"order_id"`
+	StatusCode        string `json:"status_code"`
+	GrossAmount       string `json:"gross_amount"`
+	SignatureKey      string `json:"signature_key"`
+	TransactionStatus string `json:"transaction_status"`
+	FraudStatus       string `json:"fraud_status"`
+	PaymentType       string `json:"payment_type"`
+	TransactionId     string `json:"transaction_id"`
+}
+
+var midtransHttpClient = &http.Client{Timeout: 15 * time.Second}
+
+// createSnapToken asks Midtrans Snap for a payment page of the given amount. MIDTRANS_SERVER_KEY is required,
+// MIDTRANS_IS_PRODUCTION=true switches from the sandbox to production.
+func createSnapToken(transaction snapTransaction) (*snapResult, error) {
+	serverKey := config.GetEnv("MIDTRANS_SERVER_KEY", "")
+	if serverKey == "" {
+		return nil, paymentGatewayError("midtrans is not configured, set MIDTRANS_SERVER_KEY")
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"transaction_details": map[string]interface{}{
+			"order_id":     transaction.OrderId,
+			"gross_amount": transaction.GrossAmount,
+		},
+		"customer_details": map[string]interface{}{
+			"first_name": transaction.BuyerName,
+			"phone":      transaction.Phone,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest(http.MethodPost, snapUrl(), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(serverKey, "")
+
+	response, err := midtransHttpClient.Do(request)
+	if err != nil {
+		return nil, paymentGatewayError("failed to reach midtrans: %v", err)
+	}
+	defer response.Body.Close()
+
+	var result struct {
+		snapResult
+		ErrorMessages []string `json:"error_messages"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return nil, paymentGatewayError("failed to read midtrans response: %v", err)
+	}
+	if response.StatusCode != http.StatusCreated || result.Token == "" {
+		return nil, paymentGatewayError("midtrans rejected the payment: %v", result.ErrorMessages)
+	}
+
+	return &result.snapResult, nil
+}
+
+// expireMidtransTransaction closes a Midtrans payment that is still waiting, so the customer can no longer pay it
+func expireMidtransTransaction(orderId string) error {
+	serverKey := config.GetEnv("MIDTRANS_SERVER_KEY", "")
+	if serverKey == "" {
+		return paymentGatewayError("midtrans is not configured, set MIDTRANS_SERVER_KEY")
+	}
+
+	request, err := http.NewRequest(http.MethodPost, coreApiUrl()+"/v2/"+url.PathEscape(orderId)+"/expire", nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", "application/json")
+	request.SetBasicAuth(serverKey, "")
+
+	response, err := midtransHttpClient.Do(request)
+	if err != nil {
+		return paymentGatewayError("failed to reach midtrans: %v", err)
+	}
+	defer response.Body.Close()
+
+	// The Core API answers 200 and puts its own status code in the body
+	var result struct {
+		StatusCode    string `json:"status_code"`
+		StatusMessage string `json:"status_message"`
+	}
+	if err := json.NewDecoder(response.Body).Decode(&result); err != nil {
+		return paymentGatewayError("failed to read midtrans response: %v", err)
+	}
+	if result.StatusCode != "407" && result.StatusCode != "200" {
+		return paymentGatewayError("midtrans did not expire the payment: %s %s", result.StatusCode, result.StatusMessage)
+	}
+
+	return nil
+}
+
+// verifyMidtransSignature checks the signature_key of a notification, sha512 of order id, status code,
+// gross amount and the server key
+func verifyMidtransSignature(notification *MidtransNotification) bool {
+	serverKey := config.GetEnv("MIDTRANS_SERVER_KEY", "")
+	if serverKey == "" {
+		return false
+	}
+
+	sum := sha512.Sum512([]byte(notification.OrderId + notification.StatusCode + notification.GrossAmount + serverKey))
+	return hmac.Equal([]byte(hex.EncodeToString(sum[:])), []byte(notification.SignatureKey))
+}
+
+func snapUrl() string {
+	if config.GetEnv("MIDTRANS_IS_PRODUCTION", "false") == "true" {
+		return "https://app.midtrans.com/snap/v1/transactions"
+	}
+	return "https://app.sandbox.midtrans.com/snap/v1/transactions"
+}
+
+func coreApiUrl() string {
+	if config.GetEnv("MIDTRANS_IS_PRODUCTION", "false") == "true" {
+		return "https://api.midtrans.com"
+	}
+	return "https://api.sandbox.midtrans.com"
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+)
+
+type ModifierService struct {
+	db *gorm.DB
+}
+
+func NewModifierService(db *gorm.DB) *ModifierService {
+	return &ModifierService{db: db}
+}
+
+// Get All Modifier Groups
+func (ms *ModifierService) GetModifierGroups() ([]models.ModifierGroup, error) {
+	var groups []models.ModifierGroup
+	err := ms.db.
+		Preload("Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Order("sort_order ASC, id ASC").
+		Find(&groups).Error
+
+	return groups, err
+}
+
+// Get Modifier Group By Id
+func (ms *ModifierService) GetModifierGroupById(id uint) (*models.ModifierGroup, error) {
+	var group models.ModifierGroup
+	err := ms.db.
+		Preload("Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		First(&group, id).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("modifier group not found")
+		}
+		return nil, fmt.Errorf("failed to find modifier group: %v", err)
+	}
+
+	return &group, nil
+}
+
+// Create Modifier Group with its modifiers
+func (ms *ModifierService) CreateModifierGroup(req *structs.ModifierGroupRequest) (*models.ModifierGroup, error) {
+	if err := validateModifierGroupRequest(req); err != nil {
+		return nil, err
+	}
+
+	group := models.ModifierGroup{
+		Name:       req.Name,
+		MinSelect:  req.MinSelect,
+		MaxSelect:  req.MaxSelect,
+		IsRequired: req.IsRequired,
+		SortOrder:  req.SortOrder,
+	}
+
+	for _, modifier := range req.Modifiers {
+		group.Modifiers = append(group.Modifiers, models.Modifier{
+			Name:        modifier.Name,
+			PriceDelta:  modifier.PriceDelta,
+			IsAvailable: modifier.IsAvailable == nil || *modifier.IsAvailable,
+			SortOrder:   modifier.SortOrder,
+		})
+	}
+
+	if err := ms.db.Create(&group).Error; err != nil {
+		return nil, fmt.Errorf("failed to create modifier group: %v", err)
+	}
+
+	return &group, nil
+}
+
+// Update Modifier Group. Modifiers with an id are updated, new ones are created and missing ones are removed.
+func (ms *ModifierService) UpdateModifierGroup(id uint, req *structs.ModifierGroupRequest) (*models.ModifierGroup, error) {
+	if err := validateModifierGroupRequest(req); err != nil {
+		return nil, err
+	}
+
+	group, err := ms.GetModifierGroupById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := make(map[uint]models.Modifier)
+	for _, modifier := range group.Modifiers {
+		existing[modifier.Id] = modifier
+	}
+
+	err = ms.db.Transaction(func(tx *gorm.DB) error {
+		group.Name = req.Name
+		group.MinSelect = req.MinSelect
+		group.MaxSelect = req.MaxSelect
+		group.IsRequired = req.IsRequired
+		group.SortOrder = req.SortOrder
+
+		if err := tx.Omit("Modifiers").Save(group).Error; err != nil {
+			return err
+		}
+
+		keep := make(map[uint]bool)
+		for _, item := range req.Modifiers {
+			modifier := models.Modifier{ModifierGroupId: group.Id}
+			if item.Id != 0 {
+				current, ok := existing[item.Id]
+				if !ok {
+					return invalidError("invalid modifier id %d for this group", item.Id)
+				}
+				modifier = current
+				keep[item.Id] = true
+			}
+
+			modifier.Name = item.Name
+			modifier.PriceDelta = item.PriceDelta
+			modifier.SortOrder = item.SortOrder
+			modifier.IsAvailable = item.IsAvailable == nil || *item.IsAvailable
+
+			if err := tx.Save(&modifier).Error; err != nil {
+				return err
+			}
+		}
+
+		for modifierId := range existing {
+			if !keep[modifierId] {
+				if err := tx.Delete(&models.Modifier{}, modifierId).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("modifier group not found")
+		}
+		return nil, fmt.Errorf("failed to update modifier group: %w", err)
+	}
+
+	return ms.GetModifierGroupById(id)
+}
+
+// Delete Modifier Group and detach it from products
+func (ms *ModifierService) DeleteModifierGroup(id uint) error {
+	group, err := ms.GetModifierGroupById(id)
+	if err != nil {
+		return err
+	}
+
+	err = ms.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Exec("DELETE FROM product_modifier_groups WHERE modifier_group_id = ?", group.Id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(group).Error
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete modifier group: %v", err)
+	}
+
+	return nil
+}
+
+// Set the modifier groups offered on a product
+func (ms *ModifierService) SetProductModifierGroups(productId uint, groupIds []uint) ([]models.ModifierGroup, error) {
+	var product models.Product
+	if err := ms.db.First(&product, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	var groups []models.ModifierGroup
+	if len(groupIds) > 0 {
+		if err := ms.db.Where("id IN ?", groupIds).Find(&groups).Error; err != nil {
+			return nil, fmt.Errorf("failed to find modifier groups: %v", err)
+		}
+		if len(groups) != len(uniqueIds(groupIds)) {
+			return nil, invalidError("invalid modifier groups, some groups were not found")
+		}
+	}
+
+	if err := ms.db.Model(&product).Association("ModifierGroups").Replace(groups); err != nil {
+		return nil, fmt.Errorf("failed to update product modifier groups: %v", err)
+	}
+
+	return productModifierGroups(ms.db, product.Id)
+}
+
+// productModifierGroups loads the modifier groups attached to a product, with their modifiers
+func productModifierGroups(db *gorm.DB, productId uint) ([]models.ModifierGroup, error) {
+	var groups []models.ModifierGroup
+	err := db.
+		Preload("Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Joins("JOIN product_modifier_groups ON product_modifier_groups.modifier_group_id = modifier_groups.id").
+		Where("product_modifier_groups.product_id = ?", productId).
+		Order("modifier_groups.sort_order ASC, modifier_groups.id ASC").
+		Find(&groups).Error
+
+	return groups, err
+}
+
+func validateModifierGroupRequest(req *structs.ModifierGroupRequest) error {
+	if req.MaxSelect > 0 && req.MinSelect > req.MaxSelect {
+		return invalidError("invalid selection range, min_select is greater than max_select")
+	}
+
+	if req.MaxSelect > 0 && int(req.MaxSelect) > len(req.Modifiers) {
+		return invalidError("invalid selection range, max_select is greater than the number of modifiers")
+	}
+
+	if int(req.MinSelect) > len(req.Modifiers) {
+		return invalidError("invalid selection range, min_select is greater than the number of modifiers")
+	}
+
+	return nil
+}
+
+// resolveModifierSelection validates the modifiers picked for a product against its groups
+// and returns snapshot rows together with the summed price delta per unit
+func resolveModifierSelection(tx *gorm.DB, product *models.Product, modifierIds []uint) ([]models.TransactionDetailModifier, int, error) {
+	groups, err := productModifierGroups(tx, product.Id)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to load modifiers: %v", err)
+	}
+
+	selected := make(map[uint]bool)
+	for _, id := range modifierIds {
+		if selected[id] {
+			return nil, 0, invalidError("invalid modifier selection, modifier %d selected more than once for product: %s", id, product.Name)
+		}
+		selected[id] = true
+	}
+
+	var snapshots []models.TransactionDetailModifier
+	var priceDelta int
+
+	for _, group := range groups {
+		var count uint
+		for _, modifier := range group.Modifiers {
+			if !selected[modifier.Id] {
+				continue
+			}
+			delete(selected, modifier.Id)
+
+			if !modifier.IsAvailable {
+				return nil, 0, invalidError("modifier not available: %s", modifier.Name)
+			}
+
+			count++
+			priceDelta += modifier.PriceDelta
+			snapshots = append(snapshots, models.TransactionDetailModifier{
+				ModifierId: modifier.Id,
+				GroupName:  group.Name,
+				Name:       modifier.Name,
+				PriceDelta: modifier.PriceDelta,
+			})
+		}
+
+		if count < group.MinRequired() {
+			return nil, 0, invalidError("invalid modifier selection, group %s for product %s requires at least %d selection(s)", group.Name, product.Name, group.MinRequired())
+		}
+		if group.MaxSelect > 0 && count > group.MaxSelect {
+			return nil, 0, invalidError("invalid modifier selection, group %s for product %s allows at most %d selection(s)", group.Name, product.Name, group.MaxSelect)
+		}
+	}
+
+	if len(selected) > 0 {
+		return nil, 0, invalidError("modifier not found or not available for product: %s", product.Name)
+	}
+
+	return snapshots, priceDelta, nil
+}
+
+func uniqueIds(ids []uint) []uint {
+	seen := make(map[uint]bool)
+	var result []uint
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
@@ -0,0 +1,243 @@
+package services
+
+import (
+	"deck/config"
+	"deck/helpers"
+	"deck/models"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type OrderingSessionService struct {
+	db *gorm.DB
+}
+
+func NewOrderingSessionService(db *gorm.DB) *OrderingSessionService {
+	return &OrderingSessionService{db: db}
+}
+
+// TableQr is what gets printed on a table: the signed token and the ordering page it opens
+type TableQr struct {
+	Table models.Table
+	Token string
+	Url   string
+}
+
+// Get the QR token and ordering link of a table
+func (oss *OrderingSessionService) GetTableQr(tableId uint) (*TableQr, error) {
+	var table models.Table
+	if err := oss.db.Preload("Area").First(&table, tableId).Error; err != nil {
+		return nil, notFoundError("table not found")
+	}
+
+	token, err := helpers.SignTableToken(table.Id, table.QrVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &TableQr{
+		Table: table,
+		Token: token,
+		Url:   qrOrderUrl(token),
+	}, nil
+}
+
+// RenderTableQr returns the ordering link of a table as a PNG QR code of size pixels
+func (oss *OrderingSessionService) RenderTableQr(tableId uint, size int) ([]byte, error) {
+	qr, err := oss.GetTableQr(tableId)
+	if err != nil {
+		return nil, err
+	}
+
+	png, err := qrcode.Encode(qr.Url, qrcode.Medium, size)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render qr code: %v", err)
+	}
+
+	return png, nil
+}
+
+// RotateTableQr invalidates the printed QR code of a table and closes the sessions opened with it
+func (oss *OrderingSessionService) RotateTableQr(tableId uint) (*TableQr, error) {
+	err := oss.db.Transaction(func(tx *gorm.DB) error {
+		table, err := lockTable(tx, tableId)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(table).Update("qr_version", gorm.Expr("qr_version + 1")).Error; err != nil {
+			return fmt.Errorf("failed to rotate qr code: %v", err)
+		}
+
+		return closeOrderingSessions(tx, tableId)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return oss.GetTableQr(tableId)
+}
+
+// OpenSession starts a guest ordering session from a scanned table token. The printed code is public, so a phone
+// scanning the same table again gets its live session back, and the live sessions of a table and of a client IP
+// are capped by QR_SESSION_MAX_PER_TABLE and QR_SESSION_MAX_PER_IP.
+func (oss *OrderingSessionService) OpenSession(token string, clientIp string) (*models.OrderingSession, error) {
+	tableId, version, err := helpers.ParseTableToken(token)
+	if errors.Is(err, helpers.ErrInvalidTableToken) {
+		return nil, invalidError("%v", err)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session models.OrderingSession
+	err = oss.db.Transaction(func(tx *gorm.DB) error {
+		// Locking the table makes sessions opened at the same time count each other
+		table, err := lockTable(tx, tableId)
+		if err != nil || table.QrVersion != version {
+			return invalidError("invalid table token, scan the code on the table again")
+		}
+		if !table.IsActive {
+			return invalidError("invalid table token, table %s is not in use", table.Name)
+		}
+
+		now := time.Now()
+		err = tx.Where("table_id = ? AND client_ip = ? AND expires_at > ?", table.Id, clientIp, now).
+			Order("id DESC").First(&session).Error
+		if err == nil {
+			session.Table = table
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("failed to open session: %v", err)
+		}
+
+		var tableSessions, ipSessions int64
+		if err := tx.Model(&models.OrderingSession{}).Where("table_id = ? AND expires_at > ?", table.Id, now).
+			Count(&tableSessions).Error; err != nil {
+			return fmt.Errorf("failed to open session: %v", err)
+		}
+		if tableSessions >= int64(qrSessionSetting("QR_SESSION_MAX_PER_TABLE", 6)) {
+			return tooManyRequestsError("too many sessions, table %s has too many open sessions, ask the staff for help", table.Name)
+		}
+		if err := tx.Model(&models.OrderingSession{}).Where("client_ip = ? AND expires_at > ?", clientIp, now).
+			Count(&ipSessions).Error; err != nil {
+			return fmt.Errorf("failed to open session: %v", err)
+		}
+		if ipSessions >= int64(qrSessionSetting("QR_SESSION_MAX_PER_IP", 20)) {
+			return tooManyRequestsError("too many sessions, this device has too many open sessions, ask the staff for help")
+		}
+
+		sessionToken, err := helpers.RandomToken(32)
+		if err != nil {
+			return fmt.Errorf("failed to open session: %v", err)
+		}
+
+		session = models.OrderingSession{
+			Token:     sessionToken,
+			TableId:   table.Id,
+			ClientIp:  clientIp,
+			ExpiresAt: now.Add(time.Duration(qrSessionSetting("QR_SESSION_MINUTES", 120)) * time.Minute),
+		}
+		if err := tx.Create(&session).Error; err != nil {
+			return fmt.Errorf("failed to open session: %v", err)
+		}
+		session.Table = table
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+// Get an active session by its token with the orders placed in it
+func (oss *OrderingSessionService) GetSession(token string) (*models.OrderingSession, []models.Transaction, error) {
+	session, err := findActiveSession(oss.db, token)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var transactions []models.Transaction
+	err = oss.db.Preload("TransactionDetails.Modifiers").Preload("TransactionDetails.Discounts").
+		Where("session_id = ?", session.Id).
+		Order("created_at ASC").
+		Find(&transactions).Error
+
+	return session, transactions, err
+}
+
+// QrSessionMaxOrders is how many orders one session may place, QR_SESSION_MAX_ORDERS defaults to 10
+func QrSessionMaxOrders() uint {
+	return uint(qrSessionSetting("QR_SESSION_MAX_ORDERS", 10))
+}
+
+func findActiveSession(db *gorm.DB, token string) (*models.OrderingSession, error) {
+	var session models.OrderingSession
+	if err := db.Preload("Table.Area").Where("token = ?", token).First(&session).Error; err != nil {
+		return nil, notFoundError("ordering session not found")
+	}
+	if time.Now().After(session.ExpiresAt) {
+		return nil, invalidError("invalid session, it has expired, scan the code on the table again")
+	}
+
+	return &session, nil
+}
+
+// claimSessionOrder applies the rate limits of a guest session before it places an order: a cap on the orders
+// per session and a pause between two orders
+func claimSessionOrder(tx *gorm.DB, sessionId uint) error {
+	var session models.OrderingSession
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&session, sessionId).Error; err != nil {
+		return notFoundError("ordering session not found")
+	}
+
+	now := time.Now()
+	if now.After(session.ExpiresAt) {
+		return invalidError("invalid session, it has expired, scan the code on the table again")
+	}
+	if session.OrderCount >= QrSessionMaxOrders() {
+		return tooManyRequestsError("too many orders, a session can place at most %d orders, ask the staff for help", QrSessionMaxOrders())
+	}
+	interval := time.Duration(qrSessionSetting("QR_SESSION_ORDER_INTERVAL_SECONDS", 60)) * time.Second
+	if session.LastOrderAt != nil && now.Sub(*session.LastOrderAt) < interval {
+		wait := interval - now.Sub(*session.LastOrderAt)
+		return tooManyRequestsError("too many orders, wait %d seconds before ordering again", int(wait.Seconds())+1)
+	}
+
+	return tx.Model(&session).Updates(map[string]interface{}{
+		"order_count":   session.OrderCount + 1,
+		"last_order_at": now,
+	}).Error
+}
+
+// closeOrderingSessions ends the guest sessions of a table, so the next guests need to scan the code themselves
+func closeOrderingSessions(tx *gorm.DB, tableId uint) error {
+	return tx.Model(&models.OrderingSession{}).
+		Where("table_id = ? AND expires_at > ?", tableId, time.Now()).
+		Update("expires_at", time.Now()).Error
+}
+
+// qrOrderUrl is the guest ordering page for a table token, QR_ORDER_URL is the page without the token
+func qrOrderUrl(token string) string {
+	base := config.GetEnv("QR_ORDER_URL", "http://localhost:3000/order")
+	return base + "?token=" + url.QueryEscape(token)
+}
+
+func qrSessionSetting(key string, fallback int) int {
+	value, err := strconv.Atoi(config.GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+
+	return value
+}
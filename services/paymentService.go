@@ -0,0 +1,487 @@
+package services
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrInvalidSignature is a Midtrans notification whose signature does not match the server key
+var ErrInvalidSignature = errors.New("invalid signature")
+
+type PaymentService struct {
+	db                  *gorm.DB
+	transactionService  *TransactionService
+	notificationService *NotificationService
+}
+
+func NewPaymentService(db *gorm.DB, transactionService *TransactionService, notificationService *NotificationService) *PaymentService {
+	return &PaymentService{
+		db:                  db,
+		transactionService:  transactionService,
+		notificationService: notificationService,
+	}
+}
+
+// PaymentSummary is a transaction with its payments and how much of the bill they cover
+type PaymentSummary struct {
+	Transaction   models.Transaction
+	Payments      []models.Payment
+	PaidAmount    uint
+	PendingAmount uint
+	Remaining     uint
+}
+
+// Get the payments of a transaction with what is left to pay
+func (pms *PaymentService) GetPayments(transactionId uint) (*PaymentSummary, error) {
+	var transaction models.Transaction
+	if err := pms.db.First(&transaction, transactionId).Error; err != nil {
+		return nil, notFoundError("transaction not found")
+	}
+
+	return paymentSummary(pms.db, &transaction)
+}
+
+// CreatePayment records a payment towards a pending transaction. Cash, card, QRIS and transfer payments are
+// confirmed by the cashier and count as paid at once, a Midtrans payment waits for its notification.
+func (pms *PaymentService) CreatePayment(transactionId uint, req *structs.PaymentCreateRequest, createdBy string) (*models.Payment, error) {
+	var payment models.Payment
+	var alerts *statusAlerts
+	var transaction models.Transaction
+
+	err := pms.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, transactionId).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return notFoundError("transaction not found")
+			}
+			return err
+		}
+		if transaction.PaymentStatus != models.PaymentStatusPending {
+			return invalidError("invalid payment, transaction is already %s", transaction.PaymentStatus)
+		}
+
+		// The first payment locks an open tab
+		if transaction.PaymentStartedAt == nil {
+			if err := startTabPayment(tx, &transaction, createdBy); err != nil {
+				return err
+			}
+		}
+
+		summary, err := paymentSummary(tx, &transaction)
+		if err != nil {
+			return err
+		}
+		if summary.Remaining == 0 {
+			return invalidError("invalid payment, the bill is already covered by other payments")
+		}
+
+		payment = models.Payment{
+			TransactionId: transaction.Id,
+			Method:        req.Method,
+			Status:        models.PaymentStatusPending,
+			Reference:     strings.TrimSpace(req.Reference),
+			CreatedBy:     createdBy,
+		}
+		if err := splitPayment(tx, &transaction, summary, req, &payment); err != nil {
+			return err
+		}
+
+		if req.Method == models.PaymentMethodCash {
+			payment.Tendered = max(req.Tendered, payment.Amount)
+			if req.Tendered > 0 && req.Tendered < payment.Amount {
+				return invalidError("invalid tendered, the payment is %d", payment.Amount)
+			}
+			payment.Change = payment.Tendered - payment.Amount
+		}
+		if req.Method != models.PaymentMethodMidtrans {
+			now := time.Now()
+			payment.Status = models.PaymentStatusPaid
+			payment.PaidAt = &now
+		}
+
+		if err := tx.Create(&payment).Error; err != nil {
+			return fmt.Errorf("failed to create payment: %v", err)
+		}
+		if req.Method == models.PaymentMethodMidtrans {
+			orderId := fmt.Sprintf("%s-P%d", transaction.OrderNumber, payment.Id)
+			payment.MidtransOrderId = &orderId
+			if err := tx.Model(&payment).Update("midtrans_order_id", orderId).Error; err != nil {
+				return err
+			}
+		}
+
+		alerts, err = pms.settleIfCovered(tx, &transaction)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	pms.transactionService.notifyStatusAlerts(alerts)
+
+	if payment.Method == models.PaymentMethodMidtrans {
+		snap, err := createSnapToken(snapTransaction{
+			OrderId:     *payment.MidtransOrderId,
+			GrossAmount: payment.Amount,
+			BuyerName:   transaction.BuyerName,
+			Phone:       transaction.Phone,
+		})
+		if err != nil {
+			pms.db.Model(&payment).Update("status", models.PaymentStatusFailed)
+			return nil, err
+		}
+
+		payment.MidtransToken = snap.Token
+		payment.RedirectUrl = snap.RedirectUrl
+		if err := pms.db.Model(&payment).Updates(map[string]interface{}{
+			"midtrans_token": snap.Token,
+			"redirect_url":   snap.RedirectUrl,
+		}).Error; err != nil {
+			return nil, fmt.Errorf("failed to save midtrans token: %v", err)
+		}
+	}
+
+	return &payment, nil
+}
+
+// CancelPayment drops a payment that is still waiting, its share of the bill is open again. A Midtrans payment
+// is expired at Midtrans too, one that settles anyway is marked refund_due.
+func (pms *PaymentService) CancelPayment(transactionId uint, paymentId uint) (*models.Payment, error) {
+	var payment models.Payment
+	err := pms.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND transaction_id = ?", paymentId, transactionId).First(&payment).Error; err != nil {
+			return notFoundError("payment not found")
+		}
+		if payment.Status != models.PaymentStatusPending {
+			return invalidError("invalid payment, it is already %s", payment.Status)
+		}
+
+		payment.Status = models.PaymentStatusCancelled
+		return tx.Model(&payment).Update("status", payment.Status).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if payment.MidtransOrderId != nil {
+		if err := expireMidtransTransaction(*payment.MidtransOrderId); err != nil {
+			fmt.Printf("Failed to expire midtrans payment %s: %v\n", *payment.MidtransOrderId, err)
+		}
+	}
+
+	return &payment, nil
+}
+
+// HandleMidtransNotification applies a Midtrans payment notification to the payment it belongs to. A payment
+// that settles after the cashier cancelled it, or after its order expired, was cancelled or was paid another way,
+// is marked refund_due and the admins are told to give the money back. A settlement for less than the payment is
+// marked review for the admins to check, any other gross_amount than the payment is rejected.
+func (pms *PaymentService) HandleMidtransNotification(notification *MidtransNotification) error {
+	if !verifyMidtransSignature(notification) {
+		return ErrInvalidSignature
+	}
+
+	var status string
+	switch notification.TransactionStatus {
+	case "capture":
+		if notification.FraudStatus != "" && notification.FraudStatus != "accept" {
+			return nil
+		}
+		status = models.PaymentStatusPaid
+	case "settlement":
+		status = models.PaymentStatusPaid
+	case "deny", "failure":
+		status = models.PaymentStatusFailed
+	case "cancel":
+		status = models.PaymentStatusCancelled
+	case "expire":
+		status = models.PaymentStatusExpired
+	default:
+		return nil
+	}
+
+	var alerts *statusAlerts
+	var payment models.Payment
+	var transaction models.Transaction
+	var received uint
+	refundDue := false
+	review := false
+	err := pms.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("midtrans_order_id = ?", notification.OrderId).First(&payment).Error; err != nil {
+			return notFoundError("payment not found")
+		}
+		if payment.Status == status || payment.Status == models.PaymentStatusPaid ||
+			payment.Status == models.PaymentStatusRefundDue || payment.Status == models.PaymentStatusReview {
+			return nil
+		}
+
+		var ok bool
+		received, ok = midtransAmount(notification.GrossAmount)
+		underpaid := ok && received < payment.Amount && status == models.PaymentStatusPaid
+		if !ok || (received != payment.Amount && !underpaid) {
+			return invalidError("invalid gross_amount %s, the payment is %d", notification.GrossAmount, payment.Amount)
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, payment.TransactionId).Error; err != nil {
+			return err
+		}
+
+		// The customer was charged for an order that no longer takes payments, or for a payment whose share of the
+		// bill was opened again and may be covered by another payment
+		if status == models.PaymentStatusPaid &&
+			(transaction.PaymentStatus != models.PaymentStatusPending || payment.Status == models.PaymentStatusCancelled) {
+			status = models.PaymentStatusRefundDue
+			refundDue = true
+		} else if underpaid {
+			status = models.PaymentStatusReview
+			review = true
+		}
+
+		updates := map[string]interface{}{
+			"status":    status,
+			"reference": notification.TransactionId,
+		}
+		if status == models.PaymentStatusPaid || status == models.PaymentStatusRefundDue || status == models.PaymentStatusReview {
+			updates["paid_at"] = time.Now()
+		}
+		if err := tx.Model(&payment).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		if status != models.PaymentStatusPaid {
+			return nil
+		}
+
+		var err error
+		alerts, err = pms.settleIfCovered(tx, &transaction)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	pms.transactionService.notifyStatusAlerts(alerts)
+	if refundDue {
+		pms.notifyRefundDue(&payment, &transaction)
+	}
+	if review {
+		pms.notifyPaymentReview(&payment, &transaction, received)
+	}
+
+	return nil
+}
+
+// midtransAmount reads the gross_amount of a notification, Midtrans writes it with two decimals
+func midtransAmount(grossAmount string) (uint, bool) {
+	amount, err := strconv.ParseFloat(grossAmount, 64)
+	if err != nil || amount < 0 {
+		return 0, false
+	}
+
+	return uint(math.Round(amount)), true
+}
+
+func (pms *PaymentService) notifyPaymentReview(payment *models.Payment, transaction *models.Transaction, received uint) {
+	title := "Pembayaran Perlu Diperiksa"
+	message := fmt.Sprintf("Pembayaran Midtrans untuk pesanan %s masuk %s dari %s, periksa transaksinya di Midtrans",
+		transaction.OrderNumber, helpers.FormatCurrency(received), helpers.FormatCurrency(payment.Amount))
+	data := map[string]interface{}{
+		"payment_id":     payment.Id,
+		"transaction_id": transaction.Id,
+		"order_number":   transaction.OrderNumber,
+		"amount":         payment.Amount,
+		"received":       received,
+	}
+	if err := pms.notificationService.BroadcastToAdmins("payment_review", title, message, data); err != nil {
+		fmt.Printf("Failed to broadcast notification: %v\n", err)
+	}
+}
+
+func (pms *PaymentService) notifyRefundDue(payment *models.Payment, transaction *models.Transaction) {
+	title := "Pembayaran Perlu Direfund"
+	message := fmt.Sprintf("Pembayaran Midtrans %s untuk pesanan %s masuk setelah pesanan %s, kembalikan dana ke pelanggan",
+		helpers.FormatCurrency(payment.Amount), transaction.OrderNumber, transaction.PaymentStatus)
+	if transaction.PaymentStatus == models.PaymentStatusPending {
+		message = fmt.Sprintf("Pembayaran Midtrans %s untuk pesanan %s masuk setelah pembayarannya dibatalkan, kembalikan dana ke pelanggan",
+			helpers.FormatCurrency(payment.Amount), transaction.OrderNumber)
+	}
+	data := map[string]interface{}{
+		"payment_id":     payment.Id,
+		"transaction_id": transaction.Id,
+		"order_number":   transaction.OrderNumber,
+		"amount":         payment.Amount,
+		"order_status":   transaction.PaymentStatus,
+	}
+	if err := pms.notificationService.BroadcastToAdmins("payment_refund_due", title, message, data); err != nil {
+		fmt.Printf("Failed to broadcast notification: %v\n", err)
+	}
+}
+
+// settleIfCovered marks a locked pending transaction paid once its paid payments cover the total
+func (pms *PaymentService) settleIfCovered(tx *gorm.DB, transaction *models.Transaction) (*statusAlerts, error) {
+	var methods []string
+	if err := tx.Model(&models.Payment{}).
+		Where("transaction_id = ? AND status = ?", transaction.Id, models.PaymentStatusPaid).
+		Distinct().Pluck("method", &methods).Error; err != nil {
+		return nil, err
+	}
+
+	summary, err := paymentSummary(tx, transaction)
+	if err != nil || summary.PaidAmount < transaction.TotalAmount {
+		return nil, err
+	}
+
+	transaction.PaymentMethod = models.PaymentMethodSplit
+	if len(methods) == 1 {
+		transaction.PaymentMethod = methods[0]
+	}
+
+	return pms.transactionService.changeStatus(tx, transaction, models.PaymentStatusPaid)
+}
+
+// splitPayment decides the amount of a new payment from the split asked for, capped at what is left to pay
+func splitPayment(tx *gorm.DB, transaction *models.Transaction, summary *PaymentSummary, req *structs.PaymentCreateRequest, payment *models.Payment) error {
+	remaining := summary.Remaining
+
+	switch {
+	case len(req.DetailIds) > 0:
+		var details []models.TransactionDetail
+		if err := tx.Where("id IN ? AND transaction_id = ? AND parent_detail_id IS NULL AND voided_at IS NULL", req.DetailIds, transaction.Id).
+			Find(&details).Error; err != nil {
+			return err
+		}
+		if len(details) != len(req.DetailIds) {
+			return invalidError("invalid detail_ids, some order lines are not part of this order")
+		}
+
+		var taken int64
+		if err := tx.Model(&models.PaymentLine{}).
+			Joins("JOIN payments ON payments.id = payment_lines.payment_id").
+			Where("payment_lines.transaction_detail_id IN ? AND payments.status IN ?", req.DetailIds,
+				[]string{models.PaymentStatusPending, models.PaymentStatusPaid}).
+			Count(&taken).Error; err != nil {
+			return fmt.Errorf("failed to load paid lines: %v", err)
+		}
+		if taken > 0 {
+			return invalidError("invalid detail_ids, some order lines are already paid for")
+		}
+
+		// Points redeemed on the order lower every line in proportion
+		net := transaction.SubTotal - transaction.DiscountAmount
+		var amount uint
+		for _, detail := range details {
+			lineAmount := detail.TotalPrice
+			if net > 0 {
+				lineAmount = uint(uint64(detail.TotalPrice) * uint64(transaction.TotalAmount) / uint64(net))
+			}
+			payment.Lines = append(payment.Lines, models.PaymentLine{TransactionDetailId: detail.Id, Amount: lineAmount})
+			amount += lineAmount
+		}
+
+		var unpaidLines int64
+		if err := tx.Model(&models.TransactionDetail{}).
+			Where("transaction_id = ? AND parent_detail_id IS NULL AND voided_at IS NULL", transaction.Id).
+			Where("id NOT IN (?)", tx.Model(&models.PaymentLine{}).
+				Select("payment_lines.transaction_detail_id").
+				Joins("JOIN payments ON payments.id = payment_lines.payment_id").
+				Where("payments.transaction_id = ? AND payments.status IN ?", transaction.Id,
+					[]string{models.PaymentStatusPending, models.PaymentStatusPaid})).
+			Count(&unpaidLines).Error; err != nil {
+			return fmt.Errorf("failed to load unpaid lines: %v", err)
+		}
+
+		// The payment for the last unpaid lines takes the rounding left over
+		if int(unpaidLines) == len(details) || amount > remaining {
+			amount = remaining
+		}
+		payment.Amount = amount
+		payment.SplitType = models.PaymentSplitItems
+		payment.SplitLabel = fmt.Sprintf("%d item(s)", len(details))
+	case req.People > 0:
+		var earlier int64
+		tx.Model(&models.Payment{}).
+			Where("transaction_id = ? AND split_type = ? AND status IN ?", transaction.Id, models.PaymentSplitEven,
+				[]string{models.PaymentStatusPending, models.PaymentStatusPaid}).
+			Count(&earlier)
+		if uint(earlier) >= req.People {
+			return invalidError("invalid people, the bill was already split between %d people", earlier)
+		}
+
+		share := (transaction.TotalAmount + req.People - 1) / req.People
+		payment.Amount = min(share, remaining)
+		payment.SplitType = models.PaymentSplitEven
+		payment.SplitLabel = fmt.Sprintf("%d of %d", earlier+1, req.People)
+	case req.Amount > 0:
+		if req.Amount > remaining {
+			return invalidError("invalid amount, only %d is left to pay", remaining)
+		}
+		payment.Amount = req.Amount
+		payment.SplitType = models.PaymentSplitAmount
+	default:
+		payment.Amount = remaining
+		payment.SplitType = models.PaymentSplitAmount
+	}
+
+	if payment.Amount == 0 {
+		return invalidError("invalid payment, nothing is left to pay for this split")
+	}
+
+	return nil
+}
+
+// paymentSummary adds up the payments of a transaction, pending payments hold their share of the bill
+func paymentSummary(db *gorm.DB, transaction *models.Transaction) (*PaymentSummary, error) {
+	var payments []models.Payment
+	if err := db.Preload("Lines").Where("transaction_id = ?", transaction.Id).Order("created_at ASC, id ASC").Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load payments: %v", err)
+	}
+
+	summary := &PaymentSummary{Transaction: *transaction, Payments: payments}
+	for _, payment := range payments {
+		switch payment.Status {
+		case models.PaymentStatusPaid:
+			summary.PaidAmount += payment.Amount
+		case models.PaymentStatusPending:
+			summary.PendingAmount += payment.Amount
+		}
+	}
+	if covered := summary.PaidAmount + summary.PendingAmount; covered < transaction.TotalAmount {
+		summary.Remaining = transaction.TotalAmount - covered
+	}
+
+	return summary, nil
+}
+
+// checkPaymentsAllowStatus keeps a status change in line with the payments of a transaction: it is only paid
+// once its payments cover it, and money already taken has to be refunded before it can be called off
+func checkPaymentsAllowStatus(tx *gorm.DB, transaction *models.Transaction, status string) error {
+	summary, err := paymentSummary(tx, transaction)
+	if err != nil || len(summary.Payments) == 0 {
+		return err
+	}
+
+	switch status {
+	case models.PaymentStatusPaid:
+		if summary.PaidAmount < transaction.TotalAmount {
+			return invalidError("invalid status change, payments cover %d of %d", summary.PaidAmount, transaction.TotalAmount)
+		}
+	case models.PaymentStatusFailed, models.PaymentStatusExpired, models.PaymentStatusCancelled:
+		if summary.PaidAmount > 0 {
+			return invalidError("invalid status change, %d is already paid on this order", summary.PaidAmount)
+		}
+		return tx.Model(&models.Payment{}).
+			Where("transaction_id = ? AND status = ?", transaction.Id, models.PaymentStatusPending).
+			Update("status", models.PaymentStatusCancelled).Error
+	}
+
+	return nil
+}
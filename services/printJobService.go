@@ -0,0 +1,466 @@
+package services
+
+import (
+	"deck/config"
+	"deck/helpers"
+	"deck/models"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PrintJobService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+func NewPrintJobService(db *gorm.DB, notificationService *NotificationService) *PrintJobService {
+	return &PrintJobService{
+		db:                  db,
+		notificationService: notificationService,
+	}
+}
+
+// kitchenTicket is what a station gets to prepare: only its items, without prices
+type kitchenTicket struct {
+	StationName string
+	OrderNumber string
+	OrderType   string
+	TableName   string
+	BuyerName   string
+	Date        time.Time
+	Reprint     bool
+	Items       []kitchenTicketItem
+}
+
+type kitchenTicketItem struct {
+	Name      string
+	Quantity  uint
+	Modifiers []string
+	Notes     string
+}
+
+// Get All Print Jobs, newest first, optionally filtered by status and transaction
+func (pjs *PrintJobService) GetPrintJobs(status string, transactionId uint) ([]models.PrintJob, error) {
+	query := pjs.db.Preload("Station").Preload("Printer").Preload("Transaction", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "order_number")
+	}).Order("created_at DESC, id DESC").Limit(200)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if transactionId != 0 {
+		query = query.Where("transaction_id = ?", transactionId)
+	}
+
+	var jobs []models.PrintJob
+	err := query.Find(&jobs).Error
+
+	return jobs, err
+}
+
+// Get Print Job By Id
+func (pjs *PrintJobService) GetPrintJobById(id uint) (*models.PrintJob, error) {
+	var job models.PrintJob
+	if err := pjs.db.Preload("Station").Preload("Printer").Preload("Transaction", func(db *gorm.DB) *gorm.DB {
+		return db.Select("id", "order_number")
+	}).First(&job, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("print job not found")
+		}
+		return nil, fmt.Errorf("failed to find print job: %v", err)
+	}
+
+	return &job, nil
+}
+
+// ReprintKitchenTickets queues the tickets of a paid transaction again, for one station or all of them
+func (pjs *PrintJobService) ReprintKitchenTickets(transactionId uint, stationId *uint, createdBy string) ([]models.PrintJob, error) {
+	var transaction models.Transaction
+	if err := pjs.db.First(&transaction, transactionId).Error; err != nil {
+		return nil, notFoundError("transaction not found")
+	}
+	if transaction.PaymentStatus != models.PaymentStatusPaid {
+		return nil, invalidError("invalid reprint, transaction is %s", transaction.PaymentStatus)
+	}
+	if stationId != nil {
+		if err := pjs.db.First(&models.Station{}, *stationId).Error; err != nil {
+			return nil, notFoundError("station not found")
+		}
+	}
+
+	jobs, err := queueKitchenTickets(pjs.db, &transaction, stationId, models.PrintJobKindReprint, createdBy)
+	if err != nil {
+		return nil, err
+	}
+	if len(jobs) == 0 {
+		return nil, invalidError("invalid reprint, no station with a printer has items of this order")
+	}
+
+	ids := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		ids = append(ids, job.Id)
+	}
+	var queued []models.PrintJob
+	err = pjs.db.Preload("Station").Preload("Printer").Where("id IN ?", ids).Order("id ASC").Find(&queued).Error
+
+	return queued, err
+}
+
+// RetryPrintJob sends a failed job to its printer again
+func (pjs *PrintJobService) RetryPrintJob(id uint) (*models.PrintJob, error) {
+	job, err := pjs.GetPrintJobById(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status != models.PrintJobStatusFailed {
+		return nil, invalidError("invalid retry, print job is %s", job.Status)
+	}
+
+	if err := pjs.db.Model(&models.PrintJob{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"status":          models.PrintJobStatusPending,
+		"attempts":        0,
+		"next_attempt_at": time.Now(),
+	}).Error; err != nil {
+		return nil, fmt.Errorf("failed to retry print job: %v", err)
+	}
+
+	return pjs.GetPrintJobById(id)
+}
+
+// ProcessPrintJobs sends the print jobs that are due to their printers and returns how many were printed.
+// Failed sends are retried with a growing delay until PRINT_MAX_ATTEMPTS, then the admins are notified.
+func (pjs *PrintJobService) ProcessPrintJobs() (int, error) {
+	jobs, err := pjs.claimDuePrintJobs()
+	if err != nil {
+		return 0, err
+	}
+
+	printed := 0
+	for _, job := range jobs {
+		var sendErr error
+		if job.Printer.IsActive {
+			sendErr = sendToPrinter(net.JoinHostPort(job.Printer.Host, strconv.Itoa(int(job.Printer.Port))), job.Payload)
+		} else {
+			sendErr = fmt.Errorf("printer %s is inactive", job.Printer.Name)
+		}
+
+		attempts := job.Attempts + 1
+		updates := map[string]interface{}{"attempts": attempts}
+		if sendErr == nil {
+			updates["status"] = models.PrintJobStatusPrinted
+			updates["printed_at"] = time.Now()
+			updates["last_error"] = ""
+			printed++
+		} else if attempts >= printMaxAttempts() {
+			updates["status"] = models.PrintJobStatusFailed
+			updates["last_error"] = sendErr.Error()
+		} else {
+			updates["status"] = models.PrintJobStatusPending
+			updates["last_error"] = sendErr.Error()
+			updates["next_attempt_at"] = time.Now().Add(printRetryDelay(attempts))
+		}
+
+		if err := pjs.db.Model(&models.PrintJob{}).Where("id = ?", job.Id).Updates(updates).Error; err != nil {
+			fmt.Printf("Failed to update print job %d: %v\n", job.Id, err)
+			continue
+		}
+		if updates["status"] == models.PrintJobStatusFailed {
+			pjs.notifyPrintFailed(job, sendErr)
+		}
+	}
+
+	return printed, nil
+}
+
+// StartPrintWorker sends the queued print jobs at each interval, it blocks so start it in its own goroutine
+func (pjs *PrintJobService) StartPrintWorker(interval time.Duration) {
+	// Jobs left printing by a previous run never got their result recorded, send them again
+	pjs.db.Model(&models.PrintJob{}).Where("status = ?", models.PrintJobStatusPrinting).
+		Update("status", models.PrintJobStatusPending)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := pjs.ProcessPrintJobs(); err != nil {
+			fmt.Printf("Failed to process print jobs: %v\n", err)
+		}
+	}
+}
+
+// claimDuePrintJobs marks the due jobs as printing so a second worker does not send them too
+func (pjs *PrintJobService) claimDuePrintJobs() ([]models.PrintJob, error) {
+	var jobs []models.PrintJob
+	err := pjs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND next_attempt_at <= ?", models.PrintJobStatusPending, time.Now()).
+			Order("next_attempt_at ASC, id ASC").Limit(20).Find(&jobs).Error; err != nil {
+			return err
+		}
+		if len(jobs) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, 0, len(jobs))
+		for _, job := range jobs {
+			ids = append(ids, job.Id)
+		}
+
+		return tx.Model(&models.PrintJob{}).Where("id IN ?", ids).Update("status", models.PrintJobStatusPrinting).Error
+	})
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	printerIds := make([]uint, 0, len(jobs))
+	for _, job := range jobs {
+		printerIds = append(printerIds, job.PrinterId)
+	}
+	var printers []models.Printer
+	if err := pjs.db.Where("id IN ?", uniqueIds(printerIds)).Find(&printers).Error; err != nil {
+		return nil, err
+	}
+	printersById := map[uint]*models.Printer{}
+	for i := range printers {
+		printersById[printers[i].Id] = &printers[i]
+	}
+	for i := range jobs {
+		jobs[i].Printer = printersById[jobs[i].PrinterId]
+		if jobs[i].Printer == nil {
+			jobs[i].Printer = &models.Printer{Name: "deleted printer"}
+		}
+	}
+
+	return jobs, nil
+}
+
+func (pjs *PrintJobService) notifyPrintFailed(job models.PrintJob, sendErr error) {
+	var ticket struct {
+		OrderNumber string
+		StationName string
+	}
+	pjs.db.Table("print_jobs").
+		Select("transactions.order_number, stations.name AS station_name").
+		Joins("JOIN transactions ON transactions.id = print_jobs.transaction_id").
+		Joins("JOIN stations ON stations.id = print_jobs.station_id").
+		Where("print_jobs.id = ?", job.Id).Scan(&ticket)
+
+	title := "Tiket Gagal Dicetak"
+	message := fmt.Sprintf("Tiket %s untuk %s gagal dicetak di %s: %v", ticket.OrderNumber, ticket.StationName, job.Printer.Name, sendErr)
+	data := map[string]interface{}{
+		"print_job_id":   job.Id,
+		"transaction_id": job.TransactionId,
+		"order_number":   ticket.OrderNumber,
+		"station":        ticket.StationName,
+		"printer":        job.Printer.Name,
+	}
+	if err := pjs.notificationService.BroadcastToAdmins("print_failed", title, message, data); err != nil {
+		fmt.Printf("Failed to broadcast notification: %v\n", err)
+	}
+}
+
+// queueKitchenTickets splits a transaction into one ticket per station by product category and queues them
+// on the station printers. Products in no station category are not printed, nor are stations without a printer.
+func queueKitchenTickets(tx *gorm.DB, transaction *models.Transaction, stationId *uint, kind string, createdBy string) ([]models.PrintJob, error) {
+	stationQuery := tx.Preload("Printer").Preload("Categories").
+		Where("is_active = ? AND printer_id IS NOT NULL", true).Order("name ASC")
+	if stationId != nil {
+		stationQuery = stationQuery.Where("id = ?", *stationId)
+	}
+	var stations []models.Station
+	if err := stationQuery.Find(&stations).Error; err != nil {
+		return nil, fmt.Errorf("failed to load stations: %v", err)
+	}
+	if len(stations) == 0 {
+		return nil, nil
+	}
+
+	var details []models.TransactionDetail
+	if err := tx.Preload("Modifiers").Preload("Components", func(db *gorm.DB) *gorm.DB {
+		return db.Where("voided_at IS NULL").Order("id ASC")
+	}).Where("transaction_id = ? AND parent_detail_id IS NULL AND voided_at IS NULL", transaction.Id).
+		Order("id ASC").Find(&details).Error; err != nil {
+		return nil, fmt.Errorf("failed to load order lines: %v", err)
+	}
+
+	// Bundles are prepared by their components, each printed where its own category is made
+	var items []kitchenTicketItem
+	var productIds []uint
+	for _, detail := range details {
+		if len(detail.Components) == 0 {
+			item := kitchenTicketItem{Name: detailName(detail), Quantity: detail.Quantity, Notes: detail.Notes}
+			for _, modifier := range detail.Modifiers {
+				item.Modifiers = append(item.Modifiers, modifier.Name)
+			}
+			items = append(items, item)
+			productIds = append(productIds, detail.ProductId)
+			continue
+		}
+		for _, component := range detail.Components {
+			items = append(items, kitchenTicketItem{
+				Name:     detailName(component) + " (" + detailName(detail) + ")",
+				Quantity: component.Quantity,
+				Notes:    detail.Notes,
+			})
+			productIds = append(productIds, component.ProductId)
+		}
+	}
+
+	var products []models.Product
+	if err := tx.Select("id", "category_id").Where("id IN ?", uniqueIds(productIds)).Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to load products: %v", err)
+	}
+	categoryOf := map[uint]uint{}
+	for _, product := range products {
+		categoryOf[product.Id] = product.CategoryId
+	}
+
+	ticket := kitchenTicket{
+		OrderNumber: transaction.OrderNumber,
+		OrderType:   receiptOrderTypes[transaction.OrderType],
+		BuyerName:   transaction.BuyerName,
+		Date:        time.Now().In(config.Location()),
+		Reprint:     kind == models.PrintJobKindReprint,
+	}
+	if transaction.TableId != nil {
+		var table models.Table
+		if err := tx.Select("name").First(&table, *transaction.TableId).Error; err == nil {
+			ticket.TableName = table.Name
+		}
+	}
+
+	var jobs []models.PrintJob
+	for _, station := range stations {
+		categories := map[uint]bool{}
+		for _, category := range station.Categories {
+			categories[category.Id] = true
+		}
+
+		ticket.StationName = station.Name
+		ticket.Items = nil
+		for i, item := range items {
+			if categories[categoryOf[productIds[i]]] {
+				ticket.Items = append(ticket.Items, item)
+			}
+		}
+		if len(ticket.Items) == 0 {
+			continue
+		}
+
+		width := ReceiptWidth80mm
+		if station.Printer.PaperWidth == 58 {
+			width = ReceiptWidth58mm
+		}
+		job := models.PrintJob{
+			TransactionId: transaction.Id,
+			StationId:     station.Id,
+			PrinterId:     station.Printer.Id,
+			Kind:          kind,
+			Payload:       renderKitchenTicket(ticket, width),
+			Status:        models.PrintJobStatusPending,
+			NextAttemptAt: time.Now(),
+			CreatedBy:     createdBy,
+		}
+		if err := tx.Create(&job).Error; err != nil {
+			return nil, fmt.Errorf("failed to queue ticket: %v", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
+
+func renderKitchenTicket(ticket kitchenTicket, width int) []byte {
+	printer := helpers.NewEscPos().
+		Align(helpers.EscPosAlignCenter).Bold(true).Size(2, 2)
+	for _, line := range helpers.WrapText(strings.ToUpper(ticket.StationName), width/2) {
+		printer.Line(line)
+	}
+	printer.Size(1, 1)
+	if ticket.Reprint {
+		printer.Line("** CETAK ULANG **")
+	}
+	printer.Bold(false).Align(helpers.EscPosAlignLeft).
+		Line(strings.Repeat("=", width)).
+		Line(helpers.PadColumns(ticket.OrderNumber, ticket.Date.Format("15:04"), width))
+	if ticket.TableName != "" {
+		printer.Bold(true).Size(2, 2).Line("MEJA "+ticket.TableName).Size(1, 1).Bold(false)
+	}
+	if ticket.OrderType != "" {
+		printer.Line(ticket.OrderType)
+	}
+	if ticket.BuyerName != "" {
+		printer.Line("Pelanggan: " + ticket.BuyerName)
+	}
+	printer.Line(strings.Repeat("=", width))
+
+	for _, item := range ticket.Items {
+		quantity := fmt.Sprintf("%dx ", item.Quantity)
+		indent := strings.Repeat(" ", len(quantity))
+		printer.Bold(true)
+		for i, line := range helpers.WrapText(item.Name, width-len(quantity)) {
+			if i == 0 {
+				printer.Line(quantity + line)
+			} else {
+				printer.Line(indent + line)
+			}
+		}
+		printer.Bold(false)
+		for _, modifier := range item.Modifiers {
+			for _, line := range helpers.WrapText("+ "+modifier, width-len(indent)) {
+				printer.Line(indent + line)
+			}
+		}
+		if item.Notes != "" {
+			for _, line := range helpers.WrapText("* "+item.Notes, width-len(indent)) {
+				printer.Line(indent + line)
+			}
+		}
+	}
+
+	return printer.Line(strings.Repeat("-", width)).Feed(4).Cut().Bytes()
+}
+
+// sendToPrinter writes raw ESC/POS bytes to a network printer, the way port 9100 printers take jobs
+func sendToPrinter(address string, payload []byte) error {
+	conn, err := net.DialTimeout("tcp", address, 5*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to reach printer: %v", err)
+	}
+	defer conn.Close()
+
+	if err := conn.SetWriteDeadline(time.Now().Add(10 * time.Second)); err != nil {
+		return err
+	}
+	if _, err := conn.Write(payload); err != nil {
+		return fmt.Errorf("failed to send to printer: %v", err)
+	}
+
+	return nil
+}
+
+// printMaxAttempts is how many times a job is sent before it is marked failed, PRINT_MAX_ATTEMPTS defaults to 5
+func printMaxAttempts() uint {
+	attempts, err := strconv.Atoi(config.GetEnv("PRINT_MAX_ATTEMPTS", "5"))
+	if err != nil || attempts < 1 {
+		return 5
+	}
+
+	return uint(attempts)
+}
+
+// printRetryDelay doubles from 5 seconds after each failed attempt, up to 5 minutes
+func printRetryDelay(attempts uint) time.Duration {
+	delay := 5 * time.Second
+	for i := uint(1); i < attempts && delay < 5*time.Minute; i++ {
+		delay *= 2
+	}
+
+	return min(delay, 5*time.Minute)
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestSendToPrinter(t *testing.T) {
+	// A local socket stands in for a printer listening on port 9100
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	received := make(chan []byte, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			received <- nil
+			return
+		}
+		defer conn.Close()
+		data, _ := io.ReadAll(conn)
+		received <- data
+	}()
+
+	ticket := renderKitchenTicket(kitchenTicket{
+		StationName: "Bar",
+		OrderNumber: "ORD-20260101-0042",
+		OrderType:   "Makan di tempat",
+		TableName:   "A3",
+		Date:        time.Date(2026, 1, 1, 13, 45, 0, 0, time.UTC),
+		Items: []kitchenTicketItem{
+			{Name: "Kopi Susu Gula Aren Large", Quantity: 2, Modifiers: []string{"Extra shot"}, Notes: "Es dipisah"},
+		},
+	}, ReceiptWidth58mm)
+
+	if err := sendToPrinter(listener.Addr().String(), ticket); err != nil {
+		t.Fatalf("sendToPrinter: %v", err)
+	}
+
+	select {
+	case got := <-received:
+		if !bytes.Equal(got, ticket) {
+			t.Errorf("printer received %q, want %q", got, ticket)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("printer received nothing")
+	}
+
+	for _, want := range []string{"BAR", "MEJA A3", "2x Kopi Susu Gula Aren Large", "+ Extra shot", "* Es dipisah"} {
+		if !bytes.Contains(ticket, []byte(want)) {
+			t.Errorf("ticket is missing %q", want)
+		}
+	}
+}
+
+func TestSendToPrinterUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	address := listener.Addr().String()
+	listener.Close()
+
+	if err := sendToPrinter(address, []byte("ticket")); err == nil {
+		t.Error("sendToPrinter succeeded on a closed port, want an error")
+	}
+}
+
+func TestPrintRetryDelay(t *testing.T) {
+	tests := []struct {
+		attempts uint
+		want     time.Duration
+	}{
+		{1, 5 * time.Second},
+		{2, 10 * time.Second},
+		{4, 40 * time.Second},
+		{10, 5 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := printRetryDelay(tt.attempts); got != tt.want {
+			t.Errorf("printRetryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type PrinterService struct {
+	db *gorm.DB
+}
+
+func NewPrinterService(db *gorm.DB) *PrinterService {
+	return &PrinterService{db: db}
+}
+
+// Get All Printers
+func (prs *PrinterService) GetPrinters() ([]models.Printer, error) {
+	var printers []models.Printer
+	err := prs.db.Order("name ASC").Find(&printers).Error
+
+	return printers, err
+}
+
+// Create Printer
+func (prs *PrinterService) CreatePrinter(req *structs.PrinterRequest) (*models.Printer, error) {
+	printer := models.Printer{IsActive: true}
+	if err := prs.fillPrinter(&printer, req, 0); err != nil {
+		return nil, err
+	}
+
+	if err := prs.db.Create(&printer).Error; err != nil {
+		return nil, fmt.Errorf("failed to create printer: %v", err)
+	}
+
+	return &printer, nil
+}
+
+// Update Printer
+func (prs *PrinterService) UpdatePrinter(id uint, req *structs.PrinterRequest) (*models.Printer, error) {
+	var printer models.Printer
+	if err := prs.db.First(&printer, id).Error; err != nil {
+		return nil, notFoundError("printer not found")
+	}
+
+	if err := prs.fillPrinter(&printer, req, id); err != nil {
+		return nil, err
+	}
+
+	if err := prs.db.Save(&printer).Error; err != nil {
+		return nil, fmt.Errorf("failed to update printer: %v", err)
+	}
+
+	return &printer, nil
+}
+
+// Delete Printer when no station prints on it and it never printed a ticket
+func (prs *PrinterService) DeletePrinter(id uint) error {
+	var printer models.Printer
+	if err := prs.db.First(&printer, id).Error; err != nil {
+		return notFoundError("printer not found")
+	}
+
+	var stations, jobs int64
+	prs.db.Model(&models.Station{}).Where("printer_id = ?", id).Count(&stations)
+	if stations > 0 {
+		return invalidError("printer %s is in use by %d station(s)", printer.Name, stations)
+	}
+	prs.db.Model(&models.PrintJob{}).Where("printer_id = ?", id).Count(&jobs)
+	if jobs > 0 {
+		return invalidError("printer %s is in use by %d print job(s), deactivate it instead", printer.Name, jobs)
+	}
+
+	if err := prs.db.Delete(&printer).Error; err != nil {
+		return fmt.Errorf("failed to delete printer: %v", err)
+	}
+
+	return nil
+}
+
+// Get All Stations with their printer and categories
+func (prs *PrinterService) GetStations() ([]models.Station, error) {
+	var stations []models.Station
+	err := prs.db.Preload("Printer").Preload("Categories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, display_name ASC")
+	}).Order("name ASC").Find(&stations).Error
+
+	return stations, err
+}
+
+// Get Station By Id
+func (prs *PrinterService) GetStationById(id uint) (*models.Station, error) {
+	var station models.Station
+	if err := prs.db.Preload("Printer").Preload("Categories", func(db *gorm.DB) *gorm.DB {
+		return db.Order("sort_order ASC, display_name ASC")
+	}).First(&station, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("station not found")
+		}
+		return nil, fmt.Errorf("failed to find station: %v", err)
+	}
+
+	return &station, nil
+}
+
+// Create Station
+func (prs *PrinterService) CreateStation(req *structs.StationRequest) (*models.Station, error) {
+	station := models.Station{IsActive: true}
+	categories, err := prs.fillStation(&station, req, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	err = prs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories").Create(&station).Error; err != nil {
+			return fmt.Errorf("failed to create station: %v", err)
+		}
+
+		return tx.Model(&station).Association("Categories").Replace(categories)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return prs.GetStationById(station.Id)
+}
+
+// Update Station, the categories given replace the ones it had
+func (prs *PrinterService) UpdateStation(id uint, req *structs.StationRequest) (*models.Station, error) {
+	station, err := prs.GetStationById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, err := prs.fillStation(station, req, id)
+	if err != nil {
+		return nil, err
+	}
+	station.Printer = nil
+
+	err = prs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories").Save(station).Error; err != nil {
+			return fmt.Errorf("failed to update station: %v", err)
+		}
+
+		return tx.Model(station).Association("Categories").Replace(categories)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return prs.GetStationById(id)
+}
+
+// Delete Station when it never printed a ticket
+func (prs *PrinterService) DeleteStation(id uint) error {
+	station, err := prs.GetStationById(id)
+	if err != nil {
+		return err
+	}
+
+	var jobs int64
+	prs.db.Model(&models.PrintJob{}).Where("station_id = ?", id).Count(&jobs)
+	if jobs > 0 {
+		return invalidError("station %s is in use by %d print job(s), deactivate it instead", station.Name, jobs)
+	}
+
+	return prs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(station).Association("Categories").Clear(); err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Station{}, id).Error; err != nil {
+			return fmt.Errorf("failed to delete station: %v", err)
+		}
+
+		return nil
+	})
+}
+
+func (prs *PrinterService) fillPrinter(printer *models.Printer, req *structs.PrinterRequest, id uint) error {
+	name := strings.TrimSpace(req.Name)
+	var count int64
+	prs.db.Model(&models.Printer{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, id).Count(&count)
+	if count > 0 {
+		return invalidError("printer %s already exists", name)
+	}
+
+	printer.Name = name
+	printer.Host = strings.TrimSpace(req.Host)
+	printer.Port = req.Port
+	if printer.Port == 0 {
+		printer.Port = 9100
+	}
+	printer.PaperWidth = req.PaperWidth
+	if printer.PaperWidth == 0 {
+		printer.PaperWidth = 80
+	}
+	if req.IsActive != nil {
+		printer.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+func (prs *PrinterService) fillStation(station *models.Station, req *structs.StationRequest, id uint) ([]models.Category, error) {
+	name := strings.TrimSpace(req.Name)
+	var count int64
+	prs.db.Model(&models.Station{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, id).Count(&count)
+	if count > 0 {
+		return nil, invalidError("station %s already exists", name)
+	}
+
+	if req.PrinterId != nil {
+		if err := prs.db.First(&models.Printer{}, *req.PrinterId).Error; err != nil {
+			return nil, invalidError("invalid printer_id, printer not found")
+		}
+	}
+
+	categories := []models.Category{}
+	if len(req.CategoryIds) > 0 {
+		if err := prs.db.Where("id IN ?", req.CategoryIds).Find(&categories).Error; err != nil {
+			return nil, err
+		}
+		if len(categories) != len(uniqueIds(req.CategoryIds)) {
+			return nil, invalidError("invalid category_ids, category not found")
+		}
+	}
+
+	station.Name = name
+	station.PrinterId = req.PrinterId
+	if req.IsActive != nil {
+		station.IsActive = *req.IsActive
+	}
+
+	return categories, nil
+}
@@ -0,0 +1,537 @@
+package services
+
+import (
+	"archive/zip"
+	"deck/helpers"
+	"deck/models"
+	"deck/structs"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/xuri/excelize/v2"
+	"gorm.io/gorm"
+)
+
+// productImportColumns is the column layout of exports, imports match headers by name so the order is free
+var productImportColumns = []string{"sku", "barcode", "name", "category", "price", "description", "is_available", "image"}
+
+// productImportFields maps ProductCreateRequest fields to the column they come from
+var productImportFields = map[string]string{
+	"Name":        "name",
+	"Price":       "price",
+	"Category":    "category",
+	"Description": "description",
+	"IsAvailable": "is_available",
+}
+
+const (
+	ProductImportCreate  = "create"
+	ProductImportUpdate  = "update"
+	ProductImportInvalid = "invalid"
+)
+
+type ProductImportService struct {
+	db             *gorm.DB
+	productService *ProductService
+	priceService   *ProductPriceService
+}
+
+func NewProductImportService(db *gorm.DB, productService *ProductService, priceService *ProductPriceService) *ProductImportService {
+	return &ProductImportService{db: db, productService: productService, priceService: priceService}
+}
+
+// productImportRow is a parsed row together with what importing it will do
+type productImportRow struct {
+	result   structs.ProductImportRowResult
+	req      structs.ProductCreateRequest
+	sku      *string
+	barcode  *string
+	category *models.Category
+	image    *zip.File
+	existing *models.Product
+}
+
+// Export every product as CSV or XLSX in the import layout
+func (pis *ProductImportService) ExportProducts(format string, w io.Writer) error {
+	var products []models.Product
+	if err := pis.db.Preload("Category").Order("name ASC, id ASC").Find(&products).Error; err != nil {
+		return fmt.Errorf("failed to load products: %v", err)
+	}
+
+	rows := make([][]string, 0, len(products)+1)
+	rows = append(rows, productImportColumns)
+	for _, product := range products {
+		sku, barcode := "", ""
+		if product.Sku != nil {
+			sku = *product.Sku
+		}
+		if product.Barcode != nil {
+			barcode = *product.Barcode
+		}
+		rows = append(rows, []string{
+			sku,
+			barcode,
+			product.Name,
+			product.Category.Slug,
+			strconv.FormatUint(uint64(product.Price), 10),
+			product.Description,
+			strconv.FormatBool(product.IsAvailable),
+			product.Image,
+		})
+	}
+
+	switch format {
+	case "csv":
+		writer := csv.NewWriter(w)
+		if err := writer.WriteAll(rows); err != nil {
+			return fmt.Errorf("failed to write csv: %v", err)
+		}
+		return nil
+	case "xlsx":
+		file := excelize.NewFile()
+		defer file.Close()
+
+		sheet := file.GetSheetName(0)
+		for i, row := range rows {
+			cell, _ := excelize.CoordinatesToCellName(1, i+1)
+			values := make([]interface{}, len(row))
+			for j, value := range row {
+				values[j] = value
+			}
+			if err := file.SetSheetRow(sheet, cell, &values); err != nil {
+				return fmt.Errorf("failed to write xlsx: %v", err)
+			}
+		}
+
+		return file.Write(w)
+	default:
+		return invalidError("invalid format, use csv or xlsx")
+	}
+}
+
+// Import products from a CSV or XLSX file, optionally with a zip of the images named in the image column.
+// Rows are matched to existing products by SKU first and then by name. Every row is validated before anything
+// is written and the whole import runs in one transaction, so a file with any invalid row changes nothing.
+func (pis *ProductImportService) ImportProducts(file *multipart.FileHeader, images *multipart.FileHeader, dryRun bool, createdBy string) (*structs.ProductImportReport, error) {
+	table, err := readProductImportTable(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var bundle map[string]*zip.File
+	if images != nil {
+		var closer io.Closer
+		bundle, closer, err = openProductImageBundle(images)
+		if err != nil {
+			return nil, err
+		}
+		defer closer.Close()
+	}
+
+	rows, err := pis.parseRows(table, bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &structs.ProductImportReport{
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Rows:      make([]structs.ProductImportRowResult, 0, len(rows)),
+	}
+	for _, row := range rows {
+		switch row.result.Action {
+		case ProductImportCreate:
+			report.Created++
+		case ProductImportUpdate:
+			report.Updated++
+		default:
+			report.Invalid++
+		}
+	}
+
+	if dryRun || report.Invalid > 0 {
+		for _, row := range rows {
+			report.Rows = append(report.Rows, row.result)
+		}
+		return report, nil
+	}
+
+	var written, replaced []string
+	err = pis.db.Transaction(func(tx *gorm.DB) error {
+		for _, row := range rows {
+			imageName := ""
+			if row.image != nil {
+				var err error
+				imageName, err = saveBundledImage(row.image)
+				if err != nil {
+					return fmt.Errorf("row %d: %v", row.result.Row, err)
+				}
+				written = append(written, imageName)
+			}
+
+			if row.existing == nil {
+				product := models.Product{
+					Name:        row.req.Name,
+					Sku:         row.sku,
+					Barcode:     row.barcode,
+					Type:        models.ProductTypeSingle,
+					Price:       row.req.Price,
+					CategoryId:  row.category.Id,
+					Description: row.req.Description,
+					IsAvailable: row.req.IsAvailable,
+					Image:       imageName,
+				}
+				if err := tx.Create(&product).Error; err != nil {
+					return fmt.Errorf("row %d: failed to create product: %v", row.result.Row, err)
+				}
+				if err := recordInitialPrice(tx, &product); err != nil {
+					return err
+				}
+				row.result.ProductId = product.Id
+			} else {
+				updates := map[string]interface{}{
+					"name":         row.req.Name,
+					"price":        row.req.Price,
+					"category_id":  row.category.Id,
+					"description":  row.req.Description,
+					"is_available": row.req.IsAvailable,
+				}
+				if row.sku != nil {
+					updates["sku"] = *row.sku
+				}
+				if row.barcode != nil {
+					updates["barcode"] = *row.barcode
+				}
+				if imageName != "" {
+					updates["image"] = imageName
+					if row.existing.Image != "" {
+						replaced = append(replaced, row.existing.Image)
+					}
+				}
+				if err := tx.Model(row.existing).Updates(updates).Error; err != nil {
+					return fmt.Errorf("row %d: failed to update product: %v", row.result.Row, err)
+				}
+				if row.existing.Price != row.req.Price {
+					if err := pis.priceService.RecordPriceChange(tx, row.existing.Id, row.req.Price, createdBy); err != nil {
+						return err
+					}
+				}
+			}
+
+			report.Rows = append(report.Rows, row.result)
+		}
+
+		return nil
+	})
+	if err != nil {
+		removeUploadedImages(written)
+		return nil, fmt.Errorf("failed to import products: %v", err)
+	}
+
+	go removeUploadedImages(replaced)
+
+	return report, nil
+}
+
+// parseRows validates every row against the ProductCreateRequest rules and resolves its category, image and
+// the product it updates
+func (pis *ProductImportService) parseRows(table [][]string, bundle map[string]*zip.File) ([]*productImportRow, error) {
+	if len(table) == 0 {
+		return nil, invalidError("invalid file, it has no header row")
+	}
+
+	columns := make(map[string]int)
+	for i, header := range table[0] {
+		columns[strings.ToLower(strings.TrimSpace(header))] = i
+	}
+	for _, required := range []string{"name", "category", "price"} {
+		if _, ok := columns[required]; !ok {
+			return nil, invalidError("invalid file, missing column %s", required)
+		}
+	}
+
+	var rows []*productImportRow
+	seen := make(map[string]int)
+	for i, record := range table[1:] {
+		cell := func(column string) string {
+			index, ok := columns[column]
+			if !ok || index >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[index])
+		}
+
+		if strings.Join(record, "") == "" {
+			continue
+		}
+
+		row := &productImportRow{
+			result: structs.ProductImportRowResult{Row: i + 2, Name: cell("name"), Sku: cell("sku")},
+		}
+		errs := make(map[string]string)
+
+		price, err := strconv.ParseUint(cell("price"), 10, 32)
+		if err != nil && cell("price") != "" {
+			errs["price"] = "price must be a whole number"
+		}
+		isAvailable := true
+		if value := cell("is_available"); value != "" {
+			isAvailable, err = parseImportBool(value)
+			if err != nil {
+				errs["is_available"] = "is_available must be true or false"
+			}
+		}
+
+		row.req = structs.ProductCreateRequest{
+			Name:        cell("name"),
+			Price:       uint(price),
+			Category:    cell("category"),
+			Description: cell("description"),
+			IsAvailable: isAvailable,
+		}
+		// The request rejects a false is_available as missing, the column was checked above and defaults to true
+		validated := row.req
+		validated.IsAvailable = true
+		if err := binding.Validator.ValidateStruct(&validated); err != nil {
+			for field, message := range helpers.TranslateErrorMessage(err) {
+				column := productImportFields[field]
+				if _, ok := errs[column]; !ok {
+					errs[column] = message
+				}
+			}
+		}
+
+		if row.req.Category != "" {
+			if row.category, err = pis.productService.categoryService.GetActiveCategoryBySlug(row.req.Category); err != nil {
+				errs["category"] = fmt.Sprintf("category %s does not exist or is inactive", row.req.Category)
+			}
+		}
+
+		if sku := cell("sku"); sku != "" {
+			row.sku = &sku
+		}
+		if barcode := cell("barcode"); barcode != "" {
+			if first, ok := seen["barcode:"+barcode]; ok {
+				errs["barcode"] = fmt.Sprintf("barcode is also used on row %d", first)
+			}
+			seen["barcode:"+barcode] = row.result.Row
+		}
+
+		if image := cell("image"); image != "" {
+			if err := validateBundledImage(bundle, image); err != nil {
+				errs["image"] = err.Error()
+			} else {
+				row.image = bundle[strings.ToLower(path.Base(image))]
+			}
+		}
+
+		key := "name:" + strings.ToLower(row.req.Name)
+		if row.sku != nil {
+			key = "sku:" + *row.sku
+		}
+		if first, ok := seen[key]; ok {
+			errs["row"] = fmt.Sprintf("duplicate of row %d", first)
+		} else {
+			seen[key] = row.result.Row
+		}
+
+		if len(errs) == 0 {
+			if row.existing, err = pis.findExisting(row.sku, row.req.Name); err != nil {
+				errs["row"] = err.Error()
+			}
+		}
+		if len(errs) == 0 {
+			var existingId uint
+			if row.existing != nil {
+				existingId = row.existing.Id
+			}
+			if row.sku, row.barcode, err = pis.productService.ResolveIdentifiers(existingId, cell("sku"), cell("barcode")); err != nil {
+				errs["row"] = err.Error()
+			}
+		}
+
+		switch {
+		case len(errs) > 0:
+			row.result.Action = ProductImportInvalid
+			row.result.Errors = errs
+		case row.existing != nil:
+			row.result.Action = ProductImportUpdate
+			row.result.ProductId = row.existing.Id
+		default:
+			row.result.Action = ProductImportCreate
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// findExisting returns the product a row updates, looked up by SKU and then by name, or nil for a new product
+func (pis *ProductImportService) findExisting(sku *string, name string) (*models.Product, error) {
+	if sku != nil {
+		var product models.Product
+		err := pis.db.Where("sku = ?", *sku).First(&product).Error
+		if err == nil {
+			return &product, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to look up sku: %v", err)
+		}
+	}
+
+	var products []models.Product
+	if err := pis.db.Where("LOWER(name) = LOWER(?)", name).Limit(2).Find(&products).Error; err != nil {
+		return nil, fmt.Errorf("failed to look up name: %v", err)
+	}
+	switch {
+	case len(products) > 1:
+		return nil, fmt.Errorf("name %s matches several products, add a sku to pick one", name)
+	case len(products) == 1:
+		if sku != nil && products[0].Sku != nil && *products[0].Sku != *sku {
+			return nil, invalidError("product %s already has sku %s", name, *products[0].Sku)
+		}
+		return &products[0], nil
+	default:
+		return nil, nil
+	}
+}
+
+// readProductImportTable reads all rows of a CSV file or of the first sheet of an XLSX file
+func readProductImportTable(file *multipart.FileHeader) ([][]string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %v", err)
+	}
+	defer src.Close()
+
+	switch strings.ToLower(filepath.Ext(file.Filename)) {
+	case ".csv":
+		reader := csv.NewReader(src)
+		reader.FieldsPerRecord = -1
+		reader.TrimLeadingSpace = true
+		rows, err := reader.ReadAll()
+		if err != nil {
+			return nil, invalidError("invalid csv file: %v", err)
+		}
+		return rows, nil
+	case ".xlsx":
+		workbook, err := excelize.OpenReader(src)
+		if err != nil {
+			return nil, invalidError("invalid xlsx file: %v", err)
+		}
+		defer workbook.Close()
+
+		rows, err := workbook.GetRows(workbook.GetSheetName(0))
+		if err != nil {
+			return nil, invalidError("invalid xlsx file: %v", err)
+		}
+		return rows, nil
+	default:
+		return nil, invalidError("invalid file, upload a .csv or .xlsx file")
+	}
+}
+
+// openProductImageBundle indexes the images of a zip by lower-cased base name, the bundle stays readable
+// until the returned closer is closed
+func openProductImageBundle(images *multipart.FileHeader) (map[string]*zip.File, io.Closer, error) {
+	src, err := images.Open()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to open image bundle: %v", err)
+	}
+
+	reader, err := zip.NewReader(src, images.Size)
+	if err != nil {
+		src.Close()
+		return nil, nil, invalidError("invalid image bundle, upload a .zip file")
+	}
+
+	bundle := make(map[string]*zip.File)
+	for _, entry := range reader.File {
+		if entry.FileInfo().IsDir() {
+			continue
+		}
+		bundle[strings.ToLower(path.Base(entry.Name))] = entry
+	}
+
+	return bundle, src, nil
+}
+
+// validateBundledImage applies the rules of product image uploads to an image from the bundle
+func validateBundledImage(bundle map[string]*zip.File, name string) error {
+	if bundle == nil {
+		return errors.New("image is set but no image bundle was uploaded")
+	}
+
+	entry, ok := bundle[strings.ToLower(path.Base(name))]
+	if !ok {
+		return fmt.Errorf("image %s is not in the bundle", name)
+	}
+
+	ext := strings.ToLower(filepath.Ext(entry.Name))
+	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
+		return errors.New("image must be a JPG, JPEG, or PNG file")
+	}
+	if entry.UncompressedSize64 > 1<<20 {
+		return errors.New("image size must be less than 1MB")
+	}
+
+	return nil
+}
+
+// saveBundledImage extracts an image into the upload directory and returns its new file name
+func saveBundledImage(entry *zip.File) (string, error) {
+	uploadDir := "uploads"
+	if err := os.MkdirAll(uploadDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %v", err)
+	}
+
+	src, err := entry.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read image %s: %v", entry.Name, err)
+	}
+	defer src.Close()
+
+	newFileName := helpers.UniqueFilenameFor(entry.Name)
+	dst, err := os.Create(filepath.Join(uploadDir, newFileName))
+	if err != nil {
+		return "", fmt.Errorf("failed to create destination file: %v", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, io.LimitReader(src, 1<<20)); err != nil {
+		return "", fmt.Errorf("failed to save image %s: %v", entry.Name, err)
+	}
+
+	return newFileName, nil
+}
+
+func removeUploadedImages(names []string) {
+	for _, name := range names {
+		imagePath := filepath.Join("uploads", name)
+		if err := os.Remove(imagePath); err != nil && !os.IsNotExist(err) {
+			log.Printf("Warning: Failed to delete image %s: %v", imagePath, err)
+		}
+	}
+}
+
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "true", "yes", "y", "1":
+		return true, nil
+	case "false", "no", "n", "0":
+		return false, nil
+	}
+
+	return false, invalidError("invalid boolean %q", value)
+}
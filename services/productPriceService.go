@@ -0,0 +1,273 @@
+package services
+
+import (
+	"deck/helpers"
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ProductPriceService struct {
+	db *gorm.DB
+}
+
+func NewProductPriceService(db *gorm.DB) *ProductPriceService {
+	return &ProductPriceService{db: db}
+}
+
+// Get the price history of a product including scheduled prices, oldest first
+func (ps *ProductPriceService) GetPriceHistory(productId uint) ([]models.ProductPrice, error) {
+	if err := ps.db.First(&models.Product{}, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	var prices []models.ProductPrice
+	err := ps.db.Where("product_id = ?", productId).Order("effective_from ASC, id ASC").Find(&prices).Error
+
+	return prices, err
+}
+
+// Schedule a future price for a product
+func (ps *ProductPriceService) SchedulePrice(productId uint, req *structs.ProductPriceRequest, createdBy string) (*models.ProductPrice, error) {
+	if err := ps.db.First(&models.Product{}, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	effectiveFrom, err := helpers.ParseLocalTime(req.EffectiveFrom)
+	if err != nil {
+		return nil, invalidError("%v", err)
+	}
+	if !effectiveFrom.After(time.Now()) {
+		return nil, invalidError("invalid effective_from, scheduled prices must start in the future")
+	}
+
+	var count int64
+	ps.db.Model(&models.ProductPrice{}).Where("product_id = ? AND effective_from = ?", productId, effectiveFrom).Count(&count)
+	if count > 0 {
+		return nil, invalidError("a price is already scheduled at this time")
+	}
+
+	price := models.ProductPrice{
+		ProductId:     productId,
+		Price:         req.Price,
+		EffectiveFrom: effectiveFrom,
+		Notes:         strings.TrimSpace(req.Notes),
+		CreatedBy:     createdBy,
+	}
+
+	if err := ps.db.Create(&price).Error; err != nil {
+		return nil, fmt.Errorf("failed to schedule price: %v", err)
+	}
+
+	return &price, nil
+}
+
+// Delete a scheduled price that has not taken effect yet
+func (ps *ProductPriceService) DeleteScheduledPrice(productId uint, priceId uint) error {
+	var price models.ProductPrice
+	if err := ps.db.Where("id = ? AND product_id = ?", priceId, productId).First(&price).Error; err != nil {
+		return notFoundError("price not found")
+	}
+
+	if !price.EffectiveFrom.After(time.Now()) {
+		return invalidError("invalid price, only prices that are not effective yet can be deleted")
+	}
+
+	if err := ps.db.Delete(&price).Error; err != nil {
+		return fmt.Errorf("failed to delete price: %v", err)
+	}
+
+	return nil
+}
+
+// RecordPriceChange adds a history entry effective now, used when a price is edited directly on the product
+func (ps *ProductPriceService) RecordPriceChange(tx *gorm.DB, productId uint, price uint, createdBy string) error {
+	entry := models.ProductPrice{
+		ProductId:     productId,
+		Price:         price,
+		EffectiveFrom: time.Now(),
+		CreatedBy:     createdBy,
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to record price change: %v", err)
+	}
+
+	return nil
+}
+
+// recordInitialPrice starts the price history of a newly created product
+func recordInitialPrice(tx *gorm.DB, product *models.Product) error {
+	entry := models.ProductPrice{
+		ProductId:     product.Id,
+		Price:         product.Price,
+		EffectiveFrom: product.CreatedAt,
+		Notes:         "initial price",
+	}
+
+	if err := tx.Create(&entry).Error; err != nil {
+		return fmt.Errorf("failed to create product price: %v", err)
+	}
+
+	return nil
+}
+
+// ApplyDuePrices copies prices whose time has come onto products.price, so listings show the current price
+func (ps *ProductPriceService) ApplyDuePrices() (int64, error) {
+	result := ps.db.Exec(`UPDATE products p SET price = due.price, updated_at = now()
+		FROM (
+			SELECT DISTINCT ON (product_id) product_id, price
+			FROM product_prices
+			WHERE effective_from <= now()
+			ORDER BY product_id, effective_from DESC, id DESC
+		) due
+		WHERE due.product_id = p.id AND p.price <> due.price`)
+
+	return result.RowsAffected, result.Error
+}
+
+// StartPriceWorker runs ApplyDuePrices right away and then on every tick, it blocks so start it in its own goroutine
+func (ps *ProductPriceService) StartPriceWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := ps.ApplyDuePrices(); err != nil {
+			fmt.Printf("Failed to apply scheduled prices: %v\n", err)
+		}
+		<-ticker.C
+	}
+}
+
+// Get revenue of a product per price period. Order lines are attributed through the price they were sold at,
+// lines from before price history existed fall into the period their transaction was created in.
+func (ps *ProductPriceService) GetPricePeriodReport(productId uint, fromDate string, toDate string) (*structs.PricePeriodReport, error) {
+	from, to, err := helpers.ParseLocalDateRange(fromDate, toDate)
+	if err != nil {
+		return nil, invalidError("%v", err)
+	}
+
+	var product models.Product
+	if err := ps.db.First(&product, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	prices, err := ps.GetPriceHistory(productId)
+	if err != nil {
+		return nil, err
+	}
+
+	paidLines := func() *gorm.DB {
+		query := ps.db.Table("transaction_details td").
+			Joins("JOIN transactions t ON t.id = td.transaction_id").
+			Select("COUNT(DISTINCT td.transaction_id) AS orders, COALESCE(SUM(td.quantity), 0) AS quantity, COALESCE(SUM(td.total_price), 0) AS revenue").
+			Where("td.product_id = ? AND td.parent_detail_id IS NULL AND td.voided_at IS NULL AND t.payment_status = ?", productId, models.PaymentStatusPaid)
+		if from != nil {
+			query = query.Where("t.created_at >= ?", *from)
+		}
+		if to != nil {
+			query = query.Where("t.created_at < ?", *to)
+		}
+		return query
+	}
+
+	type periodTotals struct {
+		PriceId  uint
+		Orders   int64
+		Quantity int64
+		Revenue  int64
+	}
+
+	var attributed []periodTotals
+	if err := paidLines().Select("td.price_id, COUNT(DISTINCT td.transaction_id) AS orders, COALESCE(SUM(td.quantity), 0) AS quantity, COALESCE(SUM(td.total_price), 0) AS revenue").
+		Where("td.price_id IS NOT NULL").Group("td.price_id").Scan(&attributed).Error; err != nil {
+		return nil, fmt.Errorf("failed to load revenue: %v", err)
+	}
+	totalsByPrice := make(map[uint]periodTotals)
+	for _, totals := range attributed {
+		totalsByPrice[totals.PriceId] = totals
+	}
+
+	report := &structs.PricePeriodReport{
+		ProductId:   product.Id,
+		ProductName: product.Name,
+		From:        optionalString(fromDate),
+		To:          optionalString(toDate),
+		Periods:     make([]structs.PricePeriodRevenue, 0, len(prices)),
+	}
+
+	now := time.Now()
+	for i, price := range prices {
+		if price.EffectiveFrom.After(now) {
+			break
+		}
+
+		var end *time.Time
+		if i+1 < len(prices) {
+			end = &prices[i+1].EffectiveFrom
+		}
+
+		legacy := periodTotals{}
+		query := paidLines().Where("td.price_id IS NULL AND t.created_at >= ?", price.EffectiveFrom)
+		if end != nil {
+			query = query.Where("t.created_at < ?", *end)
+		}
+		if err := query.Scan(&legacy).Error; err != nil {
+			return nil, fmt.Errorf("failed to load revenue: %v", err)
+		}
+
+		totals := totalsByPrice[price.Id]
+		period := structs.PricePeriodRevenue{
+			PriceId:       price.Id,
+			Price:         price.Price,
+			EffectiveFrom: price.EffectiveFrom.Format("2006-01-02 15:04:05"),
+			EffectiveTo:   formatOptionalTime(end),
+			Orders:        totals.Orders + legacy.Orders,
+			Quantity:      totals.Quantity + legacy.Quantity,
+			Revenue:       totals.Revenue + legacy.Revenue,
+		}
+
+		report.Periods = append(report.Periods, period)
+		report.TotalRevenue += period.Revenue
+	}
+
+	return report, nil
+}
+
+// effectiveProductPrice returns the price entry that applies to a product at the given moment, nil when it has no history
+func effectiveProductPrice(tx *gorm.DB, productId uint, at time.Time) (*models.ProductPrice, error) {
+	var price models.ProductPrice
+	err := tx.Where("product_id = ? AND effective_from <= ?", productId, at).
+		Order("effective_from DESC, id DESC").
+		First(&price).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to resolve price: %v", err)
+	}
+
+	return &price, nil
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+
+	return &value
+}
+
+func formatOptionalTime(t *time.Time) *string {
+	if t == nil {
+		return nil
+	}
+
+	formatted := t.Format("2006-01-02 15:04:05")
+	return &formatted
+}
@@ -1,33 +1,35 @@
 package services
 
 import (
-	"deck/enums"
 	"deck/helpers"
 	"deck/models"
 	"deck/structs"
 	"errors"
 	"fmt"
 	"gorm.io/gorm"
+	"html"
 	"io"
 	"log"
 	"mime/multipart"
 	"os"
 	"path/filepath"
 	"strings"
+	"unicode"
 )
 
 type ProductService struct {
-	db *gorm.DB
+	db              *gorm.DB
+	categoryService *CategoryService
 }
 
-func NewProductService(db *gorm.DB) *ProductService {
-	return &ProductService{db: db}
+func NewProductService(db *gorm.DB, categoryService *CategoryService) *ProductService {
+	return &ProductService{db: db, categoryService: categoryService}
 }
 
 // Get All Products
 func (ps *ProductService) GetProducts() ([]models.Product, error) {
 	var products []models.Product
-	err := ps.db.Order("created_at DESC").Find(&products).Error
+	err := ps.preloadMenu(ps.db).Order("created_at DESC").Find(&products).Error
 
 	return products, err
 }
@@ -35,12 +37,188 @@ func (ps *ProductService) GetProducts() ([]models.Product, error) {
 // Get Product By Id
 func (ps *ProductService) GetProductById(id uint) (*models.Product, error) {
 	var product models.Product
-	if err := ps.db.First(&product, id).Error; err != nil {
+	if err := ps.preloadMenu(ps.db).First(&product, id).Error; err != nil {
 		return nil, err
 	}
 	return &product, nil
 }
 
+// Lookup a product by barcode or SKU, used by scanners at the till
+func (ps *ProductService) LookupProduct(barcode string, sku string) (*models.Product, error) {
+	query := ps.preloadMenu(ps.db)
+	switch {
+	case barcode != "":
+		query = query.Where("barcode = ?", barcode)
+	case sku != "":
+		query = query.Where("sku = ?", sku)
+	default:
+		return nil, invalidError("invalid lookup, barcode or sku is required")
+	}
+
+	var product models.Product
+	if err := query.First(&product).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("product not found")
+		}
+		return nil, err
+	}
+
+	return &product, nil
+}
+
+// ResolveIdentifiers validates the SKU and barcode of a product, empty values clear them. productId is the
+// product being updated, 0 when creating one.
+func (ps *ProductService) ResolveIdentifiers(productId uint, sku string, barcode string) (*string, *string, error) {
+	var skuValue, barcodeValue *string
+
+	if sku = strings.TrimSpace(sku); sku != "" {
+		if len(sku) > 64 {
+			return nil, nil, invalidError("invalid sku, use at most 64 characters")
+		}
+
+		var count int64
+		if err := ps.db.Model(&models.Product{}).Where("sku = ? AND id <> ?", sku, productId).Count(&count).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to check sku: %v", err)
+		}
+		if count > 0 {
+			return nil, nil, invalidError("sku %s already exists", sku)
+		}
+		skuValue = &sku
+	}
+
+	if barcode = strings.TrimSpace(barcode); barcode != "" {
+		if !helpers.ValidBarcode(barcode) {
+			return nil, nil, invalidError("invalid barcode, expected an EAN-13, EAN-8 or UPC-A code with a valid check digit")
+		}
+
+		var count int64
+		if err := ps.db.Model(&models.Product{}).Where("barcode = ? AND id <> ?", barcode, productId).Count(&count).Error; err != nil {
+			return nil, nil, fmt.Errorf("failed to check barcode: %v", err)
+		}
+		if count > 0 {
+			return nil, nil, invalidError("barcode %s already exists", barcode)
+		}
+		barcodeValue = &barcode
+	}
+
+	return skuValue, barcodeValue, nil
+}
+
+// preloadMenu loads the relations shown on the menu: category, option groups, variants, modifiers and bundle components
+func (ps *ProductService) preloadMenu(db *gorm.DB) *gorm.DB {
+	return db.Preload("Category").
+		Preload("OptionGroups", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Preload("OptionGroups.Values", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Variants.OptionValues").
+		Preload("ModifierGroups", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Preload("ModifierGroups.Modifiers", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Scopes(preloadBundleComponents)
+}
+
+// ProductSearchResult is a product row together with its search ranking and highlighted fragments
+type ProductSearchResult struct {
+	models.Product
+	Rank          float64
+	NameHighlight string
+	Snippet       string
+	MatchType     string `gorm:"-"`
+}
+
+const (
+	SearchMatchFullText = "fulltext"
+	SearchMatchFuzzy    = "fuzzy"
+)
+
+// Headlines mark matches with control characters, the text is HTML escaped before they become <mark> tags
+const (
+	searchHeadlineOptions     = "StartSel=\"\x02\", StopSel=\"\x03\", MaxWords=25, MinWords=8, HighlightAll=false"
+	searchNameHeadlineOptions = "StartSel=\"\x02\", StopSel=\"\x03\", HighlightAll=true"
+)
+
+var searchHighlightTags = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+// Search products using full-text search, falling back to trigram similarity so typos still match
+func (ps *ProductService) SearchProducts(keyword string, limit int) ([]ProductSearchResult, error) {
+	tsQuery := buildPrefixTsQuery(keyword)
+	if tsQuery == "" {
+		return nil, invalidError("invalid search query")
+	}
+
+	var results []ProductSearchResult
+	err := ps.db.Raw(`
+		SELECT p.*,
+			ts_rank_cd(p.search_vector, q.query) AS rank,
+			ts_headline('simple', p.name, q.query, ?) AS name_highlight,
+			ts_headline('simple', coalesce(p.description, ''), q.query, ?) AS snippet
+		FROM products p, to_tsquery('simple', ?) AS q(query)
+		WHERE p.search_vector @@ q.query
+		ORDER BY rank DESC, p.name ASC
+		LIMIT ?`, searchNameHeadlineOptions, searchHeadlineOptions, tsQuery, limit).Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %v", err)
+	}
+
+	if len(results) > 0 {
+		for i := range results {
+			results[i].MatchType = SearchMatchFullText
+			markSearchHighlights(&results[i])
+		}
+		ps.attachCategories(results)
+		return results, nil
+	}
+
+	keyword = strings.TrimSpace(keyword)
+	err = ps.db.Raw(`
+		SELECT p.*,
+			word_similarity(?, p.name) AS rank,
+			p.name AS name_highlight,
+			ts_headline('simple', coalesce(p.description, ''), plainto_tsquery('simple', ?), ?) AS snippet
+		FROM products p
+		WHERE ? <% p.name OR ? <% coalesce(p.description, '')
+		ORDER BY rank DESC, p.name ASC
+		LIMIT ?`, keyword, keyword, searchHeadlineOptions, keyword, keyword, limit).Scan(&results).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %v", err)
+	}
+
+	for i := range results {
+		results[i].MatchType = SearchMatchFuzzy
+		markSearchHighlights(&results[i])
+	}
+	ps.attachCategories(results)
+
+	return results, nil
+}
+
+// markSearchHighlights escapes the product text of the headlines so it can be rendered as HTML with its <mark> tags
+func markSearchHighlights(result *ProductSearchResult) {
+	result.NameHighlight = searchHighlightTags.Replace(html.EscapeString(result.NameHighlight))
+	result.Snippet = searchHighlightTags.Replace(html.EscapeString(result.Snippet))
+}
+
+// attachCategories fills the category relation of raw search rows from the category cache
+func (ps *ProductService) attachCategories(results []ProductSearchResult) {
+	for i := range results {
+		if category, err := ps.categoryService.GetCategoryById(results[i].CategoryId); err == nil {
+			results[i].Category = *category
+		}
+	}
+}
+
+// buildPrefixTsQuery turns free text like "kopi sus" into "kopi:* & sus:*" so partial words still match
+func buildPrefixTsQuery(keyword string) string {
+	terms := strings.FieldsFunc(strings.ToLower(keyword), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
+
 func (ps *ProductService) CreateProduct(req *structs.ProductCreateRequest, file *multipart.FileHeader) (*models.Product, error) {
 	tx := ps.db.Begin()
 	defer func() {
@@ -51,18 +229,30 @@ func (ps *ProductService) CreateProduct(req *structs.ProductCreateRequest, file
 
 	if file == nil {
 		tx.Rollback()
-		return nil, errors.New("image file is required")
+		return nil, invalidError("image file is required")
 	}
 
 	if file.Size > 1<<20 {
 		tx.Rollback()
-		return nil, errors.New("image size must be less than 1MB")
+		return nil, invalidError("image size must be less than 1MB")
 	}
 
 	ext := strings.ToLower(filepath.Ext(file.Filename))
 	if ext != ".jpg" && ext != ".jpeg" && ext != ".png" {
 		tx.Rollback()
-		return nil, errors.New("image must be a JPG, JPEG, or PNG file")
+		return nil, invalidError("image must be a JPG, JPEG, or PNG file")
+	}
+
+	category, err := ps.categoryService.GetActiveCategoryBySlug(req.Category)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	sku, barcode, err := ps.ResolveIdentifiers(0, req.Sku, req.Barcode)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
 	}
 
 	uploadDir := "uploads"
@@ -102,27 +292,13 @@ func (ps *ProductService) CreateProduct(req *structs.ProductCreateRequest, file
 		return nil, fmt.Errorf("failed to save uploaded image: %v", err)
 	}
 
-	validCategories := []enums.CategoryType{
-		enums.Other, enums.Appetizers, enums.MainCourse, enums.Desserts, enums.Snacks, enums.Food, enums.Pastry,
-	}
-
-	isValidCategory := false
-	for _, validCat := range validCategories {
-		if req.Category == validCat {
-			isValidCategory = true
-			break
-		}
-	}
-
-	if !isValidCategory {
-		tx.Rollback()
-		return nil, errors.New("invalid category type")
-	}
-
 	product := models.Product{
 		Name:        req.Name,
+		Sku:         sku,
+		Barcode:     barcode,
+		Type:        models.ProductTypeSingle,
 		Price:       req.Price,
-		Category:    req.Category,
+		CategoryId:  category.Id,
 		Description: req.Description,
 		IsAvailable: req.IsAvailable,
 		Image:       newFileName,
@@ -133,10 +309,17 @@ func (ps *ProductService) CreateProduct(req *structs.ProductCreateRequest, file
 		return nil, fmt.Errorf("failed to create product: %v", err)
 	}
 
+	if err := recordInitialPrice(tx, &product); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, fmt.Errorf("failed to commit transaction: %v", err)
 	}
 
+	product.Category = *category
+
 	return &product, nil
 }
 
@@ -153,7 +336,7 @@ func (ps *ProductService) DeleteProduct(id uint) error {
 	if err := tx.First(&product, id).Error; err != nil {
 		tx.Rollback()
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return errors.New("product not found")
+			return notFoundError("product not found")
 		}
 		return fmt.Errorf("failed to find product: %v", err)
 	}
@@ -0,0 +1,230 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"sort"
+	"strings"
+)
+
+type ProductVariantService struct {
+	db *gorm.DB
+}
+
+func NewProductVariantService(db *gorm.DB) *ProductVariantService {
+	return &ProductVariantService{db: db}
+}
+
+// Get option groups and variants of a product
+func (vs *ProductVariantService) GetVariants(productId uint) (*models.Product, error) {
+	var product models.Product
+	err := vs.db.
+		Preload("OptionGroups", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Preload("OptionGroups.Values", func(db *gorm.DB) *gorm.DB { return db.Order("sort_order ASC, id ASC") }).
+		Preload("Variants", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Variants.OptionValues").
+		First(&product, productId).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("product not found")
+		}
+		return nil, fmt.Errorf("failed to find product: %v", err)
+	}
+
+	return &product, nil
+}
+
+// Create option group with its values
+func (vs *ProductVariantService) CreateOptionGroup(productId uint, req *structs.ProductOptionGroupCreateRequest) (*models.ProductOptionGroup, error) {
+	if err := vs.db.First(&models.Product{}, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	group := models.ProductOptionGroup{
+		ProductId: productId,
+		Name:      strings.TrimSpace(req.Name),
+		SortOrder: req.SortOrder,
+	}
+
+	seen := make(map[string]bool)
+	for i, name := range req.Values {
+		name = strings.TrimSpace(name)
+		if seen[strings.ToLower(name)] {
+			return nil, invalidError("invalid option values, duplicate value: %s", name)
+		}
+		seen[strings.ToLower(name)] = true
+
+		group.Values = append(group.Values, models.ProductOptionValue{Name: name, SortOrder: i})
+	}
+
+	if err := vs.db.Create(&group).Error; err != nil {
+		return nil, fmt.Errorf("failed to create option group: %v", err)
+	}
+
+	return &group, nil
+}
+
+// Delete option group when none of its values is used by a variant
+func (vs *ProductVariantService) DeleteOptionGroup(productId uint, groupId uint) error {
+	var group models.ProductOptionGroup
+	if err := vs.db.Where("id = ? AND product_id = ?", groupId, productId).First(&group).Error; err != nil {
+		return notFoundError("option group not found")
+	}
+
+	var usage int64
+	vs.db.Table("product_variant_option_values").
+		Joins("JOIN product_option_values ON product_option_values.id = product_variant_option_values.product_option_value_id").
+		Where("product_option_values.option_group_id = ?", groupId).
+		Count(&usage)
+	if usage > 0 {
+		return invalidError("option group is in use by variants, delete those variants first")
+	}
+
+	if err := vs.db.Delete(&group).Error; err != nil {
+		return fmt.Errorf("failed to delete option group: %v", err)
+	}
+
+	return nil
+}
+
+// Create variant from one option value per group
+func (vs *ProductVariantService) CreateVariant(productId uint, req *structs.ProductVariantRequest) (*models.ProductVariant, error) {
+	if err := vs.db.First(&models.Product{}, productId).Error; err != nil {
+		return nil, notFoundError("product not found")
+	}
+
+	values, name, err := vs.resolveOptionValues(productId, req.OptionValueIds, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	variant := models.ProductVariant{
+		ProductId:    productId,
+		Name:         name,
+		Price:        req.Price,
+		PriceDelta:   req.PriceDelta,
+		IsAvailable:  req.IsAvailable == nil || *req.IsAvailable,
+		OptionValues: values,
+	}
+
+	if err := vs.db.Create(&variant).Error; err != nil {
+		return nil, fmt.Errorf("failed to create variant: %v", err)
+	}
+
+	return &variant, nil
+}
+
+// Update variant options, pricing and availability
+func (vs *ProductVariantService) UpdateVariant(productId uint, variantId uint, req *structs.ProductVariantRequest) (*models.ProductVariant, error) {
+	var variant models.ProductVariant
+	if err := vs.db.Where("id = ? AND product_id = ?", variantId, productId).First(&variant).Error; err != nil {
+		return nil, notFoundError("variant not found")
+	}
+
+	values, name, err := vs.resolveOptionValues(productId, req.OptionValueIds, variantId)
+	if err != nil {
+		return nil, err
+	}
+
+	variant.Name = name
+	variant.Price = req.Price
+	variant.PriceDelta = req.PriceDelta
+	if req.IsAvailable != nil {
+		variant.IsAvailable = *req.IsAvailable
+	}
+
+	err = vs.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("OptionValues", "StockQuantity", "ReservedStock").Save(&variant).Error; err != nil {
+			return err
+		}
+		return tx.Model(&variant).Association("OptionValues").Replace(values)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to update variant: %v", err)
+	}
+
+	variant.OptionValues = values
+
+	return &variant, nil
+}
+
+// Delete variant
+func (vs *ProductVariantService) DeleteVariant(productId uint, variantId uint) error {
+	var variant models.ProductVariant
+	if err := vs.db.Where("id = ? AND product_id = ?", variantId, productId).First(&variant).Error; err != nil {
+		return notFoundError("variant not found")
+	}
+
+	if err := vs.db.Select("OptionValues").Delete(&variant).Error; err != nil {
+		return fmt.Errorf("failed to delete variant: %v", err)
+	}
+
+	return nil
+}
+
+// resolveOptionValues checks that the values pick exactly one value from every option group of the product
+// and that no other variant already uses the same combination. It returns the values and the variant name.
+func (vs *ProductVariantService) resolveOptionValues(productId uint, valueIds []uint, excludeVariantId uint) ([]models.ProductOptionValue, string, error) {
+	var groups []models.ProductOptionGroup
+	if err := vs.db.Preload("Values").Where("product_id = ?", productId).Order("sort_order ASC, id ASC").Find(&groups).Error; err != nil {
+		return nil, "", fmt.Errorf("failed to load option groups: %v", err)
+	}
+
+	if len(groups) == 0 {
+		return nil, "", invalidError("invalid variant, product has no option groups")
+	}
+
+	selected := make(map[uint]bool)
+	for _, id := range valueIds {
+		selected[id] = true
+	}
+
+	var values []models.ProductOptionValue
+	var names []string
+	for _, group := range groups {
+		var picked []models.ProductOptionValue
+		for _, value := range group.Values {
+			if selected[value.Id] {
+				picked = append(picked, value)
+				delete(selected, value.Id)
+			}
+		}
+
+		if len(picked) != 1 {
+			return nil, "", invalidError("invalid variant, pick exactly one value for option group %s", group.Name)
+		}
+
+		values = append(values, picked[0])
+		names = append(names, picked[0].Name)
+	}
+
+	if len(selected) > 0 {
+		return nil, "", invalidError("invalid variant, option values do not belong to this product")
+	}
+
+	combination := variantCombinationKey(values)
+
+	var existing []models.ProductVariant
+	vs.db.Preload("OptionValues").Where("product_id = ? AND id <> ?", productId, excludeVariantId).Find(&existing)
+	for _, variant := range existing {
+		if variantCombinationKey(variant.OptionValues) == combination {
+			return nil, "", invalidError("variant %s already exists", variant.Name)
+		}
+	}
+
+	return values, strings.Join(names, " / "), nil
+}
+
+// variantCombinationKey builds an order independent key for a set of option values
+func variantCombinationKey(values []models.ProductOptionValue) string {
+	ids := make([]int, len(values))
+	for i, value := range values {
+		ids[i] = int(value.Id)
+	}
+	sort.Ints(ids)
+
+	return fmt.Sprint(ids)
+}
@@ -0,0 +1,292 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PurchaseOrderService struct {
+	db *gorm.DB
+}
+
+func NewPurchaseOrderService(db *gorm.DB) *PurchaseOrderService {
+	return &PurchaseOrderService{db: db}
+}
+
+// Get All Suppliers
+func (ps *PurchaseOrderService) GetSuppliers() ([]models.Supplier, error) {
+	var suppliers []models.Supplier
+	err := ps.db.Order("name ASC").Find(&suppliers).Error
+
+	return suppliers, err
+}
+
+// Get Supplier By Id
+func (ps *PurchaseOrderService) GetSupplierById(id uint) (*models.Supplier, error) {
+	var supplier models.Supplier
+	if err := ps.db.First(&supplier, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("supplier not found")
+		}
+		return nil, fmt.Errorf("failed to find supplier: %v", err)
+	}
+
+	return &supplier, nil
+}
+
+// Create Supplier
+func (ps *PurchaseOrderService) CreateSupplier(req *structs.SupplierRequest) (*models.Supplier, error) {
+	supplier := models.Supplier{
+		Name:    strings.TrimSpace(req.Name),
+		Phone:   req.Phone,
+		Email:   req.Email,
+		Address: req.Address,
+		Notes:   req.Notes,
+	}
+
+	if err := ps.db.Create(&supplier).Error; err != nil {
+		return nil, fmt.Errorf("failed to create supplier: %v", err)
+	}
+
+	return &supplier, nil
+}
+
+// Update Supplier
+func (ps *PurchaseOrderService) UpdateSupplier(id uint, req *structs.SupplierRequest) (*models.Supplier, error) {
+	supplier, err := ps.GetSupplierById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	supplier.Name = strings.TrimSpace(req.Name)
+	supplier.Phone = req.Phone
+	supplier.Email = req.Email
+	supplier.Address = req.Address
+	supplier.Notes = req.Notes
+
+	if err := ps.db.Save(supplier).Error; err != nil {
+		return nil, fmt.Errorf("failed to update supplier: %v", err)
+	}
+
+	return supplier, nil
+}
+
+// Delete Supplier when it has no purchase orders
+func (ps *PurchaseOrderService) DeleteSupplier(id uint) error {
+	supplier, err := ps.GetSupplierById(id)
+	if err != nil {
+		return err
+	}
+
+	var usage int64
+	ps.db.Model(&models.PurchaseOrder{}).Where("supplier_id = ?", id).Count(&usage)
+	if usage > 0 {
+		return invalidError("supplier %s is in use by %d purchase order(s)", supplier.Name, usage)
+	}
+
+	if err := ps.db.Delete(supplier).Error; err != nil {
+		return fmt.Errorf("failed to delete supplier: %v", err)
+	}
+
+	return nil
+}
+
+// Get All Purchase Orders, optionally filtered by status
+func (ps *PurchaseOrderService) GetPurchaseOrders(status string) ([]models.PurchaseOrder, error) {
+	query := preloadPurchaseOrder(ps.db).Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var orders []models.PurchaseOrder
+	err := query.Find(&orders).Error
+
+	return orders, err
+}
+
+// Get Purchase Order By Id
+func (ps *PurchaseOrderService) GetPurchaseOrderById(id uint) (*models.PurchaseOrder, error) {
+	var order models.PurchaseOrder
+	if err := preloadPurchaseOrder(ps.db).First(&order, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("purchase order not found")
+		}
+		return nil, fmt.Errorf("failed to find purchase order: %v", err)
+	}
+
+	return &order, nil
+}
+
+// Create Purchase Order, it is placed with the supplier right away
+func (ps *PurchaseOrderService) CreatePurchaseOrder(req *structs.PurchaseOrderCreateRequest, createdBy string) (*models.PurchaseOrder, error) {
+	if _, err := ps.GetSupplierById(req.SupplierId); err != nil {
+		return nil, invalidError("invalid supplier, %v", err)
+	}
+
+	order := models.PurchaseOrder{
+		Number:     ps.generatePurchaseOrderNumber(),
+		SupplierId: req.SupplierId,
+		Status:     models.PurchaseOrderStatusOrdered,
+		Notes:      req.Notes,
+		CreatedBy:  createdBy,
+	}
+
+	seen := make(map[uint]bool)
+	for _, item := range req.Items {
+		if seen[item.IngredientId] {
+			return nil, invalidError("invalid items, ingredient %d is listed more than once", item.IngredientId)
+		}
+		seen[item.IngredientId] = true
+
+		if err := ps.db.First(&models.Ingredient{}, item.IngredientId).Error; err != nil {
+			return nil, invalidError("invalid items, ingredient %d not found", item.IngredientId)
+		}
+
+		order.TotalCost += item.Quantity * item.UnitCost
+		order.Items = append(order.Items, models.PurchaseOrderItem{
+			IngredientId: item.IngredientId,
+			Quantity:     roundQuantity(item.Quantity),
+			UnitCost:     item.UnitCost,
+		})
+	}
+
+	if err := ps.db.Create(&order).Error; err != nil {
+		return nil, fmt.Errorf("failed to create purchase order: %v", err)
+	}
+
+	return ps.GetPurchaseOrderById(order.Id)
+}
+
+// Receive Purchase Order. Received quantities go into ingredient stock and update its average cost.
+func (ps *PurchaseOrderService) ReceivePurchaseOrder(id uint, req *structs.PurchaseOrderReceiveRequest, createdBy string) (*models.PurchaseOrder, error) {
+	err := ps.db.Transaction(func(tx *gorm.DB) error {
+		var order models.PurchaseOrder
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&order, id).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return notFoundError("purchase order not found")
+			}
+			return err
+		}
+		if order.Status != models.PurchaseOrderStatusOrdered {
+			return invalidError("invalid purchase order, it is already %s", order.Status)
+		}
+
+		var items []models.PurchaseOrderItem
+		if err := tx.Where("purchase_order_id = ?", order.Id).Order("ingredient_id ASC").Find(&items).Error; err != nil {
+			return err
+		}
+
+		received := make(map[uint]float64)
+		for _, item := range items {
+			received[item.Id] = item.Quantity
+		}
+		if len(req.Items) > 0 {
+			for itemId := range received {
+				received[itemId] = 0
+			}
+			for _, item := range req.Items {
+				if _, ok := received[item.ItemId]; !ok {
+					return invalidError("invalid items, item %d does not belong to this purchase order", item.ItemId)
+				}
+				received[item.ItemId] = roundQuantity(item.ReceivedQuantity)
+			}
+		}
+
+		sort.Slice(items, func(i, j int) bool { return items[i].IngredientId < items[j].IngredientId })
+
+		var totalCost float64
+		purchaseOrderId := order.Id
+		for _, item := range items {
+			quantity := received[item.Id]
+			totalCost += quantity * item.UnitCost
+
+			if err := tx.Model(&item).Update("received_quantity", quantity).Error; err != nil {
+				return err
+			}
+			if quantity == 0 {
+				continue
+			}
+
+			var ingredient models.Ingredient
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Unit").First(&ingredient, item.IngredientId).Error; err != nil {
+				return fmt.Errorf("failed to lock ingredient %d: %v", item.IngredientId, err)
+			}
+
+			// Weighted average over the stock on hand, negative theoretical stock does not count
+			onHand := max(ingredient.StockQuantity, 0)
+			cost := item.UnitCost
+			if onHand+quantity > 0 {
+				cost = (onHand*ingredient.CostPerUnit + quantity*item.UnitCost) / (onHand + quantity)
+			}
+			if err := tx.Model(&models.Ingredient{}).Where("id = ?", ingredient.Id).Update("cost_per_unit", cost).Error; err != nil {
+				return err
+			}
+
+			if _, _, err := applyIngredientChange(tx, &ingredient, quantity, models.IngredientMovement{
+				Type:            models.IngredientMovementPurchase,
+				PurchaseOrderId: &purchaseOrderId,
+				Reference:       order.Number,
+				CreatedBy:       createdBy,
+			}); err != nil {
+				return err
+			}
+		}
+
+		now := time.Now()
+		return tx.Model(&order).Updates(map[string]interface{}{
+			"status":      models.PurchaseOrderStatusReceived,
+			"received_at": &now,
+			"total_cost":  totalCost,
+		}).Error
+	})
+	if err != nil {
+		var serviceErr *serviceError
+		if errors.As(err, &serviceErr) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("failed to receive purchase order: %v", err)
+	}
+
+	return ps.GetPurchaseOrderById(id)
+}
+
+// Cancel Purchase Order that has not been received
+func (ps *PurchaseOrderService) CancelPurchaseOrder(id uint) (*models.PurchaseOrder, error) {
+	result := ps.db.Model(&models.PurchaseOrder{}).
+		Where("id = ? AND status = ?", id, models.PurchaseOrderStatusOrdered).
+		Update("status", models.PurchaseOrderStatusCancelled)
+	if result.Error != nil {
+		return nil, fmt.Errorf("failed to cancel purchase order: %v", result.Error)
+	}
+
+	order, err := ps.GetPurchaseOrderById(id)
+	if err != nil {
+		return nil, err
+	}
+	if result.RowsAffected == 0 {
+		return nil, invalidError("invalid purchase order, it is already %s", order.Status)
+	}
+
+	return order, nil
+}
+
+// generatePurchaseOrderNumber
+func (ps *PurchaseOrderService) generatePurchaseOrderNumber() string {
+	timestamp := time.Now().Format("20060102150405")
+	return fmt.Sprintf("PO-%s", timestamp)
+}
+
+func preloadPurchaseOrder(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Supplier").
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Items.Ingredient.Unit")
+}
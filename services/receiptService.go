@@ -0,0 +1,458 @@
+package services
+
+import (
+	"bytes"
+	"deck/config"
+	"deck/helpers"
+	"deck/models"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+	"gorm.io/gorm"
+)
+
+type ReceiptService struct {
+	db *gorm.DB
+}
+
+func NewReceiptService(db *gorm.DB) *ReceiptService {
+	return &ReceiptService{db: db}
+}
+
+// Receipt is everything printed on a receipt, in the order it is printed
+type Receipt struct {
+	Title          string
+	OutletName     string
+	OutletAddress  string
+	OutletPhone    string
+	OrderNumber    string
+	Date           time.Time
+	Cashier        string
+	OrderType      string
+	TableName      string
+	BuyerName      string
+	Items          []ReceiptItem
+	SubTotal       uint
+	DiscountAmount uint
+	PointsRedeemed uint
+	PointsDiscount uint
+	Total          uint
+	TaxRate        uint
+	TaxAmount      uint
+	Payments       []ReceiptPayment
+	Footer         string
+}
+
+type ReceiptItem struct {
+	Name      string
+	Quantity  uint
+	UnitPrice uint
+	Total     uint
+	Modifiers []string
+	Notes     string
+	Discounts []ReceiptDiscount
+}
+
+type ReceiptDiscount struct {
+	Name   string
+	Amount uint
+}
+
+type ReceiptPayment struct {
+	Method   string
+	Amount   uint
+	Tendered uint
+	Change   uint
+}
+
+// Receipt paper widths in characters of the default font
+const (
+	ReceiptWidth58mm = 32
+	ReceiptWidth80mm = 48
+)
+
+var receiptTitles = map[string]string{
+	models.PaymentStatusPaid:     "STRUK PEMBAYARAN",
+	models.PaymentStatusPending:  "TAGIHAN",
+	models.PaymentStatusRefunded: "STRUK REFUND",
+}
+
+var receiptOrderTypes = map[string]string{
+	models.OrderTypeDineIn:   "Makan di tempat",
+	models.OrderTypeTakeaway: "Bawa pulang",
+	models.OrderTypeDelivery: "Pesan antar",
+}
+
+var receiptPaymentMethods = map[string]string{
+	models.PaymentMethodCash:     "Tunai",
+	models.PaymentMethodCard:     "Kartu",
+	models.PaymentMethodQris:     "QRIS",
+	models.PaymentMethodTransfer: "Transfer",
+	models.PaymentMethodMidtrans: "Midtrans",
+	models.PaymentMethodSplit:    "Split",
+}
+
+// GetReceipt collects the receipt of a transaction. Paid and refunded orders get a receipt, pending ones a bill.
+func (rs *ReceiptService) GetReceipt(transactionId uint) (*Receipt, error) {
+	var transaction models.Transaction
+	if err := rs.db.Preload("TransactionDetails", func(db *gorm.DB) *gorm.DB {
+		return db.Where("parent_detail_id IS NULL AND voided_at IS NULL").Order("id ASC")
+	}).Preload("TransactionDetails.Modifiers").Preload("TransactionDetails.Discounts").
+		First(&transaction, transactionId).Error; err != nil {
+		return nil, notFoundError("transaction not found")
+	}
+
+	title, ok := receiptTitles[transaction.PaymentStatus]
+	if !ok {
+		return nil, invalidError("invalid receipt, transaction is %s", transaction.PaymentStatus)
+	}
+
+	date := transaction.CreatedAt
+	if transaction.PaidAt != nil {
+		date = *transaction.PaidAt
+	}
+
+	receipt := &Receipt{
+		Title:          title,
+		OutletName:     config.GetEnv("OUTLET_NAME", "Deck"),
+		OutletAddress:  config.GetEnv("OUTLET_ADDRESS", ""),
+		OutletPhone:    config.GetEnv("OUTLET_PHONE", ""),
+		OrderNumber:    transaction.OrderNumber,
+		Date:           date.In(config.Location()),
+		OrderType:      receiptOrderTypes[transaction.OrderType],
+		BuyerName:      transaction.BuyerName,
+		SubTotal:       transaction.SubTotal,
+		DiscountAmount: transaction.DiscountAmount,
+		PointsRedeemed: transaction.PointsRedeemed,
+		PointsDiscount: transaction.PointsDiscount,
+		Total:          transaction.TotalAmount,
+		TaxRate:        taxRatePercent(),
+		Footer:         config.GetEnv("RECEIPT_FOOTER", "Terima kasih atas kunjungan Anda"),
+	}
+
+	receipt.TaxAmount = includedTax(receipt.Total, receipt.TaxRate)
+
+	if transaction.TableId != nil {
+		var table models.Table
+		if err := rs.db.Select("name").First(&table, *transaction.TableId).Error; err == nil {
+			receipt.TableName = table.Name
+		}
+	}
+
+	for _, detail := range transaction.TransactionDetails {
+		item := ReceiptItem{
+			Name:      detailName(detail),
+			Quantity:  detail.Quantity,
+			UnitPrice: uint(max(int(detail.Price)+detail.ModifiersPrice, 0)),
+			Total:     detail.TotalPrice + detail.DiscountAmount,
+			Notes:     detail.Notes,
+		}
+		for _, modifier := range detail.Modifiers {
+			item.Modifiers = append(item.Modifiers, modifier.Name)
+		}
+		for _, discount := range detail.Discounts {
+			item.Discounts = append(item.Discounts, ReceiptDiscount{Name: discount.Name, Amount: discount.Amount})
+		}
+		receipt.Items = append(receipt.Items, item)
+	}
+
+	var payments []models.Payment
+	if err := rs.db.Where("transaction_id = ? AND status = ?", transaction.Id, models.PaymentStatusPaid).
+		Order("paid_at ASC, id ASC").Find(&payments).Error; err != nil {
+		return nil, fmt.Errorf("failed to load payments: %v", err)
+	}
+	for _, payment := range payments {
+		receipt.Payments = append(receipt.Payments, ReceiptPayment{
+			Method:   paymentMethodLabel(payment.Method),
+			Amount:   payment.Amount,
+			Tendered: payment.Tendered,
+			Change:   payment.Change,
+		})
+		receipt.Cashier = payment.CreatedBy
+	}
+	if len(payments) == 0 && transaction.PaymentStatus != models.PaymentStatusPending {
+		receipt.Payments = append(receipt.Payments, ReceiptPayment{
+			Method: paymentMethodLabel(transaction.PaymentMethod),
+			Amount: transaction.TotalAmount,
+		})
+	}
+
+	return receipt, nil
+}
+
+// RenderEscPos returns the receipt of a transaction as ESC/POS commands for a printer width characters wide
+func (rs *ReceiptService) RenderEscPos(transactionId uint, width int) ([]byte, error) {
+	receipt, err := rs.GetReceipt(transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderReceiptEscPos(receipt, width), nil
+}
+
+// RenderPdf returns the receipt of a transaction as a PDF on 80mm wide paper
+func (rs *ReceiptService) RenderPdf(transactionId uint) ([]byte, error) {
+	receipt, err := rs.GetReceipt(transactionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return renderReceiptPdf(receipt)
+}
+
+// receiptRow is one printed line: text on the left, an amount on the right, or a divider
+type receiptRow struct {
+	left    string
+	right   string
+	align   byte
+	bold    bool
+	large   bool
+	divider bool
+}
+
+// receiptRows lays out a receipt as lines of at most width characters, shared by the ESC/POS and PDF output
+func receiptRows(receipt *Receipt, width int) []receiptRow {
+	var rows []receiptRow
+	center := func(text string, bold bool, large bool) {
+		// Double size characters take two columns each
+		columns := width
+		if large {
+			columns = width / 2
+		}
+		for _, line := range helpers.WrapText(text, columns) {
+			rows = append(rows, receiptRow{left: line, align: helpers.EscPosAlignCenter, bold: bold, large: large})
+		}
+	}
+	pair := func(left string, right string, bold bool) {
+		rows = append(rows, receiptRow{left: left, right: right, bold: bold})
+	}
+	text := func(value string, indent int) {
+		for _, line := range helpers.WrapText(value, width-indent) {
+			rows = append(rows, receiptRow{left: strings.Repeat(" ", indent) + line})
+		}
+	}
+	divider := func() {
+		rows = append(rows, receiptRow{divider: true})
+	}
+
+	center(receipt.OutletName, true, true)
+	if receipt.OutletAddress != "" {
+		center(receipt.OutletAddress, false, false)
+	}
+	if receipt.OutletPhone != "" {
+		center("Telp. "+receipt.OutletPhone, false, false)
+	}
+	center(receipt.Title, true, false)
+	divider()
+
+	pair("No", receipt.OrderNumber, false)
+	pair("Tanggal", receipt.Date.Format("02/01/2006 15:04"), false)
+	if receipt.Cashier != "" {
+		pair("Kasir", receipt.Cashier, false)
+	}
+	if receipt.OrderType != "" {
+		orderType := receipt.OrderType
+		if receipt.TableName != "" {
+			orderType += ", meja " + receipt.TableName
+		}
+		pair("Tipe", orderType, false)
+	}
+	if receipt.BuyerName != "" {
+		pair("Pelanggan", receipt.BuyerName, false)
+	}
+	divider()
+
+	for _, item := range receipt.Items {
+		text(item.Name, 0)
+		pair(fmt.Sprintf("  %d x %s", item.Quantity, helpers.FormatThousands(item.UnitPrice)), helpers.FormatThousands(item.Total), false)
+		for _, modifier := range item.Modifiers {
+			text("+ "+modifier, 2)
+		}
+		if item.Notes != "" {
+			text("* "+item.Notes, 2)
+		}
+		for _, discount := range item.Discounts {
+			pair("  "+discount.Name, "-"+helpers.FormatThousands(discount.Amount), false)
+		}
+	}
+	divider()
+
+	pair("Subtotal", helpers.FormatThousands(receipt.SubTotal), false)
+	if receipt.DiscountAmount > 0 {
+		pair("Diskon", "-"+helpers.FormatThousands(receipt.DiscountAmount), false)
+	}
+	if receipt.PointsDiscount > 0 {
+		pair(fmt.Sprintf("Poin (%d)", receipt.PointsRedeemed), "-"+helpers.FormatThousands(receipt.PointsDiscount), false)
+	}
+	pair("TOTAL", "Rp "+helpers.FormatThousands(receipt.Total), true)
+	if receipt.TaxRate > 0 {
+		pair(fmt.Sprintf("Termasuk PPN %d%%", receipt.TaxRate), helpers.FormatThousands(receipt.TaxAmount), false)
+	}
+
+	if len(receipt.Payments) > 0 {
+		divider()
+		var change uint
+		for _, payment := range receipt.Payments {
+			amount := payment.Amount
+			if payment.Tendered > amount {
+				amount = payment.Tendered
+			}
+			pair(payment.Method, helpers.FormatThousands(amount), false)
+			change += payment.Change
+		}
+		if change > 0 {
+			pair("Kembali", helpers.FormatThousands(change), true)
+		}
+	}
+	divider()
+
+	return rows
+}
+
+func renderReceiptEscPos(receipt *Receipt, width int) []byte {
+	printer := helpers.NewEscPos()
+
+	// The printer keeps alignment and emphasis until told otherwise, only send them when they change
+	align, bold := byte(helpers.EscPosAlignLeft), false
+	style := func(rowAlign byte, rowBold bool) {
+		if rowAlign != align {
+			printer.Align(rowAlign)
+			align = rowAlign
+		}
+		if rowBold != bold {
+			printer.Bold(rowBold)
+			bold = rowBold
+		}
+	}
+
+	for _, row := range receiptRows(receipt, width) {
+		switch {
+		case row.divider:
+			style(helpers.EscPosAlignLeft, false)
+			printer.Line(strings.Repeat("-", width))
+		case row.right != "":
+			style(helpers.EscPosAlignLeft, row.bold)
+			printer.Line(helpers.PadColumns(row.left, row.right, width))
+		case row.large:
+			style(row.align, row.bold)
+			printer.Size(2, 2).Line(row.left).Size(1, 1)
+		default:
+			style(row.align, row.bold)
+			printer.Line(row.left)
+		}
+	}
+
+	style(helpers.EscPosAlignCenter, false)
+	printer.QrCode(receipt.OrderNumber, 6).
+		Line(receipt.OrderNumber)
+	for _, line := range helpers.WrapText(receipt.Footer, width) {
+		printer.Line(line)
+	}
+
+	return printer.Feed(4).Cut().Bytes()
+}
+
+func renderReceiptPdf(receipt *Receipt) ([]byte, error) {
+	qr, err := qrcode.Encode(receipt.OrderNumber, qrcode.Medium, 256)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render qr code: %v", err)
+	}
+
+	// Lay the receipt out once on a long page to measure it, then again on a page of that height
+	draw := func(height float64) (*gofpdf.Fpdf, float64) {
+		const paperWidth, margin, lineHeight = 80.0, 5.0, 4.5
+		pdf := gofpdf.NewCustom(&gofpdf.InitType{
+			UnitStr: "mm",
+			Size:    gofpdf.SizeType{Wd: paperWidth, Ht: height},
+		})
+		pdf.SetMargins(margin, margin, margin)
+		pdf.SetAutoPageBreak(false, margin)
+		pdf.AddPage()
+		tr := pdf.UnicodeTranslatorFromDescriptor("")
+		contentWidth := paperWidth - 2*margin
+
+		for _, row := range receiptRows(receipt, ReceiptWidth80mm) {
+			style := ""
+			if row.bold {
+				style = "B"
+			}
+			size := 8.0
+			if row.large {
+				size = 12
+			}
+			pdf.SetFont("Helvetica", style, size)
+
+			switch {
+			case row.divider:
+				y := pdf.GetY() + lineHeight/2
+				pdf.SetDrawColor(120, 120, 120)
+				pdf.SetDashPattern([]float64{0.8, 0.8}, 0)
+				pdf.Line(margin, y, margin+contentWidth, y)
+				pdf.Ln(lineHeight)
+			case row.right != "":
+				y := pdf.GetY()
+				pdf.CellFormat(contentWidth, lineHeight, tr(row.left), "", 0, "L", false, 0, "")
+				pdf.SetXY(margin, y)
+				pdf.CellFormat(contentWidth, lineHeight, tr(row.right), "", 1, "R", false, 0, "")
+			default:
+				align := "L"
+				if row.align == helpers.EscPosAlignCenter {
+					align = "C"
+				}
+				pdf.CellFormat(contentWidth, lineHeight+size/8, tr(row.left), "", 1, align, false, 0, "")
+			}
+		}
+
+		const qrSize = 28.0
+		pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+		pdf.ImageOptions("qr", (paperWidth-qrSize)/2, pdf.GetY()+2, qrSize, qrSize, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+		pdf.SetY(pdf.GetY() + qrSize + 3)
+
+		pdf.SetFont("Helvetica", "", 8)
+		pdf.CellFormat(contentWidth, lineHeight, tr(receipt.OrderNumber), "", 1, "C", false, 0, "")
+		pdf.MultiCell(contentWidth, lineHeight, tr(receipt.Footer), "", "C", false)
+
+		return pdf, pdf.GetY() + margin
+	}
+
+	_, height := draw(1000)
+	pdf, _ := draw(height)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to render receipt: %v", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// taxRatePercent reads the tax included in prices in percent, TAX_RATE_PERCENT defaults to 0 for no tax
+func taxRatePercent() uint {
+	rate, err := strconv.Atoi(config.GetEnv("TAX_RATE_PERCENT", "0"))
+	if err != nil || rate < 0 || rate > 100 {
+		return 0
+	}
+
+	return uint(rate)
+}
+
+// includedTax is the part of a tax inclusive amount that is tax, rounded to the nearest rupiah
+func includedTax(amount uint, rate uint) uint {
+	if rate == 0 {
+		return 0
+	}
+
+	return (amount*rate + (100+rate)/2) / (100 + rate)
+}
+
+func paymentMethodLabel(method string) string {
+	if label, ok := receiptPaymentMethods[method]; ok {
+		return label
+	}
+
+	return method
+}
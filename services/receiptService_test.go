@@ -0,0 +1,110 @@
+package services
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var updateGolden = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func testReceipt() *Receipt {
+	return &Receipt{
+		Title:         "STRUK PEMBAYARAN",
+		OutletName:    "Deck Coffee",
+		OutletAddress: "Jl. Braga No. 12, Bandung",
+		OutletPhone:   "022-4201234",
+		OrderNumber:   "ORD-20260101-0042",
+		Date:          time.Date(2026, 1, 1, 13, 45, 0, 0, time.UTC),
+		Cashier:       "kasir1",
+		OrderType:     "Makan di tempat",
+		TableName:     "A3",
+		BuyerName:     "Budi",
+		Items: []ReceiptItem{
+			{
+				Name:      "Kopi Susu Gula Aren Large",
+				Quantity:  2,
+				UnitPrice: 28000,
+				Total:     56000,
+				Modifiers: []string{"Extra shot", "Less sugar"},
+				Notes:     "Es dipisah ya, tolong jangan terlalu manis",
+				Discounts: []ReceiptDiscount{{Name: "Happy Hour", Amount: 5600}},
+			},
+			{
+				Name:      "Croissant",
+				Quantity:  1,
+				UnitPrice: 22000,
+				Total:     22000,
+			},
+		},
+		SubTotal:       78000,
+		DiscountAmount: 5600,
+		PointsRedeemed: 20,
+		PointsDiscount: 2000,
+		Total:          70400,
+		TaxRate:        11,
+		TaxAmount:      6977,
+		Payments: []ReceiptPayment{
+			{Method: "QRIS", Amount: 30000},
+			{Method: "Tunai", Amount: 40400, Tendered: 50000, Change: 9600},
+		},
+		Footer: "Terima kasih atas kunjungan Anda",
+	}
+}
+
+// testUnicodeReceipt has names and notes outside ASCII, each of their characters takes one column
+func testUnicodeReceipt() *Receipt {
+	receipt := testReceipt()
+	receipt.BuyerName = "Zoë Müller"
+	receipt.Items[0].Name = "Café Crème Brûlée Spécial Très Grand"
+	receipt.Items[0].Notes = "Tanpa gula ☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕☕ ya"
+	receipt.Items[1].Modifiers = []string{"Crème fraîche"}
+
+	return receipt
+}
+
+func TestRenderReceiptEscPos(t *testing.T) {
+	tests := []struct {
+		name    string
+		receipt func() *Receipt
+		width   int
+	}{
+		{"receipt_58mm", testReceipt, ReceiptWidth58mm},
+		{"receipt_80mm", testReceipt, ReceiptWidth80mm},
+		{"receipt_58mm_unicode", testUnicodeReceipt, ReceiptWidth58mm},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := renderReceiptEscPos(tt.receipt(), tt.width)
+			golden := filepath.Join("testdata", tt.name+".golden")
+
+			if *updateGolden {
+				if err := os.WriteFile(golden, got, 0644); err != nil {
+					t.Fatalf("failed to write %s: %v", golden, err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("failed to read %s, run go test -update to create it: %v", golden, err)
+			}
+			if !bytes.Equal(got, want) {
+				t.Errorf("ESC/POS output differs from %s, run go test -update if the change is intended\ngot:\n%q\nwant:\n%q", golden, got, want)
+			}
+		})
+	}
+}
+
+func TestRenderReceiptPdf(t *testing.T) {
+	pdf, err := renderReceiptPdf(testReceipt())
+	if err != nil {
+		t.Fatalf("renderReceiptPdf: %v", err)
+	}
+	if !bytes.HasPrefix(pdf, []byte("%PDF-")) {
+		t.Errorf("output is not a PDF, starts with %q", pdf[:min(len(pdf), 8)])
+	}
+}
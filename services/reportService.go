@@ -0,0 +1,758 @@
+package services
+
+import (
+	"deck/config"
+	"deck/helpers"
+	"deck/models"
+	"deck/structs"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type ReportService struct {
+	db *gorm.DB
+}
+
+func NewReportService(db *gorm.DB) *ReportService {
+	return &ReportService{db: db}
+}
+
+// salesStatuses are the orders that were sold, a refunded order stays a sale of the day it was paid
+var salesStatuses = []string{models.PaymentStatusPaid, models.PaymentStatusRefunded}
+
+// GetDailyReport sums the sales of a business day, today when date is empty. A closed day returns its Z-report.
+func (rps *ReportService) GetDailyReport(date string) (*structs.DailyReport, error) {
+	day, err := parseBusinessDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	zReport, err := findZReport(rps.db, day)
+	if err != nil {
+		return nil, err
+	}
+	if zReport != nil {
+		return ZReportData(zReport)
+	}
+
+	return buildDailyReport(rps.db, day)
+}
+
+// CloseDay stores the daily report of a day as its Z-report. A day can be closed once and the Z-report never changes,
+// sales recorded on that day afterwards only show in the transactions.
+func (rps *ReportService) CloseDay(date string, closedBy string) (*models.ZReport, error) {
+	day, err := parseBusinessDate(date)
+	if err != nil {
+		return nil, err
+	}
+
+	today := time.Now().In(config.Location()).Format("2006-01-02")
+	if day.Format("2006-01-02") > today {
+		return nil, invalidError("invalid date, %s has not started yet", day.Format("2006-01-02"))
+	}
+
+	var zReport models.ZReport
+	err = rps.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := findZReport(tx, day)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return invalidError("invalid close, %s is already closed", day.Format("2006-01-02"))
+		}
+
+		report, err := buildDailyReport(tx, day)
+		if err != nil {
+			return err
+		}
+
+		zReport = models.ZReport{
+			BusinessDate: day,
+			ClosedAt:     time.Now(),
+			ClosedBy:     closedBy,
+			Orders:       report.Orders,
+			NetSales:     report.NetSales,
+		}
+		report.ZReport = &structs.DailyReportZReport{
+			ClosedAt: zReport.ClosedAt.Format("2006-01-02 15:04:05"),
+			ClosedBy: closedBy,
+		}
+		data, err := json.Marshal(report)
+		if err != nil {
+			return err
+		}
+		zReport.Report = string(data)
+
+		if err := tx.Create(&zReport).Error; err != nil {
+			if strings.Contains(err.Error(), "duplicate key") {
+				return invalidError("invalid close, %s is already closed", day.Format("2006-01-02"))
+			}
+			return fmt.Errorf("failed to close day: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &zReport, nil
+}
+
+// Get All Z-Reports between two dates, newest first
+func (rps *ReportService) GetZReports(fromDate string, toDate string) ([]models.ZReport, error) {
+	if _, _, err := helpers.ParseLocalDateRange(fromDate, toDate); err != nil {
+		return nil, invalidError("%v", err)
+	}
+
+	query := rps.db.Order("business_date DESC").Limit(366)
+	if fromDate != "" {
+		query = query.Where("business_date >= ?", fromDate)
+	}
+	if toDate != "" {
+		query = query.Where("business_date <= ?", toDate)
+	}
+
+	var zReports []models.ZReport
+	err := query.Find(&zReports).Error
+
+	return zReports, err
+}
+
+// Get Z-Report By Id
+func (rps *ReportService) GetZReportById(id uint) (*models.ZReport, error) {
+	var zReport models.ZReport
+	if err := rps.db.First(&zReport, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("z-report not found")
+		}
+		return nil, fmt.Errorf("failed to find z-report: %v", err)
+	}
+
+	return &zReport, nil
+}
+
+// ZReportData decodes the daily report stored in a Z-report
+func ZReportData(zReport *models.ZReport) (*structs.DailyReport, error) {
+	var report structs.DailyReport
+	if err := json.Unmarshal([]byte(zReport.Report), &report); err != nil {
+		return nil, fmt.Errorf("failed to read z-report: %v", err)
+	}
+	if report.ZReport != nil {
+		report.ZReport.Id = zReport.Id
+	}
+
+	return &report, nil
+}
+
+func findZReport(db *gorm.DB, day time.Time) (*models.ZReport, error) {
+	var zReport models.ZReport
+	if err := db.Where("business_date = ?", day.Format("2006-01-02")).First(&zReport).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &zReport, nil
+}
+
+// buildDailyReport computes the report of a day in SQL, timestamps are converted to the outlet timezone by Postgres
+func buildDailyReport(db *gorm.DB, day time.Time) (*structs.DailyReport, error) {
+	date := day.Format("2006-01-02")
+	timezone := config.Location().String()
+
+	report := &structs.DailyReport{
+		Date:           date,
+		Timezone:       timezone,
+		TaxRate:        taxRatePercent(),
+		PaymentMethods: []structs.DailyReportPaymentRow{},
+		Categories:     []structs.DailyReportCategoryRow{},
+	}
+
+	var sales struct {
+		Orders          int64
+		GrossSales      int64
+		Discounts       int64
+		PointsDiscounts int64
+	}
+	if err := db.Table("transactions t").
+		Select(`COUNT(*) AS orders,
+			COALESCE(SUM(t.sub_total), 0) AS gross_sales,
+			COALESCE(SUM(t.discount_amount), 0) AS discounts,
+			COALESCE(SUM(t.points_discount), 0) AS points_discounts`).
+		Where("t.payment_status IN ? AND (t.paid_at AT TIME ZONE ?)::date = ?", salesStatuses, timezone, date).
+		Scan(&sales).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute sales: %v", err)
+	}
+
+	var refunds struct {
+		Count  int64
+		Amount int64
+	}
+	if err := db.Table("transactions t").
+		Select("COUNT(*) AS count, COALESCE(SUM(t.total_amount), 0) AS amount").
+		Where("t.payment_status = ? AND (t.refunded_at AT TIME ZONE ?)::date = ?", models.PaymentStatusRefunded, timezone, date).
+		Scan(&refunds).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute refunds: %v", err)
+	}
+
+	var lost struct {
+		Cancelled int64
+		Expired   int64
+	}
+	if err := db.Table("transactions t").
+		Select(`COUNT(*) FILTER (WHERE t.payment_status = ?) AS cancelled,
+			COUNT(*) FILTER (WHERE t.payment_status = ?) AS expired`, models.PaymentStatusCancelled, models.PaymentStatusExpired).
+		Where("(t.created_at AT TIME ZONE ?)::date = ?", timezone, date).
+		Scan(&lost).Error; err != nil {
+		return nil, fmt.Errorf("failed to count lost orders: %v", err)
+	}
+
+	// Split payments are counted by their own method, orders paid without payment records by the order method
+	if err := db.Raw(`SELECT method, COUNT(*) AS count, COALESCE(SUM(amount), 0) AS amount FROM (
+			SELECT p.method, p.amount
+			FROM payments p JOIN transactions t ON t.id = p.transaction_id
+			WHERE p.status = ? AND t.payment_status IN ? AND (t.paid_at AT TIME ZONE ?)::date = ?
+			UNION ALL
+			SELECT t.payment_method AS method, t.total_amount AS amount
+			FROM transactions t
+			WHERE t.payment_status IN ? AND (t.paid_at AT TIME ZONE ?)::date = ?
+				AND NOT EXISTS (SELECT 1 FROM payments p WHERE p.transaction_id = t.id AND p.status = ?)
+		) paid
+		GROUP BY method
+		ORDER BY amount DESC, method ASC`,
+		models.PaymentStatusPaid, salesStatuses, timezone, date,
+		salesStatuses, timezone, date, models.PaymentStatusPaid).
+		Scan(&report.PaymentMethods).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute payment methods: %v", err)
+	}
+
+	// Bundles count towards the category of the bundle, not of their components
+	if err := db.Table("transaction_details td").
+		Joins("JOIN transactions t ON t.id = td.transaction_id").
+		Joins("JOIN products p ON p.id = td.product_id").
+		Joins("JOIN categories c ON c.id = p.category_id").
+		Select(`c.id AS category_id, MAX(c.display_name) AS category,
+			COALESCE(SUM(td.quantity), 0) AS quantity,
+			COALESCE(SUM(td.total_price + td.discount_amount), 0) AS gross_sales,
+			COALESCE(SUM(td.discount_amount), 0) AS discount_amount,
+			COALESCE(SUM(td.total_price), 0) AS net_sales`).
+		Where("td.parent_detail_id IS NULL AND td.voided_at IS NULL").
+		Where("t.payment_status IN ? AND (t.paid_at AT TIME ZONE ?)::date = ?", salesStatuses, timezone, date).
+		Group("c.id").
+		Order("net_sales DESC, category ASC").
+		Scan(&report.Categories).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute categories: %v", err)
+	}
+
+	report.Orders = sales.Orders
+	report.GrossSales = sales.GrossSales
+	report.Discounts = sales.Discounts
+	report.PointsDiscounts = sales.PointsDiscounts
+	report.Refunds = refunds.Amount
+	report.RefundCount = refunds.Count
+	report.CancelledOrders = lost.Cancelled
+	report.ExpiredOrders = lost.Expired
+	for _, category := range report.Categories {
+		report.ItemsSold += category.Quantity
+	}
+
+	report.NetSales = sales.GrossSales - sales.Discounts - sales.PointsDiscounts - refunds.Amount
+	if report.NetSales >= 0 {
+		report.Tax = int64(includedTax(uint(report.NetSales), report.TaxRate))
+	} else {
+		report.Tax = -int64(includedTax(uint(-report.NetSales), report.TaxRate))
+	}
+	report.NetSalesBeforeTax = report.NetSales - report.Tax
+	if sales.Orders > 0 {
+		report.AverageOrderValue = (sales.GrossSales - sales.Discounts - sales.PointsDiscounts) / sales.Orders
+	}
+
+	return report, nil
+}
+
+// parseBusinessDate reads a YYYY-MM-DD date, today in the outlet timezone when empty. The date is kept at
+// midnight UTC so it is stored as is in date columns.
+func parseBusinessDate(date string) (time.Time, error) {
+	if date == "" {
+		date = time.Now().In(config.Location()).Format("2006-01-02")
+	}
+
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return time.Time{}, invalidError("invalid date, use YYYY-MM-DD")
+	}
+
+	return day, nil
+}
+
+// performanceRow is the sales of one product or category over a period
+type performanceRow struct {
+	Id               uint
+	Name             string
+	Quantity         int64
+	Revenue          int64
+	DiscountAmount   int64
+	Orders           int64
+	PriceTotal       int64
+	ModifierQuantity int64
+	BundleQuantity   int64
+}
+
+// GetProductPerformance ranks products or categories by quantity or revenue. Products are reported under the
+// name and price they were sold with, so renamed or deleted products keep their history. Categories come from
+// the current products, sales of deleted products fall under Uncategorized.
+func (rps *ReportService) GetProductPerformance(query structs.ProductPerformanceQuery) (*structs.ProductPerformanceReport, error) {
+	if query.GroupBy == "" {
+		query.GroupBy = "product"
+	}
+	if query.GroupBy != "product" && query.GroupBy != "category" {
+		return nil, invalidError("invalid group_by, use product or category")
+	}
+	if query.SortBy == "" {
+		query.SortBy = "revenue"
+	}
+	if query.SortBy != "revenue" && query.SortBy != "quantity" {
+		return nil, invalidError("invalid sort_by, use quantity or revenue")
+	}
+	if query.Limit <= 0 || query.Limit > 100 {
+		query.Limit = 20
+	}
+
+	from, to, err := parseReportPeriod(query.From, query.To)
+	if err != nil {
+		return nil, err
+	}
+	days := int(to.Sub(from).Hours()/24) + 1
+	previousTo := from.AddDate(0, 0, -1)
+	previousFrom := from.AddDate(0, 0, -days)
+
+	if query.Bucket == "" {
+		switch {
+		case days <= 62:
+			query.Bucket = "day"
+		case days <= 366:
+			query.Bucket = "week"
+		default:
+			query.Bucket = "month"
+		}
+	}
+	buckets, err := periodBuckets(from, to, query.Bucket)
+	if err != nil {
+		return nil, err
+	}
+
+	current, err := aggregatePerformance(rps.db, query.GroupBy, from, to)
+	if err != nil {
+		return nil, err
+	}
+	previous, err := aggregatePerformance(rps.db, query.GroupBy, previousFrom, previousTo)
+	if err != nil {
+		return nil, err
+	}
+	sortPerformance(current, query.SortBy)
+	sortPerformance(previous, query.SortBy)
+
+	report := &structs.ProductPerformanceReport{
+		From:         from.Format("2006-01-02"),
+		To:           to.Format("2006-01-02"),
+		PreviousFrom: previousFrom.Format("2006-01-02"),
+		PreviousTo:   previousTo.Format("2006-01-02"),
+		GroupBy:      query.GroupBy,
+		SortBy:       query.SortBy,
+		Bucket:       query.Bucket,
+		Buckets:      buckets,
+		Rows:         []structs.ProductPerformanceRow{},
+	}
+
+	if err := countPerformanceOrders(rps.db, from, to, &report.Totals.Orders); err != nil {
+		return nil, err
+	}
+	if err := countPerformanceOrders(rps.db, previousFrom, previousTo, &report.Totals.PreviousOrders); err != nil {
+		return nil, err
+	}
+	// Items sold inside a bundle are already counted once by the bundle line
+	for _, row := range current {
+		report.Totals.Quantity += row.Quantity - row.BundleQuantity
+		report.Totals.Revenue += row.Revenue
+	}
+	previousByKey := map[uint]int{}
+	for i, row := range previous {
+		previousByKey[row.Id] = i
+		report.Totals.PreviousQuantity += row.Quantity - row.BundleQuantity
+		report.Totals.PreviousRevenue += row.Revenue
+	}
+	report.Totals.QuantityChange = percentChange(report.Totals.Quantity, report.Totals.PreviousQuantity)
+	report.Totals.RevenueChange = percentChange(report.Totals.Revenue, report.Totals.PreviousRevenue)
+
+	top := current[:min(len(current), query.Limit)]
+	ids := make([]uint, 0, len(top))
+	for _, row := range top {
+		ids = append(ids, row.Id)
+	}
+	series, err := performanceSeries(rps.db, query.GroupBy, query.Bucket, from, to, ids)
+	if err != nil {
+		return nil, err
+	}
+	bucketIndex := map[string]int{}
+	for i, bucket := range buckets {
+		bucketIndex[bucket] = i
+	}
+
+	for i, row := range top {
+		response := structs.ProductPerformanceRow{
+			Rank:           i + 1,
+			Id:             row.Id,
+			Name:           row.Name,
+			Quantity:       row.Quantity,
+			BundleQuantity: row.BundleQuantity,
+			Revenue:        row.Revenue,
+			DiscountAmount: row.DiscountAmount,
+			Orders:         row.Orders,
+			QuantitySeries: make([]int64, len(buckets)),
+			RevenueSeries:  make([]int64, len(buckets)),
+		}
+		if sold := row.Quantity - row.BundleQuantity; sold > 0 {
+			response.AveragePrice = row.PriceTotal / sold
+			response.ModifierAttachRate = percentOf(row.ModifierQuantity, sold)
+		}
+		if report.Totals.Revenue > 0 {
+			response.RevenueShare = percentOf(row.Revenue, report.Totals.Revenue)
+		}
+		if j, ok := previousByKey[row.Id]; ok {
+			rank := j + 1
+			response.PreviousRank = &rank
+			response.PreviousQuantity = previous[j].Quantity
+			response.PreviousRevenue = previous[j].Revenue
+		}
+		response.QuantityChange = percentChange(response.Quantity, response.PreviousQuantity)
+		response.RevenueChange = percentChange(response.Revenue, response.PreviousRevenue)
+
+		for _, point := range series[row.Id] {
+			if k, ok := bucketIndex[point.Bucket]; ok {
+				response.QuantitySeries[k] = point.Quantity
+				response.RevenueSeries[k] = point.Revenue
+			}
+		}
+		report.Rows = append(report.Rows, response)
+	}
+
+	report.Modifiers, err = modifierAttachRates(rps.db, from, to, query.Limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// performanceLines selects the paid order lines of a period, voided lines not at all. A bundle earns the revenue
+// on its own line, the products in it add the quantity of their component lines. Categories count the bundle only.
+func performanceLines(db *gorm.DB, groupBy string, from time.Time, to time.Time) *gorm.DB {
+	start, end := localDayBounds(from, to)
+
+	query := db.Table("transaction_details td").
+		Joins("JOIN transactions t ON t.id = td.transaction_id").
+		Where("td.voided_at IS NULL").
+		Where("t.payment_status = ? AND t.paid_at >= ? AND t.paid_at < ?", models.PaymentStatusPaid, start, end)
+	if groupBy == "category" {
+		query = query.Where("td.parent_detail_id IS NULL")
+	}
+
+	return query
+}
+
+func aggregatePerformance(db *gorm.DB, groupBy string, from time.Time, to time.Time) ([]performanceRow, error) {
+	measures := `COALESCE(SUM(td.quantity), 0) AS quantity,
+		COALESCE(SUM(td.total_price), 0) AS revenue,
+		COALESCE(SUM(td.discount_amount), 0) AS discount_amount,
+		COUNT(DISTINCT td.transaction_id) AS orders,
+		COALESCE(SUM(td.price * td.quantity), 0) AS price_total,
+		COALESCE(SUM(td.quantity) FILTER (WHERE EXISTS (
+			SELECT 1 FROM transaction_detail_modifiers tdm WHERE tdm.transaction_detail_id = td.id)), 0) AS modifier_quantity,
+		COALESCE(SUM(td.quantity) FILTER (WHERE td.parent_detail_id IS NOT NULL), 0) AS bundle_quantity`
+
+	query := performanceLines(db, groupBy, from, to)
+	if groupBy == "category" {
+		query = query.
+			Joins("LEFT JOIN products p ON p.id = td.product_id").
+			Joins("LEFT JOIN categories c ON c.id = p.category_id").
+			Select("COALESCE(c.id, 0) AS id, COALESCE(MAX(c.display_name), 'Uncategorized') AS name, " + measures).
+			Group("COALESCE(c.id, 0)")
+	} else {
+		// The name of the latest sale, a product renamed during the period shows under its new name
+		query = query.
+			Select("td.product_id AS id, (ARRAY_AGG(td.product_name ORDER BY td.id DESC))[1] AS name, " + measures).
+			Group("td.product_id")
+	}
+
+	var rows []performanceRow
+	if err := query.Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute product performance: %v", err)
+	}
+
+	return rows, nil
+}
+
+type performancePoint struct {
+	Id       uint
+	Bucket   string
+	Quantity int64
+	Revenue  int64
+}
+
+// performanceSeries buckets the sales of the given products or categories by day, week or month in the outlet timezone
+func performanceSeries(db *gorm.DB, groupBy string, bucket string, from time.Time, to time.Time, ids []uint) (map[uint][]performancePoint, error) {
+	series := map[uint][]performancePoint{}
+	if len(ids) == 0 {
+		return series, nil
+	}
+
+	bucketColumn := "TO_CHAR(DATE_TRUNC(?, t.paid_at AT TIME ZONE ?), 'YYYY-MM-DD')"
+	query := performanceLines(db, groupBy, from, to)
+	if groupBy == "category" {
+		query = query.
+			Joins("LEFT JOIN products p ON p.id = td.product_id").
+			Select("COALESCE(p.category_id, 0) AS id, "+bucketColumn+` AS bucket,
+				COALESCE(SUM(td.quantity), 0) AS quantity, COALESCE(SUM(td.total_price), 0) AS revenue`,
+				bucket, config.Location().String()).
+			Where("COALESCE(p.category_id, 0) IN ?", ids).
+			Group("COALESCE(p.category_id, 0), bucket")
+	} else {
+		query = query.
+			Select("td.product_id AS id, "+bucketColumn+` AS bucket,
+				COALESCE(SUM(td.quantity), 0) AS quantity, COALESCE(SUM(td.total_price), 0) AS revenue`,
+				bucket, config.Location().String()).
+			Where("td.product_id IN ?", ids).
+			Group("td.product_id, bucket")
+	}
+
+	var points []performancePoint
+	if err := query.Scan(&points).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute sales series: %v", err)
+	}
+	for _, point := range points {
+		series[point.Id] = append(series[point.Id], point)
+	}
+
+	return series, nil
+}
+
+// modifierAttachRates tells how often each modifier is chosen, out of the quantity sold of the products it was chosen on
+func modifierAttachRates(db *gorm.DB, from time.Time, to time.Time, limit int) ([]structs.ModifierAttachRow, error) {
+	start, end := localDayBounds(from, to)
+
+	rows := []structs.ModifierAttachRow{}
+	if err := db.Raw(`WITH lines AS (
+			SELECT td.id, td.product_id, td.quantity
+			FROM transaction_details td JOIN transactions t ON t.id = td.transaction_id
+			WHERE td.parent_detail_id IS NULL AND td.voided_at IS NULL
+				AND t.payment_status = ? AND t.paid_at >= ? AND t.paid_at < ?
+		), attached AS (
+			SELECT tdm.modifier_id, MAX(tdm.group_name) AS group_name, MAX(tdm.name) AS name,
+				l.product_id, SUM(l.quantity) AS quantity
+			FROM transaction_detail_modifiers tdm JOIN lines l ON l.id = tdm.transaction_detail_id
+			GROUP BY tdm.modifier_id, l.product_id
+		), sold AS (
+			SELECT product_id, SUM(quantity) AS quantity FROM lines GROUP BY product_id
+		)
+		SELECT a.modifier_id, MAX(a.group_name) AS group_name, MAX(a.name) AS name,
+			SUM(a.quantity) AS attached_quantity, SUM(s.quantity) AS eligible_quantity
+		FROM attached a JOIN sold s ON s.product_id = a.product_id
+		GROUP BY a.modifier_id
+		ORDER BY attached_quantity DESC, name ASC
+		LIMIT ?`, models.PaymentStatusPaid, start, end, limit).
+		Scan(&rows).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute modifier attach rates: %v", err)
+	}
+
+	for i := range rows {
+		rows[i].AttachRate = percentOf(rows[i].AttachedQuantity, rows[i].EligibleQuantity)
+	}
+
+	return rows, nil
+}
+
+func countPerformanceOrders(db *gorm.DB, from time.Time, to time.Time, orders *int64) error {
+	start, end := localDayBounds(from, to)
+
+	return db.Model(&models.Transaction{}).
+		Where("payment_status = ? AND paid_at >= ? AND paid_at < ?", models.PaymentStatusPaid, start, end).
+		Count(orders).Error
+}
+
+func sortPerformance(rows []performanceRow, sortBy string) {
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].Revenue, rows[j].Revenue
+		if sortBy == "quantity" {
+			a, b = rows[i].Quantity, rows[j].Quantity
+		}
+		if a != b {
+			return a > b
+		}
+		return rows[i].Name < rows[j].Name
+	})
+}
+
+// parseReportPeriod reads a from and to date, both included. It defaults to the 30 days up to today.
+func parseReportPeriod(fromDate string, toDate string) (time.Time, time.Time, error) {
+	to, err := parseBusinessDate(toDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, invalidError("invalid to date, use YYYY-MM-DD")
+	}
+
+	from := to.AddDate(0, 0, -29)
+	if fromDate != "" {
+		if from, err = time.Parse("2006-01-02", fromDate); err != nil {
+			return time.Time{}, time.Time{}, invalidError("invalid from date, use YYYY-MM-DD")
+		}
+	}
+
+	if from.After(to) {
+		return time.Time{}, time.Time{}, invalidError("invalid date range, from is after to")
+	}
+	if to.Sub(from) > 731*24*time.Hour {
+		return time.Time{}, time.Time{}, invalidError("invalid date range, it can span at most two years")
+	}
+
+	return from, to, nil
+}
+
+// periodBuckets lists the start of every day, week or month of a period, weeks start on Monday like in Postgres
+func periodBuckets(from time.Time, to time.Time, bucket string) ([]string, error) {
+	var start time.Time
+	var next func(time.Time) time.Time
+	switch bucket {
+	case "day":
+		start = from
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 1) }
+	case "week":
+		start = from.AddDate(0, 0, -((int(from.Weekday()) + 6) % 7))
+		next = func(t time.Time) time.Time { return t.AddDate(0, 0, 7) }
+	case "month":
+		start = time.Date(from.Year(), from.Month(), 1, 0, 0, 0, 0, time.UTC)
+		next = func(t time.Time) time.Time { return t.AddDate(0, 1, 0) }
+	default:
+		return nil, invalidError("invalid bucket, use day, week or month")
+	}
+
+	var buckets []string
+	for t := start; !t.After(to); t = next(t) {
+		buckets = append(buckets, t.Format("2006-01-02"))
+	}
+
+	return buckets, nil
+}
+
+// localDayBounds turns business dates into the instants the first one starts and the day after the last one starts
+func localDayBounds(from time.Time, to time.Time) (time.Time, time.Time) {
+	location := config.Location()
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, location)
+	end := time.Date(to.Year(), to.Month(), to.Day()+1, 0, 0, 0, 0, location)
+
+	return start, end
+}
+
+// percentChange is the change from previous to current in percent, nil when there is no previous value
+func percentChange(current int64, previous int64) *float64 {
+	if previous == 0 {
+		return nil
+	}
+
+	change := math.Round(float64(current-previous)/float64(previous)*1000) / 10
+	return &change
+}
+
+func percentOf(part int64, whole int64) float64 {
+	if whole == 0 {
+		return 0
+	}
+
+	return math.Round(float64(part)/float64(whole)*1000) / 10
+}
+
+var heatmapWeekdays = []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday", "Sunday"}
+
+// GetSalesHeatmap buckets the paid orders of a period by weekday and hour in the outlet timezone. Orders count
+// at the time they were placed rather than paid, which is when the staff was busy with them.
+func (rps *ReportService) GetSalesHeatmap(fromDate string, toDate string) (*structs.SalesHeatmap, error) {
+	from, to, err := parseReportPeriod(fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	start, end := localDayBounds(from, to)
+	timezone := config.Location().String()
+
+	var points []struct {
+		Weekday int
+		Hour    int
+		Orders  int64
+		Revenue int64
+	}
+	if err := rps.db.Model(&models.Transaction{}).
+		Select(`EXTRACT(ISODOW FROM created_at AT TIME ZONE ?)::int AS weekday,
+			EXTRACT(HOUR FROM created_at AT TIME ZONE ?)::int AS hour,
+			COUNT(*) AS orders, COALESCE(SUM(total_amount), 0) AS revenue`, timezone, timezone).
+		Where("payment_status = ? AND created_at >= ? AND created_at < ?", models.PaymentStatusPaid, start, end).
+		Group("weekday, hour").
+		Scan(&points).Error; err != nil {
+		return nil, fmt.Errorf("failed to compute sales heatmap: %v", err)
+	}
+
+	heatmap := &structs.SalesHeatmap{
+		From:     from.Format("2006-01-02"),
+		To:       to.Format("2006-01-02"),
+		Timezone: timezone,
+		Weekdays: make([]structs.SalesHeatmapWeekday, 7),
+		Hours:    make([]structs.SalesHeatmapHour, 24),
+		Cells:    make([]structs.SalesHeatmapCell, 7*24),
+	}
+	for i, name := range heatmapWeekdays {
+		heatmap.Weekdays[i] = structs.SalesHeatmapWeekday{Weekday: i + 1, Name: name}
+	}
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		heatmap.Weekdays[(int(day.Weekday())+6)%7].Days++
+	}
+	for hour := range heatmap.Hours {
+		heatmap.Hours[hour].Hour = hour
+	}
+	for i := range heatmap.Cells {
+		heatmap.Cells[i].Weekday = i/24 + 1
+		heatmap.Cells[i].Hour = i % 24
+	}
+
+	for _, point := range points {
+		if point.Weekday < 1 || point.Weekday > 7 || point.Hour < 0 || point.Hour > 23 {
+			continue
+		}
+		cell := &heatmap.Cells[(point.Weekday-1)*24+point.Hour]
+		cell.Orders = point.Orders
+		cell.Revenue = point.Revenue
+
+		heatmap.Weekdays[point.Weekday-1].Orders += point.Orders
+		heatmap.Weekdays[point.Weekday-1].Revenue += point.Revenue
+		heatmap.Hours[point.Hour].Orders += point.Orders
+		heatmap.Hours[point.Hour].Revenue += point.Revenue
+		heatmap.Orders += point.Orders
+		heatmap.Revenue += point.Revenue
+		heatmap.MaxOrders = max(heatmap.MaxOrders, point.Orders)
+		heatmap.MaxRevenue = max(heatmap.MaxRevenue, point.Revenue)
+	}
+
+	for i := range heatmap.Cells {
+		cell := &heatmap.Cells[i]
+		if days := heatmap.Weekdays[cell.Weekday-1].Days; days > 0 {
+			cell.AverageOrders = math.Round(float64(cell.Orders)/float64(days)*10) / 10
+			cell.AverageRevenue = cell.Revenue / int64(days)
+		}
+	}
+
+	return heatmap, nil
+}
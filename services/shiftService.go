@@ -0,0 +1,310 @@
+package services
+
+import (
+	"deck/config"
+	"deck/helpers"
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ShiftService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+func NewShiftService(db *gorm.DB, notificationService *NotificationService) *ShiftService {
+	return &ShiftService{
+		db:                  db,
+		notificationService: notificationService,
+	}
+}
+
+// Get All Shifts, newest first, optionally filtered by status and cashier
+func (shs *ShiftService) GetShifts(status string, userId uint) ([]models.Shift, error) {
+	query := shs.db.Preload("User").Order("opened_at DESC").Limit(100)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if userId != 0 {
+		query = query.Where("user_id = ?", userId)
+	}
+
+	var shifts []models.Shift
+	if err := query.Find(&shifts).Error; err != nil {
+		return nil, err
+	}
+
+	// Open shifts show their running totals
+	for i := range shifts {
+		if shifts[i].Status == models.ShiftStatusOpen {
+			if err := computeShiftCash(shs.db, &shifts[i], time.Now()); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return shifts, nil
+}
+
+// Get Shift By Id with its cash entries, an open shift shows its running totals
+func (shs *ShiftService) GetShiftById(id uint) (*models.Shift, error) {
+	var shift models.Shift
+	if err := shs.db.Preload("User").Preload("CashEntries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at ASC, id ASC")
+	}).First(&shift, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("shift not found")
+		}
+		return nil, fmt.Errorf("failed to find shift: %v", err)
+	}
+
+	if shift.Status == models.ShiftStatusOpen {
+		if err := computeShiftCash(shs.db, &shift, time.Now()); err != nil {
+			return nil, err
+		}
+	}
+
+	return &shift, nil
+}
+
+// GetCurrentShift returns the open shift of the drawer
+func (shs *ShiftService) GetCurrentShift() (*models.Shift, error) {
+	var shift models.Shift
+	if err := shs.db.Select("id").Where("status = ?", models.ShiftStatusOpen).First(&shift).Error; err != nil {
+		return nil, notFoundError("open shift not found")
+	}
+
+	return shs.GetShiftById(shift.Id)
+}
+
+// OpenShift starts a shift for a cashier with the float counted into the drawer
+func (shs *ShiftService) OpenShift(userId uint, req *structs.ShiftOpenRequest) (*models.Shift, error) {
+	if err := shs.db.First(&models.User{}, userId).Error; err != nil {
+		return nil, notFoundError("user not found")
+	}
+
+	var shift models.Shift
+	err := shs.db.Transaction(func(tx *gorm.DB) error {
+		var open models.Shift
+		if err := tx.Preload("User").Where("status = ?", models.ShiftStatusOpen).First(&open).Error; err == nil {
+			username := ""
+			if open.User != nil {
+				username = open.User.Username
+			}
+			return invalidError("invalid shift, the shift of %s opened at %s is still open", username,
+				open.OpenedAt.In(config.Location()).Format("2006-01-02 15:04"))
+		}
+
+		shift = models.Shift{
+			UserId:       userId,
+			Status:       models.ShiftStatusOpen,
+			OpenedAt:     time.Now(),
+			OpeningFloat: req.OpeningFloat,
+			OpenNotes:    strings.TrimSpace(req.Notes),
+		}
+		if err := tx.Create(&shift).Error; err != nil {
+			// The partial unique index catches a shift opened at the same moment
+			if strings.Contains(err.Error(), "idx_shifts_single_open") {
+				return invalidError("invalid shift, another shift was just opened")
+			}
+			return fmt.Errorf("failed to open shift: %v", err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shs.GetShiftById(shift.Id)
+}
+
+// AddCashEntry records cash put in or taken out of the drawer during an open shift
+func (shs *ShiftService) AddCashEntry(id uint, req *structs.ShiftCashEntryRequest, createdBy string) (*models.Shift, error) {
+	err := shs.db.Transaction(func(tx *gorm.DB) error {
+		shift, err := lockOpenShift(tx, id)
+		if err != nil {
+			return err
+		}
+
+		if req.Type == models.ShiftCashPaidOut {
+			if err := computeShiftCash(tx, shift, time.Now()); err != nil {
+				return err
+			}
+			if req.Amount > shift.ExpectedCash {
+				return invalidError("invalid paid out, the drawer should only hold Rp %s", helpers.FormatThousands(shift.ExpectedCash))
+			}
+		}
+
+		return tx.Create(&models.ShiftCashEntry{
+			ShiftId:   shift.Id,
+			Type:      req.Type,
+			Amount:    req.Amount,
+			Reason:    strings.TrimSpace(req.Reason),
+			CreatedBy: createdBy,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return shs.GetShiftById(id)
+}
+
+// CloseShift closes a shift with the cash counted in the drawer and records the variance against the expected
+// cash. Admins are notified when the variance is over SHIFT_VARIANCE_THRESHOLD.
+func (shs *ShiftService) CloseShift(id uint, req *structs.ShiftCloseRequest, closedBy string) (*models.Shift, error) {
+	var shift *models.Shift
+	err := shs.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		shift, err = lockOpenShift(tx, id)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := computeShiftCash(tx, shift, now); err != nil {
+			return err
+		}
+
+		counted := *req.CountedCash
+		shift.Status = models.ShiftStatusClosed
+		shift.ClosedAt = &now
+		shift.ClosedBy = closedBy
+		shift.CountedCash = &counted
+		shift.Variance = int(counted) - int(shift.ExpectedCash)
+		shift.CloseNotes = strings.TrimSpace(req.Notes)
+
+		return tx.Omit("User", "CashEntries").Save(shift).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	closed, err := shs.GetShiftById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if abs(closed.Variance) > shiftVarianceThreshold() {
+		shs.notifyVariance(closed)
+	}
+
+	return closed, nil
+}
+
+func (shs *ShiftService) notifyVariance(shift *models.Shift) {
+	username := ""
+	if shift.User != nil {
+		username = shift.User.Username
+	}
+
+	title := "Selisih Kas"
+	message := fmt.Sprintf("Shift %s ditutup dengan kas lebih Rp %s", username, helpers.FormatThousands(uint(shift.Variance)))
+	if shift.Variance < 0 {
+		message = fmt.Sprintf("Shift %s ditutup dengan kas kurang Rp %s", username, helpers.FormatThousands(uint(-shift.Variance)))
+	}
+	data := map[string]interface{}{
+		"shift_id":      shift.Id,
+		"username":      username,
+		"expected_cash": shift.ExpectedCash,
+		"counted_cash":  shift.CountedCash,
+		"variance":      shift.Variance,
+	}
+	if err := shs.notificationService.BroadcastToAdmins("cash_variance", title, message, data); err != nil {
+		fmt.Printf("Failed to broadcast notification: %v\n", err)
+	}
+}
+
+func lockOpenShift(tx *gorm.DB, id uint) (*models.Shift, error) {
+	var shift models.Shift
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&shift, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("shift not found")
+		}
+		return nil, err
+	}
+	if shift.Status != models.ShiftStatusOpen {
+		return nil, invalidError("invalid shift, it is already closed")
+	}
+
+	return &shift, nil
+}
+
+// computeShiftCash fills the cash totals of a shift from its opening until the given time. Cash sales are the
+// paid cash payments, plus orders paid in cash without a payment record. Cash refunds leave the drawer as
+// paid-out entries.
+func computeShiftCash(db *gorm.DB, shift *models.Shift, until time.Time) error {
+	var payments uint
+	if err := db.Model(&models.Payment{}).
+		Select("COALESCE(SUM(amount), 0)").
+		Where("method = ? AND status = ? AND paid_at >= ? AND paid_at <= ?",
+			models.PaymentMethodCash, models.PaymentStatusPaid, shift.OpenedAt, until).
+		Scan(&payments).Error; err != nil {
+		return fmt.Errorf("failed to compute cash payments: %v", err)
+	}
+
+	var orders uint
+	if err := db.Model(&models.Transaction{}).
+		Select("COALESCE(SUM(total_amount), 0)").
+		Where("payment_method = ? AND payment_status IN ? AND paid_at >= ? AND paid_at <= ?",
+			models.PaymentMethodCash, []string{models.PaymentStatusPaid, models.PaymentStatusRefunded}, shift.OpenedAt, until).
+		Where("NOT EXISTS (SELECT 1 FROM payments p WHERE p.transaction_id = transactions.id AND p.status = ?)", models.PaymentStatusPaid).
+		Scan(&orders).Error; err != nil {
+		return fmt.Errorf("failed to compute cash orders: %v", err)
+	}
+
+	var entries []struct {
+		Type   string
+		Amount uint
+	}
+	if err := db.Model(&models.ShiftCashEntry{}).
+		Select("type, COALESCE(SUM(amount), 0) AS amount").
+		Where("shift_id = ?", shift.Id).Group("type").
+		Scan(&entries).Error; err != nil {
+		return fmt.Errorf("failed to compute cash entries: %v", err)
+	}
+
+	shift.CashSales = payments + orders
+	shift.PaidIn, shift.PaidOut = 0, 0
+	for _, entry := range entries {
+		switch entry.Type {
+		case models.ShiftCashPaidIn:
+			shift.PaidIn = entry.Amount
+		case models.ShiftCashPaidOut:
+			shift.PaidOut = entry.Amount
+		}
+	}
+
+	expected := int(shift.OpeningFloat+shift.CashSales+shift.PaidIn) - int(shift.PaidOut)
+	shift.ExpectedCash = uint(max(expected, 0))
+
+	return nil
+}
+
+// shiftVarianceThreshold is the cash variance in rupiah above which admins are notified,
+// SHIFT_VARIANCE_THRESHOLD defaults to 10000
+func shiftVarianceThreshold() int {
+	threshold, err := strconv.Atoi(config.GetEnv("SHIFT_VARIANCE_THRESHOLD", "10000"))
+	if err != nil || threshold < 0 {
+		return 10000
+	}
+
+	return threshold
+}
+
+func abs(value int) int {
+	if value < 0 {
+		return -value
+	}
+
+	return value
+}
@@ -0,0 +1,208 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StockCountService struct {
+	db *gorm.DB
+}
+
+func NewStockCountService(db *gorm.DB) *StockCountService {
+	return &StockCountService{db: db}
+}
+
+// Get All Stock Counts, newest first
+func (ss *StockCountService) GetStockCounts() ([]models.StockCount, error) {
+	var counts []models.StockCount
+	err := preloadStockCount(ss.db).Order("counted_at DESC").Find(&counts).Error
+
+	return counts, err
+}
+
+// Get Stock Count By Id
+func (ss *StockCountService) GetStockCountById(id uint) (*models.StockCount, error) {
+	var count models.StockCount
+	if err := preloadStockCount(ss.db).First(&count, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("stock count not found")
+		}
+		return nil, fmt.Errorf("failed to find stock count: %v", err)
+	}
+
+	return &count, nil
+}
+
+// Create Stock Count. The theoretical stock is kept with every counted ingredient and the stock is set to the counted quantity.
+func (ss *StockCountService) CreateStockCount(req *structs.StockCountRequest, createdBy string) (*models.StockCount, error) {
+	counted := make(map[uint]float64)
+	for _, item := range req.Items {
+		if _, ok := counted[item.IngredientId]; ok {
+			return nil, invalidError("invalid items, ingredient %d is listed more than once", item.IngredientId)
+		}
+		counted[item.IngredientId] = roundQuantity(item.CountedQuantity)
+	}
+
+	ingredientIds := make([]int, 0, len(counted))
+	for id := range counted {
+		ingredientIds = append(ingredientIds, int(id))
+	}
+	sort.Ints(ingredientIds)
+
+	var stockCount models.StockCount
+	err := ss.db.Transaction(func(tx *gorm.DB) error {
+		ingredients := make([]models.Ingredient, 0, len(ingredientIds))
+		for _, id := range ingredientIds {
+			var ingredient models.Ingredient
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("Unit").First(&ingredient, id).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return invalidError("invalid items, ingredient %d not found", id)
+				}
+				return err
+			}
+			ingredients = append(ingredients, ingredient)
+		}
+
+		stockCount = models.StockCount{
+			CountedAt: time.Now(),
+			Notes:     req.Notes,
+			CreatedBy: createdBy,
+		}
+		for _, ingredient := range ingredients {
+			stockCount.Items = append(stockCount.Items, models.StockCountItem{
+				IngredientId:     ingredient.Id,
+				ExpectedQuantity: ingredient.StockQuantity,
+				CountedQuantity:  counted[ingredient.Id],
+			})
+		}
+		if err := tx.Create(&stockCount).Error; err != nil {
+			return err
+		}
+
+		stockCountId := stockCount.Id
+		for i := range ingredients {
+			variance := counted[ingredients[i].Id] - ingredients[i].StockQuantity
+			if roundQuantity(variance) == 0 {
+				continue
+			}
+			if _, _, err := applyIngredientChange(tx, &ingredients[i], variance, models.IngredientMovement{
+				Type:         models.IngredientMovementCount,
+				StockCountId: &stockCountId,
+				Reference:    fmt.Sprintf("COUNT-%d", stockCountId),
+				CreatedBy:    createdBy,
+			}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("ingredient not found")
+		}
+		return nil, err
+	}
+
+	return ss.GetStockCountById(stockCount.Id)
+}
+
+// Get Variance Report comparing a stock count with the theoretical stock since the previous count.
+// Without a count id the latest count is used.
+func (ss *StockCountService) GetVarianceReport(countId uint) (*structs.IngredientVarianceReport, error) {
+	if countId == 0 {
+		var latest models.StockCount
+		if err := ss.db.Order("counted_at DESC").First(&latest).Error; err != nil {
+			return nil, notFoundError("stock count not found")
+		}
+		countId = latest.Id
+	}
+
+	count, err := ss.GetStockCountById(countId)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &structs.IngredientVarianceReport{
+		StockCountId: count.Id,
+		PeriodEnd:    count.CountedAt.Format("2006-01-02 15:04:05"),
+		Items:        make([]structs.IngredientVarianceItem, 0, len(count.Items)),
+	}
+
+	query := ss.db.Model(&models.IngredientMovement{}).
+		Select("ingredient_id, type, SUM(quantity) AS total").
+		Where("type <> ? AND created_at <= ?", models.IngredientMovementCount, count.CountedAt)
+
+	var previous models.StockCount
+	if err := ss.db.Where("counted_at < ?", count.CountedAt).Order("counted_at DESC").First(&previous).Error; err == nil {
+		periodStart := previous.CountedAt.Format("2006-01-02 15:04:05")
+		report.PeriodStart = &periodStart
+		query = query.Where("created_at > ?", previous.CountedAt)
+	}
+
+	ingredientIds := make([]uint, 0, len(count.Items))
+	for _, item := range count.Items {
+		ingredientIds = append(ingredientIds, item.IngredientId)
+	}
+
+	var totals []struct {
+		IngredientId uint
+		Type         string
+		Total        float64
+	}
+	if len(ingredientIds) > 0 {
+		if err := query.Where("ingredient_id IN ?", ingredientIds).Group("ingredient_id, type").Scan(&totals).Error; err != nil {
+			return nil, fmt.Errorf("failed to load ingredient movements: %v", err)
+		}
+	}
+
+	movements := make(map[uint]map[string]float64)
+	for _, total := range totals {
+		if movements[total.IngredientId] == nil {
+			movements[total.IngredientId] = make(map[string]float64)
+		}
+		movements[total.IngredientId][total.Type] = total.Total
+	}
+
+	for _, item := range count.Items {
+		moved := movements[item.IngredientId]
+		purchased := moved[models.IngredientMovementPurchase]
+		used := -moved[models.IngredientMovementSale]
+		wasted := -moved[models.IngredientMovementWaste]
+		adjusted := moved[models.IngredientMovementAdjustment]
+		variance := roundQuantity(item.CountedQuantity - item.ExpectedQuantity)
+		varianceCost := variance * item.Ingredient.CostPerUnit
+
+		report.Items = append(report.Items, structs.IngredientVarianceItem{
+			IngredientId:   item.IngredientId,
+			IngredientName: item.Ingredient.Name,
+			Unit:           item.Ingredient.Unit.Symbol,
+			Opening:        roundQuantity(item.ExpectedQuantity - purchased + used + wasted - adjusted),
+			Purchased:      roundQuantity(purchased),
+			Used:           roundQuantity(used),
+			Wasted:         roundQuantity(wasted),
+			Adjusted:       roundQuantity(adjusted),
+			Theoretical:    item.ExpectedQuantity,
+			Actual:         item.CountedQuantity,
+			Variance:       variance,
+			VarianceCost:   varianceCost,
+		})
+		report.TotalVarianceCost += varianceCost
+	}
+
+	return report, nil
+}
+
+func preloadStockCount(db *gorm.DB) *gorm.DB {
+	return db.
+		Preload("Items", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).
+		Preload("Items.Ingredient.Unit")
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"deck/config"
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TabService struct {
+	db               *gorm.DB
+	inventoryService *InventoryService
+}
+
+func NewTabService(db *gorm.DB, inventoryService *InventoryService) *TabService {
+	return &TabService{
+		db:               db,
+		inventoryService: inventoryService,
+	}
+}
+
+// AddItems appends order lines to a pending transaction, priced and discounted as of now
+func (tas *TabService) AddItems(transactionId uint, items []structs.TransactionDetailCreateRequest, changedBy string) (*models.Transaction, error) {
+	err := tas.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOpenTab(tx, transactionId)
+		if err != nil {
+			return err
+		}
+
+		runningDiscounts, err := loadRunningDiscounts(tx, time.Now().In(config.Location()))
+		if err != nil {
+			return err
+		}
+
+		var details []models.TransactionDetail
+		for _, item := range items {
+			detail, err := buildOrderLine(tx, item, runningDiscounts)
+			if err != nil {
+				return err
+			}
+			details = append(details, *detail)
+		}
+
+		lines := orderLines(details)
+		if err := checkOrderAvailability(tx, lines); err != nil {
+			return err
+		}
+		if err := tas.inventoryService.reserveStock(tx, lines); err != nil {
+			return err
+		}
+
+		for i := range details {
+			details[i].TransactionId = transaction.Id
+			for j := range details[i].Components {
+				details[i].Components[j].TransactionId = transaction.Id
+			}
+			if err := tx.Create(&details[i]).Error; err != nil {
+				return fmt.Errorf("failed to add item: %v", err)
+			}
+		}
+
+		totalBefore := transaction.TotalAmount
+		if err := recomputeTabTotals(tx, transaction); err != nil {
+			return err
+		}
+
+		// Each line gets its own audit entry, the totals move from before the first to after the last
+		for i, detail := range details {
+			change := models.TransactionChange{
+				TransactionId:       transaction.Id,
+				TransactionDetailId: &details[i].Id,
+				Action:              models.TransactionChangeAddItem,
+				ProductName:         detailName(detail),
+				Quantity:            detail.Quantity,
+				Amount:              detail.TotalPrice,
+				TotalBefore:         totalBefore,
+				TotalAfter:          totalBefore + detail.TotalPrice,
+				ChangedBy:           changedBy,
+			}
+			if err := tx.Create(&change).Error; err != nil {
+				return fmt.Errorf("failed to record change: %v", err)
+			}
+			totalBefore = change.TotalAfter
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadTab(tas.db, transactionId)
+}
+
+// VoidItem takes an order line off a pending transaction and gives back the stock it held
+func (tas *TabService) VoidItem(transactionId uint, detailId uint, reason string, changedBy string) (*models.Transaction, error) {
+	err := tas.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOpenTab(tx, transactionId)
+		if err != nil {
+			return err
+		}
+
+		var detail models.TransactionDetail
+		if err := tx.Preload("Components").
+			Where("id = ? AND transaction_id = ? AND parent_detail_id IS NULL", detailId, transaction.Id).
+			First(&detail).Error; err != nil {
+			return notFoundError("order line not found")
+		}
+		if detail.VoidedAt != nil {
+			return invalidError("invalid void, %s is already voided", detail.ProductName)
+		}
+
+		var remaining int64
+		tx.Model(&models.TransactionDetail{}).
+			Where("transaction_id = ? AND parent_detail_id IS NULL AND voided_at IS NULL AND id <> ?", transaction.Id, detail.Id).
+			Count(&remaining)
+		if remaining == 0 {
+			return invalidError("invalid void, this is the last item of the order, cancel the order instead")
+		}
+
+		released := append([]models.TransactionDetail{detail}, detail.Components...)
+		if err := tas.inventoryService.releaseLineStock(tx, released); err != nil {
+			return err
+		}
+
+		lineIds := []uint{detail.Id}
+		for _, component := range detail.Components {
+			lineIds = append(lineIds, component.Id)
+		}
+		if err := tx.Model(&models.TransactionDetail{}).Where("id IN ?", lineIds).Updates(map[string]interface{}{
+			"voided_at":   time.Now(),
+			"voided_by":   changedBy,
+			"void_reason": reason,
+		}).Error; err != nil {
+			return fmt.Errorf("failed to void item: %v", err)
+		}
+
+		totalBefore := transaction.TotalAmount
+		if err := recomputeTabTotals(tx, transaction); err != nil {
+			return err
+		}
+
+		return tx.Create(&models.TransactionChange{
+			TransactionId:       transaction.Id,
+			TransactionDetailId: &detail.Id,
+			Action:              models.TransactionChangeVoidItem,
+			ProductName:         detailName(detail),
+			Quantity:            detail.Quantity,
+			Amount:              detail.TotalPrice,
+			Reason:              reason,
+			TotalBefore:         totalBefore,
+			TotalAfter:          transaction.TotalAmount,
+			ChangedBy:           changedBy,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadTab(tas.db, transactionId)
+}
+
+// StartPayment locks the order for payment: no more lines can be added or voided and the payment deadline starts
+func (tas *TabService) StartPayment(transactionId uint, changedBy string) (*models.Transaction, error) {
+	err := tas.db.Transaction(func(tx *gorm.DB) error {
+		transaction, err := lockOpenTab(tx, transactionId)
+		if err != nil {
+			return err
+		}
+
+		return startTabPayment(tx, transaction, changedBy)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadTab(tas.db, transactionId)
+}
+
+// Get the audit of a transaction, oldest change first
+func (tas *TabService) GetChanges(transactionId uint) ([]models.TransactionChange, error) {
+	if err := tas.db.First(&models.Transaction{}, transactionId).Error; err != nil {
+		return nil, notFoundError("transaction not found")
+	}
+
+	var changes []models.TransactionChange
+	err := tas.db.Where("transaction_id = ?", transactionId).Order("created_at ASC, id ASC").Find(&changes).Error
+
+	return changes, err
+}
+
+// lockOpenTab loads a transaction with SELECT ... FOR UPDATE and checks it can still be changed
+func lockOpenTab(tx *gorm.DB, transactionId uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, transactionId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("transaction not found")
+		}
+		return nil, err
+	}
+
+	if transaction.PaymentStatus != models.PaymentStatusPending {
+		return nil, invalidError("invalid order, it is already %s", transaction.PaymentStatus)
+	}
+	if transaction.PaymentStartedAt != nil || transaction.MidtransToken != "" {
+		return nil, invalidError("invalid order, payment has started and the order is locked")
+	}
+
+	return &transaction, nil
+}
+
+// startTabPayment marks a locked pending transaction as being paid, from now on its lines are final
+func startTabPayment(tx *gorm.DB, transaction *models.Transaction, changedBy string) error {
+	now := time.Now()
+	expiredAt := now.Add(paymentExpiry())
+	transaction.PaymentStartedAt = &now
+	transaction.ExpiredAt = &expiredAt
+
+	if err := tx.Model(transaction).Updates(map[string]interface{}{
+		"payment_started_at": now,
+		"expired_at":         expiredAt,
+	}).Error; err != nil {
+		return fmt.Errorf("failed to start payment: %v", err)
+	}
+
+	return tx.Create(&models.TransactionChange{
+		TransactionId: transaction.Id,
+		Action:        models.TransactionChangeStartPayment,
+		TotalBefore:   transaction.TotalAmount,
+		TotalAfter:    transaction.TotalAmount,
+		ChangedBy:     changedBy,
+	}).Error
+}
+
+// recomputeTabTotals sums the lines that are not voided into the totals of a locked transaction. Points redeemed
+// at checkout keep their discount, so voiding cannot bring the total below it.
+func recomputeTabTotals(tx *gorm.DB, transaction *models.Transaction) error {
+	var totals struct {
+		Net      uint
+		Discount uint
+	}
+	if err := tx.Model(&models.TransactionDetail{}).
+		Select("COALESCE(SUM(total_price), 0) AS net, COALESCE(SUM(discount_amount), 0) AS discount").
+		Where("transaction_id = ? AND parent_detail_id IS NULL AND voided_at IS NULL", transaction.Id).
+		Scan(&totals).Error; err != nil {
+		return fmt.Errorf("failed to compute totals: %v", err)
+	}
+
+	if totals.Net < transaction.PointsDiscount {
+		return invalidError("invalid change, the %d points redeemed on this order are worth more than what is left", transaction.PointsRedeemed)
+	}
+
+	transaction.SubTotal = totals.Net + totals.Discount
+	transaction.DiscountAmount = totals.Discount
+	transaction.TotalAmount = totals.Net - transaction.PointsDiscount
+
+	return tx.Model(transaction).Updates(map[string]interface{}{
+		"sub_total":       transaction.SubTotal,
+		"discount_amount": transaction.DiscountAmount,
+		"total_amount":    transaction.TotalAmount,
+	}).Error
+}
+
+func loadTab(db *gorm.DB, transactionId uint) (*models.Transaction, error) {
+	var transaction models.Transaction
+	if err := db.Preload("TransactionDetails.Modifiers").Preload("TransactionDetails.Discounts").First(&transaction, transactionId).Error; err != nil {
+		return nil, err
+	}
+
+	return &transaction, nil
+}
+
+func detailName(detail models.TransactionDetail) string {
+	if detail.VariantName == "" {
+		return detail.ProductName
+	}
+
+	return strings.TrimSpace(detail.ProductName + " " + detail.VariantName)
+}
@@ -0,0 +1,392 @@
+package services
+
+import (
+	"deck/models"
+	"deck/structs"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TableService struct {
+	db *gorm.DB
+}
+
+func NewTableService(db *gorm.DB) *TableService {
+	return &TableService{db: db}
+}
+
+// TableTabs is a table together with its open tabs, the pending dine-in orders placed on it
+type TableTabs struct {
+	Table models.Table
+	Tabs  []models.Transaction
+}
+
+// Get All Areas with their tables
+func (tbs *TableService) GetAreas() ([]models.Area, error) {
+	var areas []models.Area
+	err := tbs.db.Preload("Tables", func(db *gorm.DB) *gorm.DB {
+		return db.Order("name ASC")
+	}).Order("sort_order ASC, name ASC").Find(&areas).Error
+
+	return areas, err
+}
+
+// Create Area
+func (tbs *TableService) CreateArea(req *structs.AreaRequest) (*models.Area, error) {
+	name := strings.TrimSpace(req.Name)
+	if err := tbs.checkAreaName(name, 0); err != nil {
+		return nil, err
+	}
+
+	area := models.Area{
+		Name:      name,
+		SortOrder: req.SortOrder,
+	}
+	if err := tbs.db.Create(&area).Error; err != nil {
+		return nil, fmt.Errorf("failed to create area: %v", err)
+	}
+
+	return &area, nil
+}
+
+// Update Area
+func (tbs *TableService) UpdateArea(id uint, req *structs.AreaRequest) (*models.Area, error) {
+	var area models.Area
+	if err := tbs.db.First(&area, id).Error; err != nil {
+		return nil, notFoundError("area not found")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if err := tbs.checkAreaName(name, id); err != nil {
+		return nil, err
+	}
+
+	area.Name = name
+	area.SortOrder = req.SortOrder
+	if err := tbs.db.Save(&area).Error; err != nil {
+		return nil, fmt.Errorf("failed to update area: %v", err)
+	}
+
+	return &area, nil
+}
+
+// Delete Area when it has no tables
+func (tbs *TableService) DeleteArea(id uint) error {
+	var area models.Area
+	if err := tbs.db.First(&area, id).Error; err != nil {
+		return notFoundError("area not found")
+	}
+
+	var usage int64
+	tbs.db.Model(&models.Table{}).Where("area_id = ?", id).Count(&usage)
+	if usage > 0 {
+		return invalidError("area %s is in use by %d table(s)", area.Name, usage)
+	}
+
+	if err := tbs.db.Delete(&area).Error; err != nil {
+		return fmt.Errorf("failed to delete area: %v", err)
+	}
+
+	return nil
+}
+
+// Get All Tables, optionally filtered by area and status
+func (tbs *TableService) GetTables(areaId uint, status string) ([]models.Table, error) {
+	query := tbs.db.Preload("Area").Order("name ASC")
+	if areaId != 0 {
+		query = query.Where("area_id = ?", areaId)
+	}
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+
+	var tables []models.Table
+	err := query.Find(&tables).Error
+
+	return tables, err
+}
+
+// Get Table By Id
+func (tbs *TableService) GetTableById(id uint) (*models.Table, error) {
+	var table models.Table
+	if err := tbs.db.Preload("Area").First(&table, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("table not found")
+		}
+		return nil, fmt.Errorf("failed to find table: %v", err)
+	}
+
+	return &table, nil
+}
+
+// Create Table
+func (tbs *TableService) CreateTable(req *structs.TableRequest) (*models.Table, error) {
+	table := models.Table{
+		Status:   models.TableStatusFree,
+		IsActive: true,
+	}
+	if err := tbs.fillTable(&table, req, 0); err != nil {
+		return nil, err
+	}
+
+	if err := tbs.db.Create(&table).Error; err != nil {
+		return nil, fmt.Errorf("failed to create table: %v", err)
+	}
+
+	return tbs.GetTableById(table.Id)
+}
+
+// Update Table
+func (tbs *TableService) UpdateTable(id uint, req *structs.TableRequest) (*models.Table, error) {
+	table, err := tbs.GetTableById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tbs.fillTable(table, req, id); err != nil {
+		return nil, err
+	}
+	table.Area = nil
+
+	if err := tbs.db.Save(table).Error; err != nil {
+		return nil, fmt.Errorf("failed to update table: %v", err)
+	}
+
+	return tbs.GetTableById(id)
+}
+
+// Delete Table when no order was ever placed on it, deactivate it otherwise
+func (tbs *TableService) DeleteTable(id uint) error {
+	table, err := tbs.GetTableById(id)
+	if err != nil {
+		return err
+	}
+
+	var usage int64
+	tbs.db.Model(&models.Transaction{}).Where("table_id = ?", id).Count(&usage)
+	if usage > 0 {
+		return invalidError("table %s is in use by %d order(s), deactivate it instead", table.Name, usage)
+	}
+
+	if err := tbs.db.Delete(&models.Table{}, id).Error; err != nil {
+		return fmt.Errorf("failed to delete table: %v", err)
+	}
+
+	return nil
+}
+
+// SetTableStatus lets staff mark a table as cleaned or as needing cleaning, occupying a table is left to orders
+func (tbs *TableService) SetTableStatus(id uint, status string) (*models.Table, error) {
+	err := tbs.db.Transaction(func(tx *gorm.DB) error {
+		table, err := lockTable(tx, id)
+		if err != nil {
+			return err
+		}
+
+		openTabs, err := countOpenTabs(tx, id)
+		if err != nil {
+			return err
+		}
+		if openTabs > 0 {
+			return invalidError("invalid status, table %s still has %d open tab(s)", table.Name, openTabs)
+		}
+
+		if err := tx.Model(table).Updates(map[string]interface{}{
+			"status":         status,
+			"occupied_since": nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		return closeOrderingSessions(tx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tbs.GetTableById(id)
+}
+
+// GetOpenTabs lists the occupied tables with their open tabs, oldest order first
+func (tbs *TableService) GetOpenTabs(tableId uint) ([]TableTabs, error) {
+	query := tbs.db.Preload("TransactionDetails.Modifiers").Preload("TransactionDetails.Discounts").
+		Where("order_type = ? AND table_id IS NOT NULL AND payment_status = ?", models.OrderTypeDineIn, models.PaymentStatusPending).
+		Order("created_at ASC")
+	if tableId != 0 {
+		if _, err := tbs.GetTableById(tableId); err != nil {
+			return nil, err
+		}
+		query = query.Where("table_id = ?", tableId)
+	}
+
+	var transactions []models.Transaction
+	if err := query.Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	tabsByTable := map[uint][]models.Transaction{}
+	var tableIds []uint
+	for _, transaction := range transactions {
+		if _, ok := tabsByTable[*transaction.TableId]; !ok {
+			tableIds = append(tableIds, *transaction.TableId)
+		}
+		tabsByTable[*transaction.TableId] = append(tabsByTable[*transaction.TableId], transaction)
+	}
+	if len(tableIds) == 0 {
+		return []TableTabs{}, nil
+	}
+
+	var tables []models.Table
+	if err := tbs.db.Preload("Area").Where("id IN ?", tableIds).Order("name ASC").Find(&tables).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]TableTabs, 0, len(tables))
+	for _, table := range tables {
+		result = append(result, TableTabs{Table: table, Tabs: tabsByTable[table.Id]})
+	}
+
+	return result, nil
+}
+
+func (tbs *TableService) checkAreaName(name string, id uint) error {
+	var count int64
+	tbs.db.Model(&models.Area{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, id).Count(&count)
+	if count > 0 {
+		return invalidError("area %s already exists", name)
+	}
+
+	return nil
+}
+
+func (tbs *TableService) fillTable(table *models.Table, req *structs.TableRequest, id uint) error {
+	if err := tbs.db.First(&models.Area{}, req.AreaId).Error; err != nil {
+		return invalidError("invalid area_id, area not found")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	var count int64
+	tbs.db.Model(&models.Table{}).Where("LOWER(name) = LOWER(?) AND id <> ?", name, id).Count(&count)
+	if count > 0 {
+		return invalidError("table %s already exists", name)
+	}
+
+	table.AreaId = req.AreaId
+	table.Name = name
+	table.Seats = req.Seats
+	if req.IsActive != nil {
+		table.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+func lockTable(tx *gorm.DB, tableId uint) (*models.Table, error) {
+	var table models.Table
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&table, tableId).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, notFoundError("table not found")
+		}
+		return nil, err
+	}
+
+	return &table, nil
+}
+
+func countOpenTabs(tx *gorm.DB, tableId uint) (int64, error) {
+	var count int64
+	err := tx.Model(&models.Transaction{}).
+		Where("table_id = ? AND order_type = ? AND payment_status = ?", tableId, models.OrderTypeDineIn, models.PaymentStatusPending).
+		Count(&count).Error
+
+	return count, err
+}
+
+// seatOrder checks the order type and table of a new order and occupies the table of a dine-in order. More
+// orders can be seated on a table that is already occupied, a table waiting for cleaning takes none.
+func seatOrder(tx *gorm.DB, orderType string, tableId *uint) (string, error) {
+	if orderType == "" {
+		orderType = models.OrderTypeTakeaway
+	}
+	if orderType != models.OrderTypeDineIn {
+		if tableId != nil {
+			return "", invalidError("invalid table_id, a %s order has no table", orderType)
+		}
+		return orderType, nil
+	}
+	if tableId == nil {
+		return "", invalidError("invalid table_id, a dine_in order needs a table")
+	}
+
+	table, err := lockTable(tx, *tableId)
+	if err != nil {
+		return "", invalidError("invalid table_id, %v", err)
+	}
+	switch {
+	case !table.IsActive:
+		return "", invalidError("invalid table_id, table %s is not in use", table.Name)
+	case table.Status == models.TableStatusNeedsCleaning:
+		return "", invalidError("invalid table_id, table %s needs cleaning first", table.Name)
+	case table.Status == models.TableStatusOccupied:
+		return orderType, nil
+	}
+
+	now := time.Now()
+	err = tx.Model(table).Updates(map[string]interface{}{
+		"status":         models.TableStatusOccupied,
+		"occupied_since": &now,
+	}).Error
+
+	return orderType, err
+}
+
+// releaseTable runs when a dine-in order leaves pending. Once the table has no open tab left it needs cleaning
+// if any order of the sitting was paid, otherwise the guests never ate and it is free again. Either way the guest
+// ordering sessions of the sitting end.
+func releaseTable(tx *gorm.DB, transaction *models.Transaction) error {
+	if transaction.TableId == nil || transaction.OrderType != models.OrderTypeDineIn {
+		return nil
+	}
+
+	table, err := lockTable(tx, *transaction.TableId)
+	if err != nil {
+		return err
+	}
+	if table.Status != models.TableStatusOccupied {
+		return nil
+	}
+
+	openTabs, err := countOpenTabs(tx, table.Id)
+	if err != nil || openTabs > 0 {
+		return err
+	}
+
+	paid := tx.Model(&models.Transaction{}).
+		Where("table_id = ? AND payment_status IN ?", table.Id, []string{models.PaymentStatusPaid, models.PaymentStatusRefunded})
+	if table.OccupiedSince != nil {
+		paid = paid.Where("created_at >= ?", *table.OccupiedSince)
+	}
+	var paidCount int64
+	if err := paid.Count(&paidCount).Error; err != nil {
+		return err
+	}
+
+	status := models.TableStatusFree
+	if paidCount > 0 {
+		status = models.TableStatusNeedsCleaning
+	}
+
+	if err := tx.Model(table).Updates(map[string]interface{}{
+		"status":         status,
+		"occupied_since": nil,
+	}).Error; err != nil {
+		return err
+	}
+
+	return closeOrderingSessions(tx, table.Id)
+}
@@ -1,21 +1,31 @@
 package services
 
 import (
+	"deck/config"
+	"deck/helpers"
 	"deck/models"
 	"deck/structs"
+	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type TransactionService struct {
-	db *gorm.DB
+	db                *gorm.DB
+	inventoryService  *InventoryService
+	ingredientService *IngredientService
 }
 
-func NewTransactionService(db *gorm.DB) *TransactionService {
+func NewTransactionService(db *gorm.DB, inventoryService *InventoryService, ingredientService *IngredientService) *TransactionService {
 	return &TransactionService{
-		db: db,
+		db:                db,
+		inventoryService:  inventoryService,
+		ingredientService: ingredientService,
 	}
 }
 
@@ -31,52 +41,124 @@ func (ts *TransactionService) CreateTransaction(req *structs.TransactionCreateRe
 	// Generate order number
 	orderNumber := ts.generateOrderNumber()
 
+	expiredAt := time.Now().Add(paymentExpiry())
+
+	// Recognise the buyer by phone number so repeat customers share one profile
+	phone, err := helpers.NormalizePhone(req.Phone)
+	if err != nil {
+		tx.Rollback()
+		return nil, invalidError("%v", err)
+	}
+	customer, err := upsertCustomer(tx, req.BuyerName, phone)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Dine-in orders occupy their table. The table is locked before the session and stock rows, the order
+	// changeStatus takes them in too.
+	orderType, err := seatOrder(tx, req.OrderType, req.TableId)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	// Orders from a guest's phone count against the limits of their session
+	if req.SessionId != nil {
+		if err := claimSessionOrder(tx, *req.SessionId); err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+	}
+
 	transaction := models.Transaction{
 		OrderNumber:   orderNumber,
+		OrderType:     orderType,
+		TableId:       req.TableId,
+		SessionId:     req.SessionId,
+		CustomerId:    &customer.Id,
 		BuyerName:     req.BuyerName,
-		Phone:         req.Phone,
+		Phone:         phone,
 		PaymentStatus: models.PaymentStatusPending,
 		PaymentMethod: "midtrans",
+		ExpiredAt:     &expiredAt,
+	}
+
+	// A dine-in tab stays open while the guests keep ordering, its payment deadline starts with the payment
+	if orderType == models.OrderTypeDineIn {
+		transaction.ExpiredAt = nil
 	}
 
 	// Calculate totals dan create transaction details
 	var subTotal uint = 0
+	var discountTotal uint = 0
 	var transactionDetails []models.TransactionDetail
 
+	runningDiscounts, err := loadRunningDiscounts(tx, time.Now().In(config.Location()))
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
 	for _, item := range req.Items {
-		var product models.Product
-		if err := tx.Where("id = ? AND is_available = ?", item.ProductId, true).First(&product).Error; err != nil {
+		detail, err := buildOrderLine(tx, item, runningDiscounts)
+		if err != nil {
 			tx.Rollback()
-			return nil, fmt.Errorf("product not found or not available: %d", item.ProductId)
+			return nil, err
 		}
+		subTotal += detail.TotalPrice + detail.DiscountAmount
+		discountTotal += detail.DiscountAmount
+		transactionDetails = append(transactionDetails, *detail)
+	}
 
-		// Calculate item total
-		itemTotal := product.Price * item.Quantity
-		subTotal += itemTotal
+	// Check availability windows and reserve stock for the order lines, bundle components included
+	lines := orderLines(transactionDetails)
+	if err := checkOrderAvailability(tx, lines); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	if err := ts.inventoryService.reserveStock(tx, lines); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	totalAmount := subTotal - discountTotal
 
-		detail := models.TransactionDetail{
-			ProductId:   product.Id,
-			ProductName: product.Name,
-			Quantity:    item.Quantity,
-			Price:       product.Price,
-			TotalPrice:  itemTotal,
-			Notes:       item.Notes,
+	// Loyalty points pay for part of what is left after discounts
+	if req.RedeemPoints > 0 {
+		customer, err = lockCustomer(tx, customer.Id)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
 		}
-		transactionDetails = append(transactionDetails, detail)
+		pointsDiscount, err := quoteRedemption(tx, customer, req.RedeemPoints, totalAmount)
+		if err != nil {
+			tx.Rollback()
+			return nil, err
+		}
+		transaction.PointsRedeemed = req.RedeemPoints
+		transaction.PointsDiscount = pointsDiscount
+		totalAmount -= pointsDiscount
 	}
 
-	totalAmount := subTotal
-
 	transaction.SubTotal = subTotal
+	transaction.DiscountAmount = discountTotal
 	transaction.TotalAmount = totalAmount
 
 	if err := tx.Create(&transaction).Error; err != nil {
 		tx.Rollback()
 		return nil, err
 	}
+	if err := recordRedemption(tx, customer, &transaction); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
 
 	for i := range transactionDetails {
 		transactionDetails[i].TransactionId = transaction.Id
+		for j := range transactionDetails[i].Components {
+			transactionDetails[i].Components[j].TransactionId = transaction.Id
+		}
 		if err := tx.Create(&transactionDetails[i]).Error; err != nil {
 			tx.Rollback()
 			return nil, err
@@ -88,11 +170,132 @@ func (ts *TransactionService) CreateTransaction(req *structs.TransactionCreateRe
 	}
 
 	// Load transaction details untuk response
-	ts.db.Preload("TransactionDetails").First(&transaction, transaction.Id)
+	ts.db.Preload("TransactionDetails.Modifiers").Preload("TransactionDetails.Discounts").First(&transaction, transaction.Id)
 
 	return &transaction, nil
 }
 
+// CreateSessionOrder places a dine-in order from a guest ordering session, on the table the session was opened at
+func (ts *TransactionService) CreateSessionOrder(sessionToken string, req *structs.TransactionCreateRequest) (*models.Transaction, error) {
+	session, err := findActiveSession(ts.db, sessionToken)
+	if err != nil {
+		return nil, err
+	}
+
+	req.OrderType = models.OrderTypeDineIn
+	req.TableId = &session.TableId
+	req.SessionId = &session.Id
+
+	return ts.CreateTransaction(req)
+}
+
+// buildOrderLine prices one requested item at order time: the effective price, variant, modifiers and bundle
+// choices make the line total and the running automatic discounts come off it
+func buildOrderLine(tx *gorm.DB, item structs.TransactionDetailCreateRequest, runningDiscounts []models.Discount) (*models.TransactionDetail, error) {
+	if item.ProductId == 0 {
+		var scanned models.Product
+		if err := tx.Select("id").Where("barcode = ?", strings.TrimSpace(item.Barcode)).First(&scanned).Error; err != nil {
+			return nil, invalidError("product not found for barcode: %s", item.Barcode)
+		}
+		item.ProductId = scanned.Id
+	}
+
+	var product models.Product
+	if err := tx.Where("id = ? AND is_available = ?", item.ProductId, true).First(&product).Error; err != nil {
+		return nil, invalidError("product not found or not available: %d", item.ProductId)
+	}
+
+	// The price history decides the price at order time, products.price only follows it once the worker runs
+	basePrice := product.Price
+	var priceId *uint
+	effectivePrice, err := effectiveProductPrice(tx, product.Id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if effectivePrice != nil {
+		basePrice = effectivePrice.Price
+		priceId = &effectivePrice.Id
+	}
+
+	unitPrice := basePrice
+	var variantId *uint
+	var variantName string
+
+	if item.VariantId != nil {
+		var variant models.ProductVariant
+		if err := tx.Where("id = ? AND product_id = ? AND is_available = ?", *item.VariantId, product.Id, true).First(&variant).Error; err != nil {
+			return nil, invalidError("variant not found or not available: %d", *item.VariantId)
+		}
+
+		unitPrice = variant.ResolvePrice(basePrice)
+		variantId = &variant.Id
+		variantName = variant.Name
+	} else {
+		var variantCount int64
+		if err := tx.Model(&models.ProductVariant{}).Where("product_id = ?", product.Id).Count(&variantCount).Error; err != nil {
+			return nil, fmt.Errorf("failed to load variants: %v", err)
+		}
+		if variantCount > 0 {
+			return nil, invalidError("variant is required for product: %s", product.Name)
+		}
+	}
+
+	modifiers, modifiersPrice, err := resolveModifierSelection(tx, &product, item.ModifierIds)
+	if err != nil {
+		return nil, err
+	}
+
+	components, choicesPrice, err := resolveBundleSelection(tx, &product, item.BundleChoices, item.Quantity)
+	if err != nil {
+		return nil, err
+	}
+
+	if choicesPrice != 0 {
+		unitPrice = uint(max(int(unitPrice)+choicesPrice, 0))
+	}
+
+	lineUnitPrice := int(unitPrice) + modifiersPrice
+	if lineUnitPrice < 0 {
+		lineUnitPrice = 0
+	}
+
+	// Calculate item total, automatic discounts come off the line total
+	itemTotal := uint(lineUnitPrice) * item.Quantity
+	discounts, discountAmount := applyAutomaticDiscounts(runningDiscounts, &product, itemTotal, item.Quantity)
+
+	detail := models.TransactionDetail{
+		ProductId:      product.Id,
+		ProductName:    product.Name,
+		VariantId:      variantId,
+		VariantName:    variantName,
+		Quantity:       item.Quantity,
+		Price:          unitPrice,
+		PriceId:        priceId,
+		ModifiersPrice: modifiersPrice,
+		DiscountAmount: discountAmount,
+		TotalPrice:     itemTotal - discountAmount,
+		Notes:          item.Notes,
+		Modifiers:      modifiers,
+		Discounts:      discounts,
+		Components:     components,
+	}
+
+	return &detail, nil
+}
+
+// orderLines lists the order lines with the components of bundle lines after them
+func orderLines(details []models.TransactionDetail) []*models.TransactionDetail {
+	var lines []*models.TransactionDetail
+	for i := range details {
+		lines = append(lines, &details[i])
+		for j := range details[i].Components {
+			lines = append(lines, &details[i].Components[j])
+		}
+	}
+
+	return lines
+}
+
 // generateOrderNumber
 func (ts *TransactionService) generateOrderNumber() string {
 	timestamp := time.Now().Format("20060102150405")
@@ -102,7 +305,7 @@ func (ts *TransactionService) generateOrderNumber() string {
 // GetTransactionByID
 func (ts *TransactionService) GetTransactionByID(id uint) (*models.Transaction, error) {
 	var transaction models.Transaction
-	if err := ts.db.Preload("TransactionDetails").First(&transaction, id).Error; err != nil {
+	if err := ts.db.Preload("TransactionDetails.Modifiers").Preload("TransactionDetails.Discounts").First(&transaction, id).Error; err != nil {
 		return nil, err
 	}
 	return &transaction, nil
@@ -111,24 +314,168 @@ func (ts *TransactionService) GetTransactionByID(id uint) (*models.Transaction,
 // GetAllTransactions
 func (ts *TransactionService) GetAllTransactions() ([]models.Transaction, error) {
 	var transactions []models.Transaction
-	if err := ts.db.Preload("TransactionDetails").Order("created_at DESC").Find(&transactions).Error; err != nil {
+	if err := ts.db.Preload("TransactionDetails.Modifiers").Preload("TransactionDetails.Discounts").Order("created_at DESC").Find(&transactions).Error; err != nil {
 		return nil, err
 	}
 	return transactions, nil
 }
 
-// UpdateTransactionStatus
+// UpdateTransactionStatus moves a pending transaction to its final status, or a paid one to refunded. Paying
+// consumes the reserved stock and the recipe ingredients and earns loyalty points, failing, expiring or
+// cancelling releases the reservation and returns redeemed points, refunding reverses the points. Closing the last
+// open tab of a table hands the table over for cleaning.
 func (ts *TransactionService) UpdateTransactionStatus(orderNumber string, status string) error {
-	var transaction models.Transaction
-	if err := ts.db.Where("order_number = ?", orderNumber).First(&transaction).Error; err != nil {
+	var alerts *statusAlerts
+
+	err := ts.db.Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("order_number = ?", orderNumber).First(&transaction).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return notFoundError("transaction not found")
+			}
+			return err
+		}
+
+		var err error
+		alerts, err = ts.changeStatus(tx, &transaction, status)
+		return err
+	})
+	if err != nil {
 		return err
 	}
 
+	ts.notifyStatusAlerts(alerts)
+
+	return nil
+}
+
+// statusAlerts collects the stock alerts raised while paying a transaction, they are sent once it commits
+type statusAlerts struct {
+	stock       []lowStockAlert
+	ingredients []ingredientAlert
+}
+
+// changeStatus applies a status change to a locked transaction, see UpdateTransactionStatus
+func (ts *TransactionService) changeStatus(tx *gorm.DB, transaction *models.Transaction, status string) (*statusAlerts, error) {
+	alerts := &statusAlerts{}
+	if transaction.PaymentStatus == status {
+		return alerts, nil
+	}
+	refunding := status == models.PaymentStatusRefunded
+	if refunding && transaction.PaymentStatus != models.PaymentStatusPaid {
+		return nil, invalidError("invalid status change, only paid transactions can be refunded, transaction is %s", transaction.PaymentStatus)
+	}
+	if !refunding && transaction.PaymentStatus != models.PaymentStatusPending {
+		return nil, invalidError("invalid status change, transaction is already %s", transaction.PaymentStatus)
+	}
+	if err := checkPaymentsAllowStatus(tx, transaction, status); err != nil {
+		return nil, err
+	}
+
+	// Lock the table before the stock rows like CreateTransaction does, so paying one tab while another is
+	// ordered at the same table cannot deadlock
+	if !refunding && transaction.TableId != nil && transaction.OrderType == models.OrderTypeDineIn {
+		if _, err := lockTable(tx, *transaction.TableId); err != nil {
+			return nil, err
+		}
+	}
+
 	transaction.PaymentStatus = status
-	if status == models.PaymentStatusPaid {
+
+	var err error
+	switch status {
+	case models.PaymentStatusPaid:
 		now := time.Now()
 		transaction.PaidAt = &now
+		alerts.stock, err = ts.inventoryService.consumeReservedStock(tx, transaction)
+		if err == nil {
+			alerts.ingredients, err = ts.ingredientService.depleteIngredients(tx, transaction)
+		}
+		if err == nil {
+			err = earnPoints(tx, transaction)
+		}
+		if err == nil {
+			_, err = queueKitchenTickets(tx, transaction, nil, models.PrintJobKindTicket, "")
+		}
+	case models.PaymentStatusFailed, models.PaymentStatusExpired, models.PaymentStatusCancelled:
+		err = ts.inventoryService.releaseReservedStock(tx, transaction)
+		if err == nil {
+			err = reversePoints(tx, transaction)
+		}
+	case models.PaymentStatusRefunded:
+		now := time.Now()
+		transaction.RefundedAt = &now
+		err = reversePoints(tx, transaction)
+	default:
+		err = invalidError("invalid payment status: %s", status)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Save(transaction).Error; err != nil {
+		return nil, err
+	}
+
+	if !refunding {
+		if err := releaseTable(tx, transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	return alerts, nil
+}
+
+func (ts *TransactionService) notifyStatusAlerts(alerts *statusAlerts) {
+	if alerts == nil {
+		return
+	}
+
+	ts.inventoryService.notifyLowStock(alerts.stock)
+	ts.ingredientService.notifyLowIngredients(alerts.ingredients)
+}
+
+// ExpirePendingTransactions expires pending transactions past their payment deadline and releases their stock.
+// Orders that are partly paid are left for the cashier to settle.
+func (ts *TransactionService) ExpirePendingTransactions() (int, error) {
+	var orderNumbers []string
+	if err := ts.db.Model(&models.Transaction{}).
+		Where("payment_status = ? AND expired_at IS NOT NULL AND expired_at < ?", models.PaymentStatusPending, time.Now()).
+		Where("NOT EXISTS (SELECT 1 FROM payments p WHERE p.transaction_id = transactions.id AND p.status = ?)", models.PaymentStatusPaid).
+		Pluck("order_number", &orderNumbers).Error; err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, orderNumber := range orderNumbers {
+		if err := ts.UpdateTransactionStatus(orderNumber, models.PaymentStatusExpired); err != nil {
+			fmt.Printf("Failed to expire transaction %s: %v\n", orderNumber, err)
+			continue
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+// StartExpiryWorker runs ExpirePendingTransactions on every tick, it blocks so start it in its own goroutine
+func (ts *TransactionService) StartExpiryWorker(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := ts.ExpirePendingTransactions(); err != nil {
+			fmt.Printf("Failed to expire transactions: %v\n", err)
+		}
+	}
+}
+
+// paymentExpiry reads how long a transaction waits for payment, PAYMENT_EXPIRY_MINUTES defaults to 30
+func paymentExpiry() time.Duration {
+	minutes, err := strconv.Atoi(config.GetEnv("PAYMENT_EXPIRY_MINUTES", "30"))
+	if err != nil || minutes <= 0 {
+		minutes = 30
 	}
 
-	return ts.db.Save(&transaction).Error
+	return time.Duration(minutes) * time.Minute
 }
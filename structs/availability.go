@@ -0,0 +1,37 @@
+package structs
+
+type AvailabilityScheduleRequest struct {
+	ProductId  *uint  `json:"product_id"`
+	CategoryId *uint  `json:"category_id"`
+	Label      string `json:"label"`
+	Days       []int  `json:"days" binding:"required,min=1,dive,min=0,max=6"`
+	StartTime  string `json:"start_time" binding:"required"`
+	EndTime    string `json:"end_time" binding:"required"`
+}
+
+type AvailabilityScheduleResponse struct {
+	Id         uint   `json:"id"`
+	ProductId  *uint  `json:"product_id"`
+	CategoryId *uint  `json:"category_id"`
+	Label      string `json:"label"`
+	Days       []int  `json:"days"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+}
+
+type AvailabilityBlackoutRequest struct {
+	ProductId  *uint  `json:"product_id"`
+	CategoryId *uint  `json:"category_id"`
+	StartDate  string `json:"start_date" binding:"required"`
+	EndDate    string `json:"end_date" binding:"required"`
+	Reason     string `json:"reason"`
+}
+
+type AvailabilityBlackoutResponse struct {
+	Id         uint   `json:"id"`
+	ProductId  *uint  `json:"product_id"`
+	CategoryId *uint  `json:"category_id"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Reason     string `json:"reason"`
+}
@@ -0,0 +1,43 @@
+package structs
+
+type BundleComponentChoiceResponse struct {
+	Id          uint   `json:"id"`
+	ProductId   uint   `json:"product_id"`
+	ProductName string `json:"product_name"`
+	PriceDelta  int    `json:"price_delta"`
+	IsAvailable bool   `json:"is_available"`
+}
+
+type BundleComponentResponse struct {
+	Id          uint                            `json:"id"`
+	Name        string                          `json:"name"`
+	ProductId   uint                            `json:"product_id"`
+	ProductName string                          `json:"product_name"`
+	Quantity    uint                            `json:"quantity"`
+	SortOrder   int                             `json:"sort_order"`
+	IsAvailable bool                            `json:"is_available"`
+	Choices     []BundleComponentChoiceResponse `json:"choices"`
+}
+
+type BundleComponentChoiceRequest struct {
+	ProductId  uint `json:"product_id" binding:"required"`
+	PriceDelta int  `json:"price_delta"`
+}
+
+type BundleComponentRequest struct {
+	Name      string                         `json:"name" binding:"required"`
+	ProductId uint                           `json:"product_id" binding:"required"`
+	Quantity  uint                           `json:"quantity" binding:"required,min=1"`
+	SortOrder int                            `json:"sort_order"`
+	Choices   []BundleComponentChoiceRequest `json:"choices" binding:"dive"`
+}
+
+type BundleRequest struct {
+	Components []BundleComponentRequest `json:"components" binding:"required,min=1,dive"`
+}
+
+// BundleChoiceSelection swaps the default product of a bundle component for one of its choices
+type BundleChoiceSelection struct {
+	ComponentId uint `json:"component_id" binding:"required"`
+	ProductId   uint `json:"product_id" binding:"required"`
+}
@@ -1,6 +1,26 @@
 package structs
 
 type CategoryResponse struct {
-	Value string `json:"value"`
-	Label string `json:"label"`
+	Id        uint   `json:"id"`
+	Value     string `json:"value"`
+	Label     string `json:"label"`
+	SortOrder int    `json:"sort_order"`
+	Icon      string `json:"icon"`
+	IsActive  bool   `json:"is_active"`
+}
+
+type CategoryCreateRequest struct {
+	Slug        string `json:"slug" binding:"required,max=100"`
+	DisplayName string `json:"display_name" binding:"required"`
+	SortOrder   int    `json:"sort_order"`
+	Icon        string `json:"icon"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+type CategoryUpdateRequest struct {
+	Slug        string `json:"slug" binding:"required,max=100"`
+	DisplayName string `json:"display_name" binding:"required"`
+	SortOrder   int    `json:"sort_order"`
+	Icon        string `json:"icon"`
+	IsActive    *bool  `json:"is_active"`
 }
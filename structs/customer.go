@@ -0,0 +1,25 @@
+package structs
+
+type CustomerUpdateRequest struct {
+	Name  string `json:"name" binding:"required"`
+	Email string `json:"email" binding:"omitempty,email"`
+	Notes string `json:"notes"`
+}
+
+type CustomerResponse struct {
+	Id                       uint     `json:"id"`
+	Phone                    string   `json:"phone"`
+	Name                     string   `json:"name"`
+	Email                    string   `json:"email"`
+	Notes                    string   `json:"notes"`
+	PointsBalance            int      `json:"points_balance"`
+	OrderCount               int64    `json:"order_count"`
+	LifetimeValue            int64    `json:"lifetime_value"`
+	AverageOrderValue        int64    `json:"average_order_value"`
+	VisitDays                int64    `json:"visit_days"`
+	AverageDaysBetweenVisits *float64 `json:"average_days_between_visits"`
+	FirstOrderAt             *string  `json:"first_order_at"`
+	LastOrderAt              *string  `json:"last_order_at"`
+	CreatedAt                string   `json:"created_at"`
+	UpdatedAt                string   `json:"updated_at"`
+}
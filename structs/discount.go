@@ -0,0 +1,63 @@
+package structs
+
+type DiscountRequest struct {
+	Name       string `json:"name" binding:"required"`
+	Type       string `json:"type" binding:"required,oneof=percentage fixed"`
+	Value      uint   `json:"value" binding:"required,min=1"`
+	ProductId  *uint  `json:"product_id"`
+	CategoryId *uint  `json:"category_id"`
+	Days       []int  `json:"days" binding:"dive,min=0,max=6"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+	StartDate  string `json:"start_date"`
+	EndDate    string `json:"end_date"`
+	Stackable  bool   `json:"stackable"`
+	Priority   int    `json:"priority"`
+	IsActive   *bool  `json:"is_active"`
+}
+
+type DiscountResponse struct {
+	Id         uint    `json:"id"`
+	Name       string  `json:"name"`
+	Type       string  `json:"type"`
+	Value      uint    `json:"value"`
+	ProductId  *uint   `json:"product_id"`
+	CategoryId *uint   `json:"category_id"`
+	Days       []int   `json:"days"`
+	StartTime  string  `json:"start_time"`
+	EndTime    string  `json:"end_time"`
+	StartDate  *string `json:"start_date"`
+	EndDate    *string `json:"end_date"`
+	Stackable  bool    `json:"stackable"`
+	Priority   int     `json:"priority"`
+	IsActive   bool    `json:"is_active"`
+	ActiveNow  bool    `json:"active_now"`
+	CreatedAt  string  `json:"created_at"`
+	UpdatedAt  string  `json:"updated_at"`
+}
+
+type TransactionDetailDiscountResponse struct {
+	Id         uint   `json:"id"`
+	DiscountId uint   `json:"discount_id"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+	Value      uint   `json:"value"`
+	Amount     uint   `json:"amount"`
+}
+
+type DiscountReportRow struct {
+	DiscountId     uint   `json:"discount_id"`
+	Name           string `json:"name"`
+	Orders         int64  `json:"orders"`
+	Lines          int64  `json:"lines"`
+	Quantity       int64  `json:"quantity"`
+	DiscountAmount int64  `json:"discount_amount"`
+	NetRevenue     int64  `json:"net_revenue"`
+}
+
+type DiscountReport struct {
+	From          *string             `json:"from"`
+	To            *string             `json:"to"`
+	Discounts     []DiscountReportRow `json:"discounts"`
+	TotalDiscount int64               `json:"total_discount"`
+}
@@ -0,0 +1,18 @@
+package structs
+
+type ExportJobResponse struct {
+	Id          uint              `json:"id"`
+	Kind        string            `json:"kind"`
+	Format      string            `json:"format"`
+	Language    string            `json:"language"`
+	Params      map[string]string `json:"params"`
+	Status      string            `json:"status"`
+	FileName    string            `json:"file_name"`
+	Rows        int64             `json:"rows"`
+	Error       string            `json:"error"`
+	CreatedBy   string            `json:"created_by"`
+	DownloadUrl *string           `json:"download_url"`
+	CompletedAt *string           `json:"completed_at"`
+	ExpiresAt   *string           `json:"expires_at"`
+	CreatedAt   string            `json:"created_at"`
+}
@@ -0,0 +1,85 @@
+package structs
+
+type UnitResponse struct {
+	Id        uint    `json:"id"`
+	Symbol    string  `json:"symbol"`
+	Name      string  `json:"name"`
+	Dimension string  `json:"dimension"`
+	Factor    float64 `json:"factor"`
+}
+
+type UnitCreateRequest struct {
+	Symbol    string  `json:"symbol" binding:"required,max=20"`
+	Name      string  `json:"name" binding:"required"`
+	Dimension string  `json:"dimension" binding:"required,oneof=mass volume count"`
+	Factor    float64 `json:"factor" binding:"required,gt=0"`
+}
+
+type IngredientResponse struct {
+	Id            uint    `json:"id"`
+	Name          string  `json:"name"`
+	UnitId        uint    `json:"unit_id"`
+	Unit          string  `json:"unit"`
+	StockQuantity float64 `json:"stock_quantity"`
+	LowStockLevel float64 `json:"low_stock_level"`
+	CostPerUnit   float64 `json:"cost_per_unit"`
+	IsActive      bool    `json:"is_active"`
+	IsLowStock    bool    `json:"is_low_stock"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+type IngredientRequest struct {
+	Name          string  `json:"name" binding:"required"`
+	UnitId        uint    `json:"unit_id" binding:"required"`
+	LowStockLevel float64 `json:"low_stock_level" binding:"min=0"`
+	CostPerUnit   float64 `json:"cost_per_unit" binding:"min=0"`
+	IsActive      *bool   `json:"is_active"`
+}
+
+type IngredientMovementRequest struct {
+	Type     string  `json:"type" binding:"required,oneof=waste adjustment"`
+	Quantity float64 `json:"quantity" binding:"required"`
+	Notes    string  `json:"notes"`
+}
+
+type IngredientMovementResponse struct {
+	Id              uint    `json:"id"`
+	IngredientId    uint    `json:"ingredient_id"`
+	Type            string  `json:"type"`
+	Quantity        float64 `json:"quantity"`
+	StockAfter      float64 `json:"stock_after"`
+	TransactionId   *uint   `json:"transaction_id"`
+	PurchaseOrderId *uint   `json:"purchase_order_id"`
+	StockCountId    *uint   `json:"stock_count_id"`
+	Reference       string  `json:"reference"`
+	Notes           string  `json:"notes"`
+	CreatedBy       string  `json:"created_by"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+type RecipeItemRequest struct {
+	IngredientId uint    `json:"ingredient_id" binding:"required"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"`
+	UnitId       uint    `json:"unit_id"`
+}
+
+type RecipeRequest struct {
+	Items []RecipeItemRequest `json:"items" binding:"dive"`
+}
+
+type RecipeItemResponse struct {
+	Id             uint    `json:"id"`
+	IngredientId   uint    `json:"ingredient_id"`
+	IngredientName string  `json:"ingredient_name"`
+	Quantity       float64 `json:"quantity"`
+	UnitId         uint    `json:"unit_id"`
+	Unit           string  `json:"unit"`
+	Cost           float64 `json:"cost"`
+}
+
+type RecipeResponse struct {
+	ProductId uint                 `json:"product_id"`
+	Items     []RecipeItemResponse `json:"items"`
+	Cost      float64              `json:"cost"`
+}
@@ -0,0 +1,41 @@
+package structs
+
+type InventoryItemResponse struct {
+	ProductId      uint   `json:"product_id"`
+	VariantId      *uint  `json:"variant_id"`
+	Name           string `json:"name"`
+	StockQuantity  int    `json:"stock_quantity"`
+	ReservedStock  int    `json:"reserved_stock"`
+	AvailableStock int    `json:"available_stock"`
+	LowStockLevel  int    `json:"low_stock_level"`
+	IsLowStock     bool   `json:"is_low_stock"`
+	IsAvailable    bool   `json:"is_available"`
+}
+
+type StockSettingsRequest struct {
+	VariantId     *uint `json:"variant_id"`
+	TrackStock    *bool `json:"track_stock"`
+	LowStockLevel *int  `json:"low_stock_level" binding:"omitempty,min=0"`
+}
+
+type StockMovementRequest struct {
+	ProductId uint   `json:"product_id" binding:"required"`
+	VariantId *uint  `json:"variant_id"`
+	Type      string `json:"type" binding:"required,oneof=restock waste adjustment"`
+	Quantity  int    `json:"quantity" binding:"required"`
+	Notes     string `json:"notes"`
+}
+
+type StockMovementResponse struct {
+	Id            uint   `json:"id"`
+	ProductId     uint   `json:"product_id"`
+	VariantId     *uint  `json:"variant_id"`
+	Type          string `json:"type"`
+	Quantity      int    `json:"quantity"`
+	StockAfter    int    `json:"stock_after"`
+	TransactionId *uint  `json:"transaction_id"`
+	Reference     string `json:"reference"`
+	Notes         string `json:"notes"`
+	CreatedBy     string `json:"created_by"`
+	CreatedAt     string `json:"created_at"`
+}
@@ -0,0 +1,41 @@
+package structs
+
+type LoyaltySettingRequest struct {
+	IsActive         bool `json:"is_active"`
+	EarnAmount       uint `json:"earn_amount" binding:"required,min=1"`
+	MinimumSpend     uint `json:"minimum_spend"`
+	PointValue       uint `json:"point_value" binding:"required,min=1"`
+	MinRedeemPoints  uint `json:"min_redeem_points"`
+	MaxRedeemPercent uint `json:"max_redeem_percent" binding:"max=100"`
+	ExpiryDays       uint `json:"expiry_days"`
+}
+
+type LoyaltySettingResponse struct {
+	IsActive         bool   `json:"is_active"`
+	EarnAmount       uint   `json:"earn_amount"`
+	MinimumSpend     uint   `json:"minimum_spend"`
+	PointValue       uint   `json:"point_value"`
+	MinRedeemPoints  uint   `json:"min_redeem_points"`
+	MaxRedeemPercent uint   `json:"max_redeem_percent"`
+	ExpiryDays       uint   `json:"expiry_days"`
+	UpdatedAt        string `json:"updated_at"`
+}
+
+type LoyaltyAdjustRequest struct {
+	Points int    `json:"points" binding:"required"`
+	Notes  string `json:"notes" binding:"required"`
+}
+
+type LoyaltyPointEntryResponse struct {
+	Id            uint    `json:"id"`
+	CustomerId    uint    `json:"customer_id"`
+	TransactionId *uint   `json:"transaction_id"`
+	Type          string  `json:"type"`
+	Points        int     `json:"points"`
+	Remaining     int     `json:"remaining"`
+	BalanceAfter  int     `json:"balance_after"`
+	ExpiresAt     *string `json:"expires_at"`
+	Notes         string  `json:"notes"`
+	CreatedBy     string  `json:"created_by"`
+	CreatedAt     string  `json:"created_at"`
+}
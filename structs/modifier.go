@@ -0,0 +1,48 @@
+package structs
+
+type ModifierResponse struct {
+	Id          uint   `json:"id"`
+	Name        string `json:"name"`
+	PriceDelta  int    `json:"price_delta"`
+	IsAvailable bool   `json:"is_available"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+type ModifierGroupResponse struct {
+	Id         uint               `json:"id"`
+	Name       string             `json:"name"`
+	MinSelect  uint               `json:"min_select"`
+	MaxSelect  uint               `json:"max_select"`
+	IsRequired bool               `json:"is_required"`
+	SortOrder  int                `json:"sort_order"`
+	Modifiers  []ModifierResponse `json:"modifiers"`
+}
+
+type ModifierRequest struct {
+	Id          uint   `json:"id"`
+	Name        string `json:"name" binding:"required"`
+	PriceDelta  int    `json:"price_delta"`
+	IsAvailable *bool  `json:"is_available"`
+	SortOrder   int    `json:"sort_order"`
+}
+
+type ModifierGroupRequest struct {
+	Name       string            `json:"name" binding:"required"`
+	MinSelect  uint              `json:"min_select"`
+	MaxSelect  uint              `json:"max_select"`
+	IsRequired bool              `json:"is_required"`
+	SortOrder  int               `json:"sort_order"`
+	Modifiers  []ModifierRequest `json:"modifiers" binding:"required,min=1,dive"`
+}
+
+type ProductModifierGroupsRequest struct {
+	ModifierGroupIds []uint `json:"modifier_group_ids"`
+}
+
+type TransactionDetailModifierResponse struct {
+	Id         uint   `json:"id"`
+	ModifierId uint   `json:"modifier_id"`
+	GroupName  string `json:"group_name"`
+	Name       string `json:"name"`
+	PriceDelta int    `json:"price_delta"`
+}
@@ -0,0 +1,43 @@
+package structs
+
+// PaymentCreateRequest pays part of a bill. DetailIds splits by order lines, People splits evenly and Amount pays
+// a given amount, without any of them the payment covers what is left.
+type PaymentCreateRequest struct {
+	Method    string `json:"method" binding:"required,oneof=cash card qris transfer midtrans"`
+	Amount    uint   `json:"amount"`
+	DetailIds []uint `json:"detail_ids"`
+	People    uint   `json:"people" binding:"omitempty,min=2"`
+	Tendered  uint   `json:"tendered"`
+	Reference string `json:"reference"`
+}
+
+type PaymentResponse struct {
+	Id              uint    `json:"id"`
+	TransactionId   uint    `json:"transaction_id"`
+	Method          string  `json:"method"`
+	Amount          uint    `json:"amount"`
+	Tendered        uint    `json:"tendered"`
+	Change          uint    `json:"change"`
+	Status          string  `json:"status"`
+	SplitType       string  `json:"split_type"`
+	SplitLabel      string  `json:"split_label"`
+	DetailIds       []uint  `json:"detail_ids"`
+	Reference       string  `json:"reference"`
+	MidtransOrderId *string `json:"midtrans_order_id"`
+	MidtransToken   string  `json:"midtrans_token,omitempty"`
+	RedirectUrl     string  `json:"redirect_url,omitempty"`
+	PaidAt          *string `json:"paid_at"`
+	CreatedBy       string  `json:"created_by"`
+	CreatedAt       string  `json:"created_at"`
+}
+
+type PaymentSummaryResponse struct {
+	TransactionId uint              `json:"transaction_id"`
+	OrderNumber   string            `json:"order_number"`
+	PaymentStatus string            `json:"payment_status"`
+	TotalAmount   uint              `json:"total_amount"`
+	PaidAmount    uint              `json:"paid_amount"`
+	PendingAmount uint              `json:"pending_amount"`
+	Remaining     uint              `json:"remaining"`
+	Payments      []PaymentResponse `json:"payments"`
+}
@@ -0,0 +1,67 @@
+package structs
+
+type PrinterRequest struct {
+	Name       string `json:"name" binding:"required,max=100"`
+	Host       string `json:"host" binding:"required,max=255"`
+	Port       uint   `json:"port" binding:"omitempty,min=1,max=65535"`
+	PaperWidth uint   `json:"paper_width" binding:"omitempty,oneof=58 80"`
+	IsActive   *bool  `json:"is_active"`
+}
+
+type PrinterResponse struct {
+	Id         uint   `json:"id"`
+	Name       string `json:"name"`
+	Host       string `json:"host"`
+	Port       uint   `json:"port"`
+	PaperWidth uint   `json:"paper_width"`
+	IsActive   bool   `json:"is_active"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+}
+
+type StationRequest struct {
+	Name        string `json:"name" binding:"required,max=100"`
+	PrinterId   *uint  `json:"printer_id"`
+	IsActive    *bool  `json:"is_active"`
+	CategoryIds []uint `json:"category_ids"`
+}
+
+type StationCategoryResponse struct {
+	Id          uint   `json:"id"`
+	Slug        string `json:"slug"`
+	DisplayName string `json:"display_name"`
+}
+
+type StationResponse struct {
+	Id         uint                      `json:"id"`
+	Name       string                    `json:"name"`
+	PrinterId  *uint                     `json:"printer_id"`
+	Printer    *PrinterResponse          `json:"printer,omitempty"`
+	IsActive   bool                      `json:"is_active"`
+	Categories []StationCategoryResponse `json:"categories"`
+	CreatedAt  string                    `json:"created_at"`
+	UpdatedAt  string                    `json:"updated_at"`
+}
+
+type KitchenTicketReprintRequest struct {
+	StationId *uint `json:"station_id"`
+}
+
+type PrintJobResponse struct {
+	Id            uint    `json:"id"`
+	TransactionId uint    `json:"transaction_id"`
+	OrderNumber   string  `json:"order_number,omitempty"`
+	StationId     uint    `json:"station_id"`
+	StationName   string  `json:"station_name,omitempty"`
+	PrinterId     uint    `json:"printer_id"`
+	PrinterName   string  `json:"printer_name,omitempty"`
+	Kind          string  `json:"kind"`
+	Status        string  `json:"status"`
+	Attempts      uint    `json:"attempts"`
+	LastError     string  `json:"last_error"`
+	NextAttemptAt string  `json:"next_attempt_at"`
+	PrintedAt     *string `json:"printed_at"`
+	CreatedBy     string  `json:"created_by"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
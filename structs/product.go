@@ -1,32 +1,54 @@
 package structs
 
-import "deck/enums"
-
 type ProductResponse struct {
-	Id           uint               `json:"id"`
-	Name         string             `json:"name"`
-	Price        uint               `json:"price"`
-	Category     enums.CategoryType `json:"category"`
-	CategoryName string             `json:"category_name"`
-	Description  string             `json:"description"`
-	Image        string             `json:"image"`
-	IsAvailable  bool               `json:"is_available"`
-	CreatedAt    string             `json:"created_at"`
-	UpdatedAt    string             `json:"updated_at"`
+	Id           uint    `json:"id"`
+	Name         string  `json:"name"`
+	Sku          *string `json:"sku"`
+	Barcode      *string `json:"barcode"`
+	Type         string  `json:"type"`
+	Price        uint    `json:"price"`
+	CategoryId   uint    `json:"category_id"`
+	Category     string  `json:"category"`
+	CategoryName string  `json:"category_name"`
+	Description  string  `json:"description"`
+	Image        string  `json:"image"`
+	IsAvailable  bool    `json:"is_available"`
+	CreatedAt    string  `json:"created_at"`
+	UpdatedAt    string  `json:"updated_at"`
+
+	AvailableNow      bool   `json:"available_now"`
+	UnavailableReason string `json:"unavailable_reason,omitempty"`
+
+	OptionGroups   []ProductOptionGroupResponse `json:"option_groups,omitempty"`
+	Variants       []ProductVariantResponse     `json:"variants,omitempty"`
+	ModifierGroups []ModifierGroupResponse      `json:"modifier_groups,omitempty"`
+	Components     []BundleComponentResponse    `json:"components,omitempty"`
 }
 
 type ProductCreateRequest struct {
-	Name        string             `json:"name" form:"name" binding:"required" gorm:"not null"`
-	Price       uint               `json:"price" form:"price" binding:"required" gorm:"not null"`
-	Category    enums.CategoryType `json:"category" form:"category" binding:"required,oneof=other appetizers main_course desserts snacks food pastry" gorm:"not null"`
-	Description string             `json:"description" form:"description"`
-	IsAvailable bool               `json:"isAvailable" form:"is_available" binding:"required" gorm:"not null"`
+	Name        string `json:"name" form:"name" binding:"required" gorm:"not null"`
+	Price       uint   `json:"price" form:"price" binding:"required" gorm:"not null"`
+	Category    string `json:"category" form:"category" binding:"required" gorm:"not null"`
+	Description string `json:"description" form:"description"`
+	Sku         string `json:"sku" form:"sku"`
+	Barcode     string `json:"barcode" form:"barcode"`
+	IsAvailable bool   `json:"isAvailable" form:"is_available" binding:"required" gorm:"not null"`
 }
 
 type ProductUpdateRequest struct {
-	Name        string             `json:"name" form:"name" binding:"required" gorm:"not null"`
-	Price       uint               `json:"price" form:"price" binding:"required" gorm:"not null"`
-	Category    enums.CategoryType `json:"category" form:"category" binding:"required,oneof=other appetizers main_course desserts snacks food pastry" gorm:"not null"`
-	Description string             `json:"description" form:"description"`
-	IsAvailable bool               `json:"isAvailable" form:"is_available"`
+	Name        string `json:"name" form:"name" binding:"required" gorm:"not null"`
+	Price       uint   `json:"price" form:"price" binding:"required" gorm:"not null"`
+	Category    string `json:"category" form:"category" binding:"required" gorm:"not null"`
+	Description string `json:"description" form:"description"`
+	Sku         string `json:"sku" form:"sku"`
+	Barcode     string `json:"barcode" form:"barcode"`
+	IsAvailable bool   `json:"isAvailable" form:"is_available"`
+}
+
+type ProductSearchResponse struct {
+	ProductResponse
+	Rank          float64 `json:"rank"`
+	NameHighlight string  `json:"name_highlight"`
+	Snippet       string  `json:"snippet"`
+	MatchType     string  `json:"match_type"`
 }
@@ -0,0 +1,19 @@
+package structs
+
+type ProductImportRowResult struct {
+	Row       int               `json:"row"`
+	Action    string            `json:"action"`
+	ProductId uint              `json:"product_id,omitempty"`
+	Name      string            `json:"name"`
+	Sku       string            `json:"sku,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+type ProductImportReport struct {
+	DryRun    bool                     `json:"dry_run"`
+	TotalRows int                      `json:"total_rows"`
+	Created   int                      `json:"created"`
+	Updated   int                      `json:"updated"`
+	Invalid   int                      `json:"invalid"`
+	Rows      []ProductImportRowResult `json:"rows"`
+}
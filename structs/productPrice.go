@@ -0,0 +1,39 @@
+package structs
+
+type ProductPriceRequest struct {
+	Price         uint   `json:"price" binding:"required"`
+	EffectiveFrom string `json:"effective_from" binding:"required"`
+	Notes         string `json:"notes"`
+}
+
+type ProductPriceResponse struct {
+	Id            uint    `json:"id"`
+	ProductId     uint    `json:"product_id"`
+	Price         uint    `json:"price"`
+	EffectiveFrom string  `json:"effective_from"`
+	EffectiveTo   *string `json:"effective_to"`
+	Notes         string  `json:"notes"`
+	CreatedBy     string  `json:"created_by"`
+	IsCurrent     bool    `json:"is_current"`
+	IsScheduled   bool    `json:"is_scheduled"`
+	CreatedAt     string  `json:"created_at"`
+}
+
+type PricePeriodRevenue struct {
+	PriceId       uint    `json:"price_id"`
+	Price         uint    `json:"price"`
+	EffectiveFrom string  `json:"effective_from"`
+	EffectiveTo   *string `json:"effective_to"`
+	Orders        int64   `json:"orders"`
+	Quantity      int64   `json:"quantity"`
+	Revenue       int64   `json:"revenue"`
+}
+
+type PricePeriodReport struct {
+	ProductId    uint                 `json:"product_id"`
+	ProductName  string               `json:"product_name"`
+	From         *string              `json:"from"`
+	To           *string              `json:"to"`
+	Periods      []PricePeriodRevenue `json:"periods"`
+	TotalRevenue int64                `json:"total_revenue"`
+}
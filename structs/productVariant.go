@@ -0,0 +1,43 @@
+package structs
+
+type ProductOptionValueResponse struct {
+	Id        uint   `json:"id"`
+	Name      string `json:"name"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type ProductOptionGroupResponse struct {
+	Id        uint                         `json:"id"`
+	Name      string                       `json:"name"`
+	SortOrder int                          `json:"sort_order"`
+	Values    []ProductOptionValueResponse `json:"values"`
+}
+
+type ProductVariantResponse struct {
+	Id             uint   `json:"id"`
+	Name           string `json:"name"`
+	Price          uint   `json:"price"`
+	AbsolutePrice  *uint  `json:"absolute_price"`
+	PriceDelta     int    `json:"price_delta"`
+	IsAvailable    bool   `json:"is_available"`
+	OptionValueIds []uint `json:"option_value_ids"`
+}
+
+type ProductVariantsResponse struct {
+	ProductId    uint                         `json:"product_id"`
+	OptionGroups []ProductOptionGroupResponse `json:"option_groups"`
+	Variants     []ProductVariantResponse     `json:"variants"`
+}
+
+type ProductOptionGroupCreateRequest struct {
+	Name      string   `json:"name" binding:"required"`
+	SortOrder int      `json:"sort_order"`
+	Values    []string `json:"values" binding:"required,min=1,dive,required"`
+}
+
+type ProductVariantRequest struct {
+	OptionValueIds []uint `json:"option_value_ids" binding:"required,min=1"`
+	Price          *uint  `json:"price"`
+	PriceDelta     int    `json:"price_delta"`
+	IsAvailable    *bool  `json:"is_available"`
+}
@@ -0,0 +1,67 @@
+package structs
+
+type SupplierRequest struct {
+	Name    string `json:"name" binding:"required"`
+	Phone   string `json:"phone"`
+	Email   string `json:"email" binding:"omitempty,email"`
+	Address string `json:"address"`
+	Notes   string `json:"notes"`
+}
+
+type SupplierResponse struct {
+	Id        uint   `json:"id"`
+	Name      string `json:"name"`
+	Phone     string `json:"phone"`
+	Email     string `json:"email"`
+	Address   string `json:"address"`
+	Notes     string `json:"notes"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type PurchaseOrderItemRequest struct {
+	IngredientId uint    `json:"ingredient_id" binding:"required"`
+	Quantity     float64 `json:"quantity" binding:"required,gt=0"`
+	UnitCost     float64 `json:"unit_cost" binding:"min=0"`
+}
+
+type PurchaseOrderCreateRequest struct {
+	SupplierId uint                       `json:"supplier_id" binding:"required"`
+	Notes      string                     `json:"notes"`
+	Items      []PurchaseOrderItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type PurchaseOrderReceiveItemRequest struct {
+	ItemId           uint    `json:"item_id" binding:"required"`
+	ReceivedQuantity float64 `json:"received_quantity" binding:"min=0"`
+}
+
+// PurchaseOrderReceiveRequest receives every item in full when Items is empty
+type PurchaseOrderReceiveRequest struct {
+	Items []PurchaseOrderReceiveItemRequest `json:"items" binding:"dive"`
+}
+
+type PurchaseOrderItemResponse struct {
+	Id               uint    `json:"id"`
+	IngredientId     uint    `json:"ingredient_id"`
+	IngredientName   string  `json:"ingredient_name"`
+	Unit             string  `json:"unit"`
+	Quantity         float64 `json:"quantity"`
+	ReceivedQuantity float64 `json:"received_quantity"`
+	UnitCost         float64 `json:"unit_cost"`
+}
+
+type PurchaseOrderResponse struct {
+	Id           uint                        `json:"id"`
+	Number       string                      `json:"number"`
+	SupplierId   uint                        `json:"supplier_id"`
+	SupplierName string                      `json:"supplier_name"`
+	Status       string                      `json:"status"`
+	TotalCost    float64                     `json:"total_cost"`
+	Notes        string                      `json:"notes"`
+	CreatedBy    string                      `json:"created_by"`
+	ReceivedAt   *string                     `json:"received_at"`
+	CreatedAt    string                      `json:"created_at"`
+	UpdatedAt    string                      `json:"updated_at"`
+	Items        []PurchaseOrderItemResponse `json:"items"`
+}
@@ -0,0 +1,166 @@
+package structs
+
+type DailyReportPaymentRow struct {
+	Method string `json:"method"`
+	Count  int64  `json:"count"`
+	Amount int64  `json:"amount"`
+}
+
+type DailyReportCategoryRow struct {
+	CategoryId     uint   `json:"category_id"`
+	Category       string `json:"category"`
+	Quantity       int64  `json:"quantity"`
+	GrossSales     int64  `json:"gross_sales"`
+	DiscountAmount int64  `json:"discount_amount"`
+	NetSales       int64  `json:"net_sales"`
+}
+
+type DailyReportZReport struct {
+	Id       uint   `json:"id"`
+	ClosedAt string `json:"closed_at"`
+	ClosedBy string `json:"closed_by"`
+}
+
+// DailyReport sums the sales of one business day in the outlet timezone. Sales count on the day they were paid
+// and refunds on the day they were refunded. Prices include tax, Tax is the part of NetSales that is tax.
+type DailyReport struct {
+	Date              string                   `json:"date"`
+	Timezone          string                   `json:"timezone"`
+	Orders            int64                    `json:"orders"`
+	ItemsSold         int64                    `json:"items_sold"`
+	GrossSales        int64                    `json:"gross_sales"`
+	Discounts         int64                    `json:"discounts"`
+	PointsDiscounts   int64                    `json:"points_discounts"`
+	Refunds           int64                    `json:"refunds"`
+	RefundCount       int64                    `json:"refund_count"`
+	NetSales          int64                    `json:"net_sales"`
+	TaxRate           uint                     `json:"tax_rate"`
+	Tax               int64                    `json:"tax"`
+	NetSalesBeforeTax int64                    `json:"net_sales_before_tax"`
+	AverageOrderValue int64                    `json:"average_order_value"`
+	CancelledOrders   int64                    `json:"cancelled_orders"`
+	ExpiredOrders     int64                    `json:"expired_orders"`
+	PaymentMethods    []DailyReportPaymentRow  `json:"payment_methods"`
+	Categories        []DailyReportCategoryRow `json:"categories"`
+	ZReport           *DailyReportZReport      `json:"z_report"`
+}
+
+type ZReportCloseRequest struct {
+	Date string `json:"date" binding:"required"`
+}
+
+type ZReportResponse struct {
+	Id       uint        `json:"id"`
+	Date     string      `json:"date"`
+	ClosedAt string      `json:"closed_at"`
+	ClosedBy string      `json:"closed_by"`
+	Orders   int64       `json:"orders"`
+	NetSales int64       `json:"net_sales"`
+	Report   DailyReport `json:"report"`
+}
+
+type ProductPerformanceRow struct {
+	Rank               int      `json:"rank"`
+	Id                 uint     `json:"id"`
+	Name               string   `json:"name"`
+	Quantity           int64    `json:"quantity"`
+	BundleQuantity     int64    `json:"bundle_quantity"`
+	Revenue            int64    `json:"revenue"`
+	DiscountAmount     int64    `json:"discount_amount"`
+	Orders             int64    `json:"orders"`
+	AveragePrice       int64    `json:"average_price"`
+	RevenueShare       float64  `json:"revenue_share"`
+	ModifierAttachRate float64  `json:"modifier_attach_rate"`
+	PreviousRank       *int     `json:"previous_rank"`
+	PreviousQuantity   int64    `json:"previous_quantity"`
+	PreviousRevenue    int64    `json:"previous_revenue"`
+	QuantityChange     *float64 `json:"quantity_change"`
+	RevenueChange      *float64 `json:"revenue_change"`
+	QuantitySeries     []int64  `json:"quantity_series"`
+	RevenueSeries      []int64  `json:"revenue_series"`
+}
+
+type ModifierAttachRow struct {
+	ModifierId       uint    `json:"modifier_id"`
+	GroupName        string  `json:"group_name"`
+	Name             string  `json:"name"`
+	AttachedQuantity int64   `json:"attached_quantity"`
+	EligibleQuantity int64   `json:"eligible_quantity"`
+	AttachRate       float64 `json:"attach_rate"`
+}
+
+type ProductPerformanceTotals struct {
+	Quantity         int64    `json:"quantity"`
+	Revenue          int64    `json:"revenue"`
+	Orders           int64    `json:"orders"`
+	PreviousQuantity int64    `json:"previous_quantity"`
+	PreviousRevenue  int64    `json:"previous_revenue"`
+	PreviousOrders   int64    `json:"previous_orders"`
+	QuantityChange   *float64 `json:"quantity_change"`
+	RevenueChange    *float64 `json:"revenue_change"`
+}
+
+// ProductPerformanceReport ranks products or categories over a period and compares them with the period of the
+// same length just before it. Changes are in percent, nil when there was nothing to compare with. The series
+// hold one value per bucket, in the order of Buckets.
+type ProductPerformanceReport struct {
+	From         string                   `json:"from"`
+	To           string                   `json:"to"`
+	PreviousFrom string                   `json:"previous_from"`
+	PreviousTo   string                   `json:"previous_to"`
+	GroupBy      string                   `json:"group_by"`
+	SortBy       string                   `json:"sort_by"`
+	Bucket       string                   `json:"bucket"`
+	Buckets      []string                 `json:"buckets"`
+	Totals       ProductPerformanceTotals `json:"totals"`
+	Rows         []ProductPerformanceRow  `json:"rows"`
+	Modifiers    []ModifierAttachRow      `json:"modifiers"`
+}
+
+type ProductPerformanceQuery struct {
+	From    string
+	To      string
+	GroupBy string
+	SortBy  string
+	Bucket  string
+	Limit   int
+}
+
+type SalesHeatmapCell struct {
+	Weekday        int     `json:"weekday"`
+	Hour           int     `json:"hour"`
+	Orders         int64   `json:"orders"`
+	Revenue        int64   `json:"revenue"`
+	AverageOrders  float64 `json:"average_orders"`
+	AverageRevenue int64   `json:"average_revenue"`
+}
+
+type SalesHeatmapWeekday struct {
+	Weekday int    `json:"weekday"`
+	Name    string `json:"name"`
+	Days    int    `json:"days"`
+	Orders  int64  `json:"orders"`
+	Revenue int64  `json:"revenue"`
+}
+
+type SalesHeatmapHour struct {
+	Hour    int   `json:"hour"`
+	Orders  int64 `json:"orders"`
+	Revenue int64 `json:"revenue"`
+}
+
+// SalesHeatmap counts paid orders by the weekday and hour they were placed, in the outlet timezone. Weekdays run
+// from 1 for Monday to 7 for Sunday. Cells holds all 168 weekday and hour pairs, Monday 00:00 first, and averages
+// divide by the number of times the weekday occurs in the range.
+type SalesHeatmap struct {
+	From       string                `json:"from"`
+	To         string                `json:"to"`
+	Timezone   string                `json:"timezone"`
+	Orders     int64                 `json:"orders"`
+	Revenue    int64                 `json:"revenue"`
+	MaxOrders  int64                 `json:"max_orders"`
+	MaxRevenue int64                 `json:"max_revenue"`
+	Weekdays   []SalesHeatmapWeekday `json:"weekdays"`
+	Hours      []SalesHeatmapHour    `json:"hours"`
+	Cells      []SalesHeatmapCell    `json:"cells"`
+}
@@ -0,0 +1,48 @@
+package structs
+
+type ShiftOpenRequest struct {
+	OpeningFloat uint   `json:"opening_float"`
+	Notes        string `json:"notes"`
+}
+
+type ShiftCashEntryRequest struct {
+	Type   string `json:"type" binding:"required,oneof=paid_in paid_out"`
+	Amount uint   `json:"amount" binding:"required,min=1"`
+	Reason string `json:"reason" binding:"required"`
+}
+
+type ShiftCloseRequest struct {
+	CountedCash *uint  `json:"counted_cash" binding:"required"`
+	Notes       string `json:"notes"`
+}
+
+type ShiftCashEntryResponse struct {
+	Id        uint   `json:"id"`
+	Type      string `json:"type"`
+	Amount    uint   `json:"amount"`
+	Reason    string `json:"reason"`
+	CreatedBy string `json:"created_by"`
+	CreatedAt string `json:"created_at"`
+}
+
+type ShiftResponse struct {
+	Id           uint                     `json:"id"`
+	UserId       uint                     `json:"user_id"`
+	Username     string                   `json:"username,omitempty"`
+	Status       string                   `json:"status"`
+	OpenedAt     string                   `json:"opened_at"`
+	OpeningFloat uint                     `json:"opening_float"`
+	OpenNotes    string                   `json:"open_notes"`
+	ClosedAt     *string                  `json:"closed_at"`
+	ClosedBy     string                   `json:"closed_by"`
+	CashSales    uint                     `json:"cash_sales"`
+	PaidIn       uint                     `json:"paid_in"`
+	PaidOut      uint                     `json:"paid_out"`
+	ExpectedCash uint                     `json:"expected_cash"`
+	CountedCash  *uint                    `json:"counted_cash"`
+	Variance     int                      `json:"variance"`
+	CloseNotes   string                   `json:"close_notes"`
+	CashEntries  []ShiftCashEntryResponse `json:"cash_entries"`
+	CreatedAt    string                   `json:"created_at"`
+	UpdatedAt    string                   `json:"updated_at"`
+}
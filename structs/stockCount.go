@@ -0,0 +1,51 @@
+package structs
+
+type StockCountItemRequest struct {
+	IngredientId    uint    `json:"ingredient_id" binding:"required"`
+	CountedQuantity float64 `json:"counted_quantity" binding:"min=0"`
+}
+
+type StockCountRequest struct {
+	Notes string                  `json:"notes"`
+	Items []StockCountItemRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type StockCountItemResponse struct {
+	IngredientId     uint    `json:"ingredient_id"`
+	IngredientName   string  `json:"ingredient_name"`
+	Unit             string  `json:"unit"`
+	ExpectedQuantity float64 `json:"expected_quantity"`
+	CountedQuantity  float64 `json:"counted_quantity"`
+	Variance         float64 `json:"variance"`
+}
+
+type StockCountResponse struct {
+	Id        uint                     `json:"id"`
+	CountedAt string                   `json:"counted_at"`
+	Notes     string                   `json:"notes"`
+	CreatedBy string                   `json:"created_by"`
+	Items     []StockCountItemResponse `json:"items"`
+}
+
+type IngredientVarianceItem struct {
+	IngredientId   uint    `json:"ingredient_id"`
+	IngredientName string  `json:"ingredient_name"`
+	Unit           string  `json:"unit"`
+	Opening        float64 `json:"opening"`
+	Purchased      float64 `json:"purchased"`
+	Used           float64 `json:"used"`
+	Wasted         float64 `json:"wasted"`
+	Adjusted       float64 `json:"adjusted"`
+	Theoretical    float64 `json:"theoretical"`
+	Actual         float64 `json:"actual"`
+	Variance       float64 `json:"variance"`
+	VarianceCost   float64 `json:"variance_cost"`
+}
+
+type IngredientVarianceReport struct {
+	StockCountId      uint                     `json:"stock_count_id"`
+	PeriodStart       *string                  `json:"period_start"`
+	PeriodEnd         string                   `json:"period_end"`
+	Items             []IngredientVarianceItem `json:"items"`
+	TotalVarianceCost float64                  `json:"total_variance_cost"`
+}
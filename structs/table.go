@@ -0,0 +1,66 @@
+package structs
+
+type AreaRequest struct {
+	Name      string `json:"name" binding:"required"`
+	SortOrder int    `json:"sort_order"`
+}
+
+type AreaResponse struct {
+	Id        uint            `json:"id"`
+	Name      string          `json:"name"`
+	SortOrder int             `json:"sort_order"`
+	Tables    []TableResponse `json:"tables"`
+	CreatedAt string          `json:"created_at"`
+	UpdatedAt string          `json:"updated_at"`
+}
+
+type TableRequest struct {
+	AreaId   uint   `json:"area_id" binding:"required"`
+	Name     string `json:"name" binding:"required,max=50"`
+	Seats    uint   `json:"seats" binding:"required,min=1"`
+	IsActive *bool  `json:"is_active"`
+}
+
+type TableStatusRequest struct {
+	Status string `json:"status" binding:"required,oneof=free needs_cleaning"`
+}
+
+type TableResponse struct {
+	Id            uint    `json:"id"`
+	AreaId        uint    `json:"area_id"`
+	AreaName      string  `json:"area_name,omitempty"`
+	Name          string  `json:"name"`
+	Seats         uint    `json:"seats"`
+	Status        string  `json:"status"`
+	IsActive      bool    `json:"is_active"`
+	OccupiedSince *string `json:"occupied_since"`
+	CreatedAt     string  `json:"created_at"`
+	UpdatedAt     string  `json:"updated_at"`
+}
+
+type TableTabsResponse struct {
+	Table      TableResponse         `json:"table"`
+	OpenAmount uint                  `json:"open_amount"`
+	Tabs       []TransactionResponse `json:"tabs"`
+}
+
+type TableQrResponse struct {
+	TableId   uint   `json:"table_id"`
+	TableName string `json:"table_name"`
+	Token     string `json:"token"`
+	Url       string `json:"url"`
+}
+
+type OrderingSessionOpenRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type OrderingSessionResponse struct {
+	Token       string                `json:"token,omitempty"`
+	Table       TableResponse         `json:"table"`
+	ExpiresAt   string                `json:"expires_at"`
+	OrderCount  uint                  `json:"order_count"`
+	MaxOrders   uint                  `json:"max_orders"`
+	LastOrderAt *string               `json:"last_order_at"`
+	Orders      []TransactionResponse `json:"orders,omitempty"`
+}
@@ -4,13 +4,22 @@ type TransactionResponse struct {
 	Id                 uint                        `json:"id"`
 	OrderNumber        string                      `json:"order_number"`
 	SubTotal           uint                        `json:"sub_total"`
+	DiscountAmount     uint                        `json:"discount_amount"`
+	PointsRedeemed     uint                        `json:"points_redeemed"`
+	PointsDiscount     uint                        `json:"points_discount"`
+	PointsEarned       uint                        `json:"points_earned"`
 	TotalAmount        uint                        `json:"total_amount"`
 	PaymentStatus      string                      `json:"payment_status"`
 	PaymentMethod      string                      `json:"payment_method"`
+	OrderType          string                      `json:"order_type"`
+	TableId            *uint                       `json:"table_id"`
+	CustomerId         *uint                       `json:"customer_id"`
 	BuyerName          string                      `json:"buyer_name"`
 	Phone              string                      `json:"phone"`
 	Notes              string                      `json:"notes"`
+	PaymentStartedAt   *string                     `json:"payment_started_at"`
 	PaidAt             *string                     `json:"paid_at"`
+	RefundedAt         *string                     `json:"refunded_at"`
 	ExpiredAt          *string                     `json:"expired_at"`
 	CreatedAt          string                      `json:"created_at"`
 	UpdatedAt          string                      `json:"updated_at"`
@@ -18,8 +27,39 @@ type TransactionResponse struct {
 }
 
 type TransactionCreateRequest struct {
-	BuyerName string                           `json:"buyer_name" binding:"required"`
-	Phone     string                           `json:"phone" binding:"required"`
-	Notes     string                           `json:"notes"`
-	Items     []TransactionDetailCreateRequest `json:"items" binding:"required,dive"`
+	BuyerName    string                           `json:"buyer_name" binding:"required"`
+	Phone        string                           `json:"phone" binding:"required"`
+	Notes        string                           `json:"notes"`
+	RedeemPoints uint                             `json:"redeem_points"`
+	OrderType    string                           `json:"order_type" binding:"omitempty,oneof=dine_in takeaway delivery"`
+	TableId      *uint                            `json:"table_id"`
+	SessionId    *uint                            `json:"-"`
+	Items        []TransactionDetailCreateRequest `json:"items" binding:"required,dive"`
+}
+
+type TransactionStatusUpdateRequest struct {
+	Status string `json:"status" binding:"required,oneof=paid failed expired cancelled refunded"`
+}
+
+type TransactionItemsRequest struct {
+	Items []TransactionDetailCreateRequest `json:"items" binding:"required,min=1,dive"`
+}
+
+type TransactionItemVoidRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type TransactionChangeResponse struct {
+	Id                  uint   `json:"id"`
+	TransactionId       uint   `json:"transaction_id"`
+	TransactionDetailId *uint  `json:"transaction_detail_id"`
+	Action              string `json:"action"`
+	ProductName         string `json:"product_name"`
+	Quantity            uint   `json:"quantity"`
+	Amount              uint   `json:"amount"`
+	Reason              string `json:"reason"`
+	TotalBefore         uint   `json:"total_before"`
+	TotalAfter          uint   `json:"total_after"`
+	ChangedBy           string `json:"changed_by"`
+	CreatedAt           string `json:"created_at"`
 }
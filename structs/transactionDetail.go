@@ -5,16 +5,32 @@ type TransactionDetailResponse struct {
 	TransactionId uint   `json:"transaction_id"`
 	ProductId     uint   `json:"product_id"`
 	ProductName   string `json:"product_name"`
+	VariantId     *uint  `json:"variant_id"`
+	VariantName   string `json:"variant_name"`
 	Quantity      uint   `json:"quantity"`
 	Price         uint   `json:"price"`
 	TotalPrice    uint   `json:"total_price"`
 	Notes         string `json:"notes"`
 	CreatedAt     string `json:"created_at"`
 	UpdatedAt     string `json:"updated_at"`
+
+	ParentDetailId *uint                               `json:"parent_detail_id"`
+	ModifiersPrice int                                 `json:"modifiers_price"`
+	Modifiers      []TransactionDetailModifierResponse `json:"modifiers"`
+	DiscountAmount uint                                `json:"discount_amount"`
+	Discounts      []TransactionDetailDiscountResponse `json:"discounts"`
+	VoidedAt       *string                             `json:"voided_at"`
+	VoidedBy       string                              `json:"voided_by,omitempty"`
+	VoidReason     string                              `json:"void_reason,omitempty"`
 }
 
 type TransactionDetailCreateRequest struct {
-	ProductId uint   `json:"product_id" binding:"required"`
-	Quantity  uint   `json:"quantity" binding:"required,min=1"`
-	Notes     string `json:"notes"`
+	ProductId   uint   `json:"product_id" binding:"required_without=Barcode"`
+	Barcode     string `json:"barcode"`
+	VariantId   *uint  `json:"variant_id"`
+	ModifierIds []uint `json:"modifier_ids"`
+	Quantity    uint   `json:"quantity" binding:"required,min=1"`
+	Notes       string `json:"notes"`
+
+	BundleChoices []BundleChoiceSelection `json:"bundle_choices" binding:"dive"`
 }